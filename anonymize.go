@@ -0,0 +1,207 @@
+package prview
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// mentionPattern matches an @login mention, capturing the preceding
+// character so email addresses aren't mistaken for mentions
+var mentionPattern = regexp.MustCompile(`(^|[^\w@])@([A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?)`)
+
+// Anonymize returns a copy of the PR with every login replaced by a stable
+// pseudonym (user1, user2, ...), numbered in the order people first appear
+// in the rendered output. Mentions and Co-authored-by trailers are rewritten
+// to match, and URLs are redacted too when redactURLs is set.
+func Anonymize(pr PullRequest, redactURLs bool) PullRequest {
+	a := &anonymizer{names: make(map[string]string), redactURLs: redactURLs}
+
+	// Assign pseudonyms in reading order before rewriting anything, so the
+	// numbering doesn't depend on the order the slices are stored in
+	a.visit(pr.User.Login, pr.Body)
+	if pr.MergedBy != nil {
+		a.login(pr.MergedBy.Login)
+	}
+	visitReview := func(r Review) {
+		a.visit(r.User.Login, r.Body)
+		for _, thread := range r.Threads {
+			for _, c := range thread.Comments {
+				a.visit(c.User.Login, c.Body)
+				a.visitReactions(c.Reactions)
+			}
+		}
+	}
+	if pr.PendingReview != nil {
+		visitReview(*pr.PendingReview)
+	}
+	for _, item := range buildTimeline(pr) {
+		switch item.Type {
+		case "comment":
+			a.visit(item.Comment.User.Login, item.Comment.Body)
+			a.visitReactions(item.Comment.Reactions)
+		case "review":
+			visitReview(*item.Review)
+		case "commit":
+			a.visit(item.Commit.Author.Login, item.Commit.Body)
+		case "reference":
+			a.login(item.Reference.Actor.Login)
+		}
+	}
+
+	teams := make([]string, 0, len(pr.Teams))
+	for team := range pr.Teams {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+	for _, team := range teams {
+		for _, login := range pr.Teams[team] {
+			a.login(login)
+		}
+	}
+
+	comment := func(c Comment) Comment {
+		c.User.Login = a.login(c.User.Login)
+		c.Body = a.text(c.Body)
+		if c.Reactions.Users != nil {
+			users := make(map[string][]string, len(c.Reactions.Users))
+			for content, logins := range c.Reactions.Users {
+				users[content] = make([]string, len(logins))
+				for i, login := range logins {
+					users[content][i] = a.login(login)
+				}
+			}
+			c.Reactions.Users = users
+		}
+		return c
+	}
+	review := func(r Review) Review {
+		r.User.Login = a.login(r.User.Login)
+		r.Body = a.text(r.Body)
+		threads := make([]CommentThread, len(r.Threads))
+		for j, thread := range r.Threads {
+			threads[j] = thread
+			threads[j].Comments = make([]Comment, len(thread.Comments))
+			for k, c := range thread.Comments {
+				threads[j].Comments[k] = comment(c)
+			}
+		}
+		r.Threads = threads
+		return r
+	}
+
+	pr.User.Login = a.login(pr.User.Login)
+	pr.Body = a.text(pr.Body)
+	if pr.MergedBy != nil {
+		mergedBy := *pr.MergedBy
+		mergedBy.Login = a.login(mergedBy.Login)
+		pr.MergedBy = &mergedBy
+	}
+
+	comments := make([]Comment, len(pr.Comments))
+	for i, c := range pr.Comments {
+		comments[i] = comment(c)
+	}
+	pr.Comments = comments
+
+	reviews := make([]Review, len(pr.Reviews))
+	for i, r := range pr.Reviews {
+		reviews[i] = review(r)
+	}
+	pr.Reviews = reviews
+	if pr.PendingReview != nil {
+		pending := review(*pr.PendingReview)
+		pr.PendingReview = &pending
+	}
+
+	commits := make([]Commit, len(pr.Commits))
+	for i, c := range pr.Commits {
+		c.Author.Login = a.login(c.Author.Login)
+		// The commit's own author details would give away who wrote it
+		c.AuthorName, c.AuthorEmail = "", ""
+		c.Message = a.text(c.Message)
+		c.Body = a.text(c.Body)
+		commits[i] = c
+	}
+	pr.Commits = commits
+
+	if pr.Teams != nil {
+		// Keys are org/team, and the org is rewritten in mentions like a login
+		members := make(map[string][]string, len(pr.Teams))
+		for team, logins := range pr.Teams {
+			org, slug, _ := strings.Cut(team, "/")
+			aliased := make([]string, len(logins))
+			for i, login := range logins {
+				aliased[i] = a.login(login)
+			}
+			members[strings.ToLower(a.login(org)+"/"+slug)] = aliased
+		}
+		pr.Teams = members
+	}
+
+	references := make([]CrossReference, len(pr.References))
+	for i, ref := range pr.References {
+		ref.Actor.Login = a.login(ref.Actor.Login)
+		references[i] = ref
+	}
+	pr.References = references
+
+	return pr
+}
+
+type anonymizer struct {
+	names      map[string]string
+	redactURLs bool
+}
+
+// visit assigns pseudonyms to an author and the people mentioned or credited
+// in their text
+func (a *anonymizer) visit(login string, body string) {
+	a.login(login)
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		a.login(match[2])
+	}
+	for _, match := range coAuthorTrailer.FindAllStringSubmatch(body, -1) {
+		a.login(match[1])
+	}
+}
+
+// visitReactions assigns pseudonyms to the people who reacted to a comment,
+// in the order their reactions are listed
+func (a *anonymizer) visitReactions(r Reactions) {
+	for _, kind := range reactionEmoji {
+		for _, login := range r.Users[kind.content] {
+			a.login(login)
+		}
+	}
+}
+
+// login returns the pseudonym for a login or name, assigning the next one if
+// it hasn't been seen before
+func (a *anonymizer) login(login string) string {
+	if login == "" {
+		return ""
+	}
+	key := strings.ToLower(login)
+	if name, ok := a.names[key]; ok {
+		return name
+	}
+	name := fmt.Sprintf("user%d", len(a.names)+1)
+	a.names[key] = name
+	return name
+}
+
+func (a *anonymizer) text(s string) string {
+	s = mentionPattern.ReplaceAllStringFunc(s, func(m string) string {
+		match := mentionPattern.FindStringSubmatch(m)
+		return match[1] + "@" + a.login(match[2])
+	})
+	s = coAuthorTrailer.ReplaceAllStringFunc(s, func(m string) string {
+		return "Co-authored-by: " + a.login(coAuthorTrailer.FindStringSubmatch(m)[1])
+	})
+	if a.redactURLs {
+		s = urlPattern.ReplaceAllString(s, "[redacted URL]")
+	}
+	return s
+}
@@ -0,0 +1,135 @@
+package prview_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestAnonymize(t *testing.T) {
+	now := time.Now()
+	pr := prview.PullRequest{
+		User:     prview.User{Login: "alice"},
+		Body:     "cc @bob, see https://example.com/secret",
+		MergedBy: &prview.User{Login: "erin"},
+		Comments: []prview.Comment{
+			{ID: 1, Body: "Thanks @alice", CreatedAt: now, User: prview.User{Login: "bob"}},
+			{ID: 2, Body: "Mail me at carol@example.com", CreatedAt: now.Add(time.Minute), User: prview.User{Login: "carol"},
+				Reactions: prview.Reactions{TotalCount: 2, Users: map[string][]string{"+1": {"alice", "heidi"}}}},
+		},
+		Reviews: []prview.Review{
+			{
+				ID:          101,
+				SubmittedAt: now.Add(2 * time.Minute),
+				User:        prview.User{Login: "bob"},
+				Threads: []prview.CommentThread{
+					{Comments: []prview.Comment{{ID: 3, Body: "Nit", CreatedAt: now, User: prview.User{Login: "Alice"}}}, Resolved: true},
+				},
+			},
+		},
+		Commits: []prview.Commit{
+			{SHA: "aaa", CreatedAt: now.Add(-time.Hour), Author: prview.User{Login: "alice"}, Body: "Co-authored-by: Dave Example <dave@example.com>"},
+		},
+		PendingReview: &prview.Review{
+			User: prview.User{Login: "grace"},
+			Body: "Draft for @bob",
+			Threads: []prview.CommentThread{
+				{Comments: []prview.Comment{{ID: 4, Body: "Ask @carol", User: prview.User{Login: "grace"}}}},
+			},
+		},
+		References: []prview.CrossReference{
+			{CreatedAt: now.Add(3 * time.Minute), Actor: prview.User{Login: "frank"}, Number: 7, Repo: "owner/other"},
+		},
+	}
+
+	anon := prview.Anonymize(pr, false)
+
+	if anon.User.Login != "user1" || anon.Commits[0].Author.Login != "user1" || anon.Reviews[0].Threads[0].Comments[0].User.Login != "user1" {
+		t.Errorf("Expected alice to consistently be user1")
+	}
+	if anon.Comments[0].User.Login != "user2" || anon.Reviews[0].User.Login != "user2" {
+		t.Errorf("Expected bob to consistently be user2")
+	}
+	if anon.Comments[1].User.Login == anon.Comments[0].User.Login {
+		t.Errorf("Expected different logins to get different pseudonyms")
+	}
+	if anon.Body != "cc @user2, see https://example.com/secret" {
+		t.Errorf("Unexpected anonymized PR body: %q", anon.Body)
+	}
+	if anon.Comments[0].Body != "Thanks @user1" {
+		t.Errorf("Unexpected anonymized comment body: %q", anon.Comments[0].Body)
+	}
+	if anon.Comments[1].Body != "Mail me at carol@example.com" {
+		t.Errorf("Expected email addresses not to be treated as mentions, got %q", anon.Comments[1].Body)
+	}
+	if strings.Contains(anon.Commits[0].Body, "Dave") || strings.Contains(anon.Commits[0].Body, "dave@") {
+		t.Errorf("Expected the co-author to be anonymized, got %q", anon.Commits[0].Body)
+	}
+	if anon.MergedBy.Login == "erin" || pr.MergedBy.Login != "erin" {
+		t.Errorf("Expected the merger to be anonymized in the copy only, got %q", anon.MergedBy.Login)
+	}
+	if actor := anon.References[0].Actor.Login; actor == "frank" || !strings.HasPrefix(actor, "user") {
+		t.Errorf("Expected the referencing actor to be anonymized, got %q", actor)
+	}
+	if reactors := anon.Comments[1].Reactions.Users["+1"]; len(reactors) != 2 || reactors[0] != "user1" || reactors[1] == "heidi" {
+		t.Errorf("Expected the reactors to be anonymized, got %v", reactors)
+	}
+	if pr.Comments[1].Reactions.Users["+1"][1] != "heidi" {
+		t.Errorf("Expected the original reactions to be left untouched")
+	}
+	if !anon.Reviews[0].Threads[0].Resolved {
+		t.Errorf("Expected the thread to stay resolved")
+	}
+	pending := anon.PendingReview
+	if pending.User.Login == "grace" || pending.Threads[0].Comments[0].User.Login != pending.User.Login {
+		t.Errorf("Expected the pending review's author to be anonymized consistently, got %q", pending.User.Login)
+	}
+	if pending.Body != "Draft for @user2" || strings.Contains(pending.Threads[0].Comments[0].Body, "carol") {
+		t.Errorf("Expected the pending review's text to be anonymized, got %q and %q", pending.Body, pending.Threads[0].Comments[0].Body)
+	}
+	if pr.PendingReview.User.Login != "grace" || pr.PendingReview.Body != "Draft for @bob" {
+		t.Errorf("Expected the original pending review to be left untouched")
+	}
+	if pr.User.Login != "alice" || pr.Comments[0].Body != "Thanks @alice" {
+		t.Errorf("Expected the original PR to be left untouched")
+	}
+
+	redacted := prview.Anonymize(pr, true)
+	if redacted.Body != "cc @user2, see [redacted URL]" {
+		t.Errorf("Expected the URL to be redacted, got %q", redacted.Body)
+	}
+}
+
+func TestAnonymizeTeams(t *testing.T) {
+	pr := prview.PullRequest{
+		User:  prview.User{Login: "alice"},
+		Body:  "cc @acme/core",
+		Teams: map[string][]string{"acme/core": {"bob", "alice"}},
+	}
+
+	anon := prview.Anonymize(pr, false)
+
+	if anon.Body != "cc @user2/core" {
+		t.Fatalf("Unexpected anonymized body: %q", anon.Body)
+	}
+	members, ok := anon.Teams["user2/core"]
+	if !ok || len(anon.Teams) != 1 {
+		t.Fatalf("Expected the team to be keyed as its mention now reads, got %v", anon.Teams)
+	}
+	if members[0] == "bob" || members[1] != "user1" {
+		t.Errorf("Expected the members to be anonymized, got %v", members)
+	}
+	if pr.Teams["acme/core"][0] != "bob" {
+		t.Errorf("Expected the original teams to be left untouched")
+	}
+
+	var buf strings.Builder
+	if err := prview.RenderPR(&buf, anon, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "cc @user2/core (") || strings.Contains(buf.String(), "bob") {
+		t.Errorf("Expected the anonymized members beside the mention, got:\n%s", buf.String())
+	}
+}
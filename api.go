@@ -1,10 +1,19 @@
 package prview
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"os"
 	"os/exec"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cli/go-gh/v2/pkg/api"
@@ -18,18 +27,44 @@ type User struct {
 
 // Comment represents a PR comment (issue comment or review comment)
 type Comment struct {
-	ID                  int64     `json:"id"`
-	Body                string    `json:"body"`
-	CreatedAt           time.Time `json:"created_at"`
-	User                User      `json:"user"`
-	DiffHunk            string    `json:"diff_hunk,omitempty"`
-	Path                string    `json:"path,omitempty"`
-	CommitID            string    `json:"commit_id,omitempty"`
-	OriginalCommitID    string    `json:"original_commit_id,omitempty"`
-	Line                *int      `json:"line,omitempty"`
-	OriginalLine        *int      `json:"original_line,omitempty"`
-	InReplyToID         *int64    `json:"in_reply_to_id,omitempty"`
-	PullRequestReviewID int64     `json:"pull_request_review_id,omitempty"`
+	ID               int64     `json:"id"`
+	Body             string    `json:"body"`
+	CreatedAt        time.Time `json:"created_at"`
+	User             User      `json:"user"`
+	DiffHunk         string    `json:"diff_hunk,omitempty"`
+	Path             string    `json:"path,omitempty"`
+	CommitID         string    `json:"commit_id,omitempty"`
+	OriginalCommitID string    `json:"original_commit_id,omitempty"`
+	Line             *int      `json:"line,omitempty"`
+	OriginalLine     *int      `json:"original_line,omitempty"`
+	// Side is LEFT when Line is in the old version of the file, such as a
+	// removed line, and RIGHT, or empty, when it is in the new one
+	Side                string       `json:"side,omitempty"`
+	InReplyToID         *int64       `json:"in_reply_to_id,omitempty"`
+	PullRequestReviewID int64        `json:"pull_request_review_id,omitempty"`
+	Reactions           Reactions    `json:"reactions"`
+	FileContext         *FileContext `json:"-"`
+	CurrentContext      *FileContext `json:"-"`
+	// Renamed is set when the PR renamed the comment's file
+	Renamed *Rename `json:"-"`
+}
+
+// Reactions summarizes the emoji reactions left on a comment
+type Reactions struct {
+	TotalCount int `json:"total_count"`
+	// Users are the logins of who reacted, by reaction content such as
+	// "+1", when they have been loaded
+	Users map[string][]string `json:"-"`
+}
+
+// FileContext holds the lines of a file surrounding a review comment
+type FileContext struct {
+	Ref       string
+	StartLine int
+	Line      int
+	Lines     []string
+	// Removed reports that the file no longer exists at Ref
+	Removed bool
 }
 
 // Review represents a PR review
@@ -46,15 +81,23 @@ type Review struct {
 // CommentThread represents a thread of comments on a single diff location
 type CommentThread struct {
 	Comments []Comment
+	// Resolved reports whether the thread has been marked as resolved
+	Resolved bool
 }
 
 // Commit represents a git commit
 type Commit struct {
-	SHA       string `json:"sha"`
-	Message   string
-	Author    User
-	Checks    CheckCounts `json:"-"`
-	CreatedAt time.Time   `json:"-"`
+	SHA     string `json:"sha"`
+	Message string
+	Body    string
+	Author  User
+	// AuthorName and AuthorEmail are from the commit itself, as opposed to
+	// the GitHub account it is linked to
+	AuthorName  string
+	AuthorEmail string
+	Checks      CheckCounts `json:"-"`
+	CreatedAt   time.Time   `json:"-"`
+	Diff        string      `json:"-"`
 }
 
 // CheckCounts holds counts of check runs by status
@@ -68,7 +111,11 @@ type CheckCounts struct {
 type commitResponse struct {
 	SHA    string `json:"sha"`
 	Commit struct {
-		Message   string `json:"message"`
+		Message string `json:"message"`
+		Author  struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+		} `json:"author"`
 		Committer struct {
 			Date time.Time `json:"date"`
 		} `json:"committer"`
@@ -76,6 +123,15 @@ type commitResponse struct {
 	Author User `json:"author"`
 }
 
+type commitFilesResponse struct {
+	Files []struct {
+		Filename         string `json:"filename"`
+		PreviousFilename string `json:"previous_filename"`
+		Status           string `json:"status"`
+		Patch            string `json:"patch"`
+	} `json:"files"`
+}
+
 type checkRunsResponse struct {
 	CheckRuns []struct {
 		Status     string `json:"status"`
@@ -87,33 +143,172 @@ type statusResponse struct {
 	State string `json:"state"`
 }
 
+type contentsResponse struct {
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+}
+
+// GitRef represents the head or base branch of a pull request
+type GitRef struct {
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+// Issue represents an issue a pull request closes
+type Issue struct {
+	Ref   IssueRef `json:"-"`
+	Title string   `json:"title"`
+}
+
 // PullRequest represents a GitHub pull request
 type PullRequest struct {
-	Number    int       `json:"number"`
-	Title     string    `json:"title"`
-	Body      string    `json:"body"`
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	// HTMLURL is the PR's page on GitHub
+	HTMLURL string `json:"html_url"`
+	// State is open or closed; a merged PR is closed
+	State     string    `json:"state"`
+	Draft     bool      `json:"draft"`
 	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 	User      User      `json:"user"`
-	Comments  []Comment `json:"-"`
-	Reviews   []Review  `json:"-"`
-	Commits   []Commit  `json:"-"`
+	// AuthorAssociation is the author's relationship to the repository, such
+	// as MEMBER or FIRST_TIME_CONTRIBUTOR
+	AuthorAssociation string `json:"author_association"`
+	Head              GitRef `json:"head"`
+	Base              GitRef `json:"base"`
+	// Mergeable is nil while GitHub is still computing it
+	Mergeable      *bool     `json:"mergeable"`
+	MergeableState string    `json:"mergeable_state"`
+	Comments       []Comment `json:"-"`
+	Reviews        []Review  `json:"-"`
+	// CommitCount is how many commits the PR has, which GitHub reports even
+	// when Commits isn't loaded
+	CommitCount int      `json:"commits"`
+	Commits     []Commit `json:"-"`
+	Closes      []Issue  `json:"-"`
+	// References are the issues and PRs that mention this one, when loaded
+	References []CrossReference `json:"-"`
+	// ReviewDecision is GitHub's verdict on the reviews, as
+	// ReviewStatus.Decision describes, when it has been loaded
+	ReviewDecision string `json:"-"`
+	// MergedBy and MergedAt are nil unless the PR has been merged
+	MergedBy       *User      `json:"merged_by"`
+	MergedAt       *time.Time `json:"merged_at"`
+	MergeCommitSHA string     `json:"merge_commit_sha"`
+	// ChangedFiles is how many files the PR changes, and Files those of
+	// them GitHub lists, when they have been loaded
+	ChangedFiles int           `json:"changed_files"`
+	Files        []ChangedFile `json:"-"`
+	// PendingReview is the authenticated user's unsubmitted review, if any
+	PendingReview *Review `json:"-"`
+	// Teams are the logins of the members of the teams mentioned in the PR,
+	// by lowercase org/team, when ResolveTeams loaded them. Only text
+	// output shows them.
+	Teams map[string][]string `json:"-"`
 }
 
+// currentRepo resolves the repository from the working directory's git
+// remotes. It is a variable so tests can simulate running outside a repo.
+var currentRepo = repository.Current
+
 // GetCurrentRepo returns the current repository information
 func GetCurrentRepo() (repository.Repository, error) {
-	return repository.Current()
+	return currentRepo()
+}
+
+// ResolveRepo returns the repository named by override, in [HOST/]OWNER/REPO
+// form, or the current repository when override is empty
+func ResolveRepo(override string) (repository.Repository, error) {
+	if override != "" {
+		repo, err := repository.Parse(override)
+		if err != nil {
+			return repository.Repository{}, fmt.Errorf("invalid --repo value: %w", err)
+		}
+		return repo, nil
+	}
+
+	repo, err := GetCurrentRepo()
+	if err != nil {
+		// go-gh reports both missing remotes and remotes that don't point at
+		// a GitHub host with messages about the configured git remotes
+		if strings.Contains(err.Error(), "git remotes") {
+			return repository.Repository{}, tagError(ErrNoRepo, "no GitHub remote found for this git repository; pass --repo owner/name")
+		}
+		return repository.Repository{}, tagError(ErrNoRepo, "not inside a GitHub repository; pass --repo owner/name")
+	}
+	return repo, nil
 }
 
 // GetRESTClient returns a GitHub REST API client
 func GetRESTClient() (*api.RESTClient, error) {
-	clientOpts := api.ClientOptions{EnableCache: true}
-	return api.NewRESTClient(clientOpts)
+	return newRESTClient(nil, 0, "", "")
+}
+
+// NewClientWithTransport returns an uncached GitHub REST API client that
+// sends its requests through rt, such as a retrying or stub transport. The
+// host and token are still resolved from the gh environment.
+func NewClientWithTransport(rt http.RoundTripper) (*api.RESTClient, error) {
+	return newRESTClient(rt, 0, "", "")
+}
+
+// newRESTClient returns a client for host that sends requests through rt, or
+// through the default cached transport when rt is nil. An empty host is the
+// gh environment's default. A non-zero requestTimeout limits how long each
+// individual request may take, and a non-empty authToken is used in place
+// of the token from the gh environment.
+func newRESTClient(rt http.RoundTripper, requestTimeout time.Duration, authToken, host string) (*api.RESTClient, error) {
+	client, err := api.NewRESTClient(clientOptions(rt, requestTimeout, authToken, host))
+	if err != nil && strings.Contains(err.Error(), "authentication token not found") {
+		return nil, tagError(ErrNoToken, "%v; run gh auth login or set GH_TOKEN", err)
+	}
+	return client, err
+}
+
+// newGraphQLClient returns a GraphQL client configured like newRESTClient
+func newGraphQLClient(rt http.RoundTripper, requestTimeout time.Duration, authToken, host string) (*api.GraphQLClient, error) {
+	return api.NewGraphQLClient(clientOptions(rt, requestTimeout, authToken, host))
+}
+
+func clientOptions(rt http.RoundTripper, requestTimeout time.Duration, authToken, host string) api.ClientOptions {
+	rt = withRequestTimeout(rt, requestTimeout)
+	return api.ClientOptions{
+		AuthToken:   authToken,
+		Host:        host,
+		EnableCache: cacheable(rt),
+		Transport:   rt,
+	}
+}
+
+// gitTimeout bounds how long a git subprocess may run before it is killed
+const gitTimeout = 5 * time.Second
+
+// runGit runs git with the given arguments and returns its standard output.
+// Tests replace it to stub out the repository.
+var runGit = func(args ...string) ([]byte, error) {
+	return runCommand(gitTimeout, "git", args...)
+}
+
+// runCommand runs a subprocess, killing it if it runs longer than timeout.
+// Git is prevented from prompting for credentials, which would otherwise
+// block forever waiting on input we never give it.
+func runCommand(timeout time.Duration, name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	output, err := cmd.Output()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%s %s timed out after %s", name, strings.Join(args, " "), timeout)
+	}
+	return output, err
 }
 
 // GetCurrentBranch returns the name of the current git branch
 func GetCurrentBranch() (string, error) {
-	cmd := exec.Command("git", "branch", "--show-current")
-	output, err := cmd.Output()
+	output, err := runGit("branch", "--show-current")
 	if err != nil {
 		return "", err
 	}
@@ -121,22 +316,34 @@ func GetCurrentBranch() (string, error) {
 }
 
 // GetCurrentPR tries to determine the PR number for the current branch
-func GetCurrentPR(client *api.RESTClient, repo repository.Repository) (int, error) {
+func GetCurrentPR(ctx context.Context, client *api.RESTClient, repo repository.Repository) (int, error) {
 	branch, err := GetCurrentBranch()
 	if err != nil {
 		return 0, err
 	}
 
-	// Find PR for current branch
+	prNumber, err := FindPRForBranch(ctx, client, repo, branch)
+	if err != nil {
+		return 0, err
+	}
+	if prNumber == 0 {
+		return 0, tagError(ErrPRNotFound, "no open PR found for current branch: %s", branch)
+	}
+	return prNumber, nil
+}
+
+// FindPRForBranch returns the number of the open PR whose head is the named
+// branch of the repository, or zero if there isn't one
+func FindPRForBranch(ctx context.Context, client *api.RESTClient, repo repository.Repository, branch string) (int, error) {
 	var prs []map[string]interface{}
-	err = client.Get(fmt.Sprintf("repos/%s/%s/pulls?head=%s:%s&state=open",
-		repo.Owner, repo.Name, repo.Owner, branch), &prs)
+	err := doRequest(ctx, client, http.MethodGet, fmt.Sprintf("repos/%s/%s/pulls?head=%s&state=open",
+		repo.Owner, repo.Name, url.QueryEscape(repo.Owner+":"+branch)), nil, &prs)
 	if err != nil {
 		return 0, err
 	}
 
 	if len(prs) == 0 {
-		return 0, fmt.Errorf("no open PR found for current branch: %s", branch)
+		return 0, nil
 	}
 
 	// Convert the number to int
@@ -157,73 +364,284 @@ func GetCurrentPR(client *api.RESTClient, repo repository.Repository) (int, erro
 	}
 }
 
+// FindPRForCommit returns the number of the PR that introduced a commit, or
+// zero if there isn't one. When several PRs contain the commit, e.g. a
+// feature branch and the release branch it was merged into, the one that was
+// merged is picked; if that doesn't settle it, the error lists them all.
+func FindPRForCommit(ctx context.Context, client *api.RESTClient, repo repository.Repository, sha string) (int, error) {
+	var prs []PullRequest
+	err := doRequest(ctx, client, http.MethodGet, fmt.Sprintf("repos/%s/%s/commits/%s/pulls",
+		repo.Owner, repo.Name, url.PathEscape(sha)), nil, &prs)
+	if err != nil {
+		return 0, err
+	}
+
+	switch len(prs) {
+	case 0:
+		return 0, nil
+	case 1:
+		return prs[0].Number, nil
+	}
+
+	var merged []int
+	numbers := make([]string, len(prs))
+	for i, pr := range prs {
+		if pr.MergedAt != nil {
+			merged = append(merged, pr.Number)
+		}
+		numbers[i] = fmt.Sprintf("#%d", pr.Number)
+	}
+	if len(merged) == 1 {
+		return merged[0], nil
+	}
+	return 0, fmt.Errorf("commit %s is in several PRs: %s", sha, strings.Join(numbers, ", "))
+}
+
 // FetchPR retrieves a pull request by number
-func FetchPR(client *api.RESTClient, repo repository.Repository, prNumber int) (PullRequest, error) {
+func FetchPR(ctx context.Context, client *api.RESTClient, repo repository.Repository, prNumber int) (PullRequest, error) {
 	var pr PullRequest
-	err := client.Get(fmt.Sprintf("repos/%s/%s/pulls/%d", repo.Owner, repo.Name, prNumber), &pr)
+	err := doRequest(ctx, client, http.MethodGet, fmt.Sprintf("repos/%s/%s/pulls/%d", repo.Owner, repo.Name, prNumber), nil, &pr)
+	if isNotFound(err) {
+		err = fmt.Errorf("%w: %w", ErrPRNotFound, err)
+	}
 	return pr, err
 }
 
-// FetchPRComments retrieves issue comments for a pull request
-func FetchPRComments(client *api.RESTClient, repo repository.Repository, prNumber int) ([]Comment, error) {
-	var comments []Comment
-	err := client.Get(fmt.Sprintf("repos/%s/%s/issues/%d/comments", repo.Owner, repo.Name, prNumber), &comments)
-	return comments, err
+// reviewThreadsQuery lists a PR's review threads with the ID of the first
+// comment in each, which ties them to the threads built from REST comments,
+// along with the PR's review decision
+const reviewThreadsQuery = `query($owner: String!, $name: String!, $number: Int!, $first: Int!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    pullRequest(number: $number) {
+      reviewDecision
+      reviewThreads(first: $first, after: $cursor) {
+        nodes {
+          isResolved
+          comments(first: 1) { nodes { databaseId } }
+        }
+        pageInfo { hasNextPage endCursor }
+      }
+    }
+  }
+}`
+
+type reviewThreadsResponse struct {
+	Repository struct {
+		PullRequest struct {
+			ReviewDecision string `json:"reviewDecision"`
+			ReviewThreads  struct {
+				Nodes []struct {
+					IsResolved bool `json:"isResolved"`
+					Comments   struct {
+						Nodes []struct {
+							DatabaseID int64 `json:"databaseId"`
+						} `json:"nodes"`
+					} `json:"comments"`
+				} `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+			} `json:"reviewThreads"`
+		} `json:"pullRequest"`
+	} `json:"repository"`
+}
+
+// ReviewStatus is what the GraphQL API adds to the REST API's account of a
+// PR's reviews
+type ReviewStatus struct {
+	// Resolved reports which review threads are resolved, keyed by the ID of
+	// each thread's first comment
+	Resolved map[int64]bool
+	// Decision sums the reviews up as APPROVED, CHANGES_REQUESTED or
+	// REVIEW_REQUIRED, as branch protection sees them. It is empty when the
+	// repository doesn't require reviews.
+	Decision string
+}
+
+// FetchResolvedThreads reports which of a PR's review threads are resolved,
+// keyed by the ID of each thread's first comment, as FetchReviewStatus
+// does along with the review decision
+func FetchResolvedThreads(ctx context.Context, client *api.GraphQLClient, repo repository.Repository, prNumber int, perPage int) (map[int64]bool, error) {
+	status, err := FetchReviewStatus(ctx, client, repo, prNumber, perPage)
+	if err != nil {
+		return nil, err
+	}
+	return status.Resolved, nil
+}
+
+// FetchReviewStatus retrieves which of a PR's review threads are resolved,
+// listing perPage threads per query as pageSize clamps it, and the PR's
+// review decision. Neither is available through the REST API.
+func FetchReviewStatus(ctx context.Context, client *api.GraphQLClient, repo repository.Repository, prNumber int, perPage int) (ReviewStatus, error) {
+	status := ReviewStatus{Resolved: make(map[int64]bool)}
+	variables := map[string]interface{}{"owner": repo.Owner, "name": repo.Name, "number": prNumber, "first": pageSize(perPage, graphQLPageSize), "cursor": nil}
+
+	for {
+		var response reviewThreadsResponse
+		if err := client.DoWithContext(ctx, reviewThreadsQuery, variables, &response); err != nil {
+			return ReviewStatus{}, tagAPIError(err)
+		}
+
+		status.Decision = response.Repository.PullRequest.ReviewDecision
+		threads := response.Repository.PullRequest.ReviewThreads
+		for _, node := range threads.Nodes {
+			if len(node.Comments.Nodes) > 0 {
+				status.Resolved[node.Comments.Nodes[0].DatabaseID] = node.IsResolved
+			}
+		}
+		if !threads.PageInfo.HasNextPage {
+			return status, nil
+		}
+		variables["cursor"] = threads.PageInfo.EndCursor
+	}
+}
+
+// FetchCurrentUser retrieves the authenticated user
+func FetchCurrentUser(ctx context.Context, client *api.RESTClient) (User, error) {
+	var user User
+	err := doRequest(ctx, client, http.MethodGet, "user", nil, &user)
+	return user, err
 }
 
-// FetchPRReviews retrieves reviews for a pull request
-func FetchPRReviews(client *api.RESTClient, repo repository.Repository, prNumber int) ([]Review, error) {
-	var reviews []Review
-	err := client.Get(fmt.Sprintf("repos/%s/%s/pulls/%d/reviews", repo.Owner, repo.Name, prNumber), &reviews)
-	return reviews, err
+// currentUsers memoizes CurrentUser, mapping each *api.RESTClient to the
+// User it authenticates as
+var currentUsers sync.Map
+
+// CurrentUser returns the authenticated user like FetchCurrentUser, but only
+// asks the API the first time for each client. Failures aren't remembered.
+// Across runs, the default client's disk cache already keeps the response
+// for a day.
+func CurrentUser(ctx context.Context, client *api.RESTClient) (User, error) {
+	if user, ok := currentUsers.Load(client); ok {
+		return user.(User), nil
+	}
+	user, err := FetchCurrentUser(ctx, client)
+	if err != nil {
+		return User{}, err
+	}
+	currentUsers.Store(client, user)
+	return user, nil
+}
+
+// FetchIssue retrieves an issue by number
+func FetchIssue(ctx context.Context, client *api.RESTClient, repo repository.Repository, number int) (Issue, error) {
+	var issue Issue
+	err := doRequest(ctx, client, http.MethodGet, fmt.Sprintf("repos/%s/%s/issues/%d", repo.Owner, repo.Name, number), nil, &issue)
+	return issue, err
+}
+
+// FetchPRComments retrieves issue comments for a pull request, stopping
+// after the first limit comments when limit is positive
+func FetchPRComments(ctx context.Context, client *api.RESTClient, repo repository.Repository, prNumber int, limit int) ([]Comment, error) {
+	return streamArray[Comment](ctx, client, fmt.Sprintf("repos/%s/%s/issues/%d/comments", repo.Owner, repo.Name, prNumber), limit)
+}
+
+// FetchPRReviews retrieves reviews for a pull request, stopping after the
+// first limit reviews when limit is positive
+func FetchPRReviews(ctx context.Context, client *api.RESTClient, repo repository.Repository, prNumber int, limit int) ([]Review, error) {
+	return streamArray[Review](ctx, client, fmt.Sprintf("repos/%s/%s/pulls/%d/reviews", repo.Owner, repo.Name, prNumber), limit)
 }
 
 // FetchReviewComments retrieves comments for a specific review
-func FetchReviewComments(client *api.RESTClient, repo repository.Repository, prNumber int, reviewID int64) ([]Comment, error) {
+func FetchReviewComments(ctx context.Context, client *api.RESTClient, repo repository.Repository, prNumber int, reviewID int64) ([]Comment, error) {
 	var comments []Comment
-	err := client.Get(fmt.Sprintf("repos/%s/%s/pulls/%d/reviews/%d/comments",
-		repo.Owner, repo.Name, prNumber, reviewID), &comments)
+	err := doRequest(ctx, client, http.MethodGet, fmt.Sprintf("repos/%s/%s/pulls/%d/reviews/%d/comments",
+		repo.Owner, repo.Name, prNumber, reviewID), nil, &comments)
 	return comments, err
 }
 
 // FetchAllReviewComments retrieves all review comments for a pull request
-func FetchAllReviewComments(client *api.RESTClient, repo repository.Repository, prNumber int) ([]Comment, error) {
+// with a single request, rather than one for each review. Each comment's
+// PullRequestReviewID says which review it belongs under.
+func FetchAllReviewComments(ctx context.Context, client *api.RESTClient, repo repository.Repository, prNumber int) ([]Comment, error) {
 	var comments []Comment
-	err := client.Get(fmt.Sprintf("repos/%s/%s/pulls/%d/comments",
-		repo.Owner, repo.Name, prNumber), &comments)
+	err := doRequest(ctx, client, http.MethodGet, fmt.Sprintf("repos/%s/%s/pulls/%d/comments",
+		repo.Owner, repo.Name, prNumber), nil, &comments)
 	return comments, err
 }
 
+// ReplyToReviewComment posts a reply to a review comment
+func ReplyToReviewComment(ctx context.Context, client *api.RESTClient, repo repository.Repository, prNumber int, commentID int64, body string) (Comment, error) {
+	payload, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return Comment{}, err
+	}
+
+	var reply Comment
+	err = doRequest(ctx, client, http.MethodPost, fmt.Sprintf("repos/%s/%s/pulls/%d/comments/%d/replies",
+		repo.Owner, repo.Name, prNumber, commentID), bytes.NewReader(payload), &reply)
+	return reply, err
+}
+
 // FetchCommits retrieves commits for a pull request
-func FetchCommits(client *api.RESTClient, repo repository.Repository, prNumber int) ([]Commit, error) {
+func FetchCommits(ctx context.Context, client *api.RESTClient, repo repository.Repository, prNumber int) ([]Commit, error) {
 	var responses []commitResponse
-	err := client.Get(fmt.Sprintf("repos/%s/%s/pulls/%d/commits", repo.Owner, repo.Name, prNumber), &responses)
+	err := doRequest(ctx, client, http.MethodGet, fmt.Sprintf("repos/%s/%s/pulls/%d/commits", repo.Owner, repo.Name, prNumber), nil, &responses)
 	if err != nil {
 		return nil, err
 	}
+	return commitsFromResponses(responses), nil
+}
 
+// commitsFromResponses converts commits as listed by the API, splitting each
+// message into its subject and body
+func commitsFromResponses(responses []commitResponse) []Commit {
 	commits := make([]Commit, len(responses))
 	for i, r := range responses {
-		msg := r.Commit.Message
-		if idx := strings.Index(msg, "\n"); idx != -1 {
-			msg = msg[:idx]
-		}
+		msg, body, _ := strings.Cut(r.Commit.Message, "\n")
 		commits[i] = Commit{
-			SHA:       r.SHA,
-			Message:   msg,
-			Author:    r.Author,
-			CreatedAt: r.Commit.Committer.Date,
+			SHA:         r.SHA,
+			Message:     msg,
+			Body:        strings.TrimSpace(body),
+			Author:      r.Author,
+			AuthorName:  r.Commit.Author.Name,
+			AuthorEmail: r.Commit.Author.Email,
+			CreatedAt:   r.Commit.Committer.Date,
+		}
+	}
+	return commits
+}
+
+// FetchCommitDiff retrieves the unified diff introduced by a commit
+func FetchCommitDiff(ctx context.Context, client *api.RESTClient, repo repository.Repository, sha string) (string, error) {
+	var response commitFilesResponse
+	err := doRequest(ctx, client, http.MethodGet, fmt.Sprintf("repos/%s/%s/commits/%s", repo.Owner, repo.Name, sha), nil, &response)
+	if err != nil {
+		return "", err
+	}
+
+	var diff strings.Builder
+	for _, f := range response.Files {
+		oldName, newName := "a/"+f.Filename, "b/"+f.Filename
+		if f.PreviousFilename != "" {
+			oldName = "a/" + f.PreviousFilename
+		}
+		fmt.Fprintf(&diff, "diff --git %s %s\n", oldName, newName)
+
+		if f.Patch == "" {
+			// GitHub omits the patch for binary and very large files
+			fmt.Fprintf(&diff, "Binary files %s and %s differ\n", oldName, newName)
+			continue
+		}
+
+		switch f.Status {
+		case "added":
+			oldName = "/dev/null"
+		case "removed":
+			newName = "/dev/null"
 		}
+		fmt.Fprintf(&diff, "--- %s\n+++ %s\n%s\n", oldName, newName, strings.TrimSuffix(f.Patch, "\n"))
 	}
-	return commits, nil
+	return diff.String(), nil
 }
 
 // FetchCommitChecks retrieves check run counts for a commit
-func FetchCommitChecks(client *api.RESTClient, repo repository.Repository, sha string) CheckCounts {
+func FetchCommitChecks(ctx context.Context, client *api.RESTClient, repo repository.Repository, sha string) CheckCounts {
 	var counts CheckCounts
 
 	var checkRuns checkRunsResponse
-	err := client.Get(fmt.Sprintf("repos/%s/%s/commits/%s/check-runs", repo.Owner, repo.Name, sha), &checkRuns)
+	err := doRequest(ctx, client, http.MethodGet, fmt.Sprintf("repos/%s/%s/commits/%s/check-runs", repo.Owner, repo.Name, sha), nil, &checkRuns)
 	if err != nil {
 		return counts
 	}
@@ -245,3 +663,28 @@ func FetchCommitChecks(client *api.RESTClient, repo repository.Repository, sha s
 
 	return counts
 }
+
+// FetchFileLines retrieves the lines of a file at the given ref
+func FetchFileLines(ctx context.Context, client *api.RESTClient, repo repository.Repository, path string, ref string) ([]string, error) {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	var contents contentsResponse
+	err := doRequest(ctx, client, http.MethodGet, fmt.Sprintf("repos/%s/%s/contents/%s?ref=%s",
+		repo.Owner, repo.Name, strings.Join(segments, "/"), url.QueryEscape(ref)), nil, &contents)
+	if err != nil {
+		return nil, err
+	}
+
+	if contents.Encoding != "base64" {
+		return nil, fmt.Errorf("unsupported content encoding for %s: %q", path, contents.Encoding)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(contents.Content, "\n", ""))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s: %w", path, err)
+	}
+
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"), nil
+}
@@ -1,12 +1,80 @@
 package prview_test
 
 import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	prview "github.com/bmon/gh-prview"
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/repository"
 )
 
+// stubTransport serves canned JSON responses keyed by request path, delaying
+// any listed in delays, and records the requests it receives along with
+// their bodies
+type stubTransport struct {
+	responses map[string]string
+	delays    map[string]time.Duration
+	requests  []*http.Request
+	bodies    []string
+}
+
+func (s *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.requests = append(s.requests, req)
+	var reqBody []byte
+	if req.Body != nil {
+		reqBody, _ = io.ReadAll(req.Body)
+	}
+	s.bodies = append(s.bodies, string(reqBody))
+
+	if delay, ok := s.delays[req.URL.Path]; ok {
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	body, ok := s.responses[req.URL.Path]
+	status := http.StatusOK
+	if !ok {
+		body = `{"message": "Not Found"}`
+		status = http.StatusNotFound
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+// setTestAuth points the gh environment at github.com with a dummy token
+func setTestAuth(t *testing.T) {
+	t.Setenv("GH_HOST", "github.com")
+	t.Setenv("GH_TOKEN", "test-token")
+}
+
+// newTestClient returns a REST client whose requests are served by rt
+func newTestClient(t *testing.T, rt http.RoundTripper) *api.RESTClient {
+	t.Helper()
+	setTestAuth(t)
+	client, err := prview.NewClientWithTransport(rt)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return client
+}
+
+var testRepo = repository.Repository{Host: "github.com", Owner: "owner", Name: "repo"}
+
 // TestParseTimelineItems tests the creation and sorting of timeline items
 func TestParseTimelineItems(t *testing.T) {
 	now := time.Now()
@@ -32,10 +100,10 @@ func TestParseTimelineItems(t *testing.T) {
 	// Create test reviews
 	reviews := []prview.Review{
 		{
-			ID:        101,
-			Body:      "Middle review",
-			CreatedAt: now,
-			User:      prview.User{Login: "reviewer1"},
+			ID:          101,
+			Body:        "Middle review",
+			SubmittedAt: now,
+			User:        prview.User{Login: "reviewer1"},
 		},
 	}
 
@@ -55,7 +123,7 @@ func TestParseTimelineItems(t *testing.T) {
 	for i := range reviews {
 		timeline = append(timeline, prview.TimelineItem{
 			Type:      "review",
-			CreatedAt: reviews[i].CreatedAt,
+			CreatedAt: reviews[i].SubmittedAt,
 			Review:    &reviews[i],
 		})
 	}
@@ -96,12 +164,12 @@ func TestCommentStructure(t *testing.T) {
 // TestReviewStructure tests the review structure
 func TestReviewStructure(t *testing.T) {
 	review := prview.Review{
-		ID:        456,
-		Body:      "Test review",
-		State:     "APPROVED",
-		CreatedAt: time.Now(),
-		User:      prview.User{Login: "reviewer"},
-		Comments:  []prview.Comment{},
+		ID:          456,
+		Body:        "Test review",
+		State:       "APPROVED",
+		SubmittedAt: time.Now(),
+		User:        prview.User{Login: "reviewer"},
+		Threads:     []prview.CommentThread{},
 	}
 
 	if review.ID != 456 {
@@ -120,7 +188,280 @@ func TestReviewStructure(t *testing.T) {
 		t.Errorf("Expected user 'reviewer', got '%s'", review.User.Login)
 	}
 
-	if len(review.Comments) != 0 {
-		t.Errorf("Expected 0 comments, got %d", len(review.Comments))
+	if len(review.Threads) != 0 {
+		t.Errorf("Expected 0 threads, got %d", len(review.Threads))
+	}
+}
+
+func TestFetchFileLines(t *testing.T) {
+	content := base64.StdEncoding.EncodeToString([]byte("package main\n\nfunc main() {}\n"))
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/contents/cmd/main.go": `{"encoding": "base64", "content": "` + content + `"}`,
+	}}
+
+	lines, err := prview.FetchFileLines(context.Background(), newTestClient(t, rt), testRepo, "cmd/main.go", "abc123")
+	if err != nil {
+		t.Fatalf("FetchFileLines returned an error: %v", err)
+	}
+
+	if len(lines) != 3 || lines[0] != "package main" || lines[2] != "func main() {}" {
+		t.Errorf("Unexpected lines: %q", lines)
+	}
+	if ref := rt.requests[0].URL.Query().Get("ref"); ref != "abc123" {
+		t.Errorf("Expected ref abc123, got %q", ref)
+	}
+}
+
+func TestFetchCommitDiff(t *testing.T) {
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/commits/abc123": `{"files": [
+			{"filename": "new.go", "status": "added", "patch": "@@ -0,0 +1 @@\n+package new"},
+			{"filename": "logo.png", "status": "modified"}
+		]}`,
+	}}
+
+	diff, err := prview.FetchCommitDiff(context.Background(), newTestClient(t, rt), testRepo, "abc123")
+	if err != nil {
+		t.Fatalf("FetchCommitDiff returned an error: %v", err)
+	}
+
+	expected := "diff --git a/new.go b/new.go\n--- /dev/null\n+++ b/new.go\n@@ -0,0 +1 @@\n+package new\n" +
+		"diff --git a/logo.png b/logo.png\nBinary files a/logo.png and b/logo.png differ\n"
+	if diff != expected {
+		t.Errorf("Expected diff:\n%s\ngot:\n%s", expected, diff)
+	}
+}
+
+func TestRunCommandTimeout(t *testing.T) {
+	start := time.Now()
+	_, err := prview.RunCommand(50*time.Millisecond, "sleep", "5")
+	if err == nil {
+		t.Fatal("Expected a timeout error, got nil")
+	}
+	if !strings.Contains(err.Error(), "sleep 5 timed out after 50ms") {
+		t.Errorf("Expected a timeout error, got %q", err.Error())
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the command to be killed promptly, took %s", elapsed)
+	}
+}
+
+func TestRunCommandDisablesGitPrompt(t *testing.T) {
+	output, err := prview.RunCommand(time.Second, "sh", "-c", "echo $GIT_TERMINAL_PROMPT")
+	if err != nil {
+		t.Fatalf("RunCommand returned an error: %v", err)
+	}
+	if strings.TrimSpace(string(output)) != "0" {
+		t.Errorf("Expected GIT_TERMINAL_PROMPT=0, got %q", output)
+	}
+}
+
+func TestNewClientWithTransport(t *testing.T) {
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/42": `{"number": 42, "title": "Stubbed PR", "user": {"login": "octocat"}}`,
+	}}
+
+	pr, err := prview.FetchPR(context.Background(), newTestClient(t, rt), testRepo, 42)
+	if err != nil {
+		t.Fatalf("FetchPR returned an error: %v", err)
+	}
+
+	if pr.Number != 42 || pr.Title != "Stubbed PR" || pr.User.Login != "octocat" {
+		t.Errorf("Unexpected PR: %+v", pr)
+	}
+	if len(rt.requests) != 1 || rt.requests[0].URL.Host != "api.github.com" {
+		t.Errorf("Expected a single request to api.github.com, got %v", rt.requests)
+	}
+	if auth := rt.requests[0].Header.Get("Authorization"); auth != "token test-token" {
+		t.Errorf("Expected the token from the environment, got %q", auth)
+	}
+}
+
+func TestReplyToReviewComment(t *testing.T) {
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/5/comments/9876/replies": `{"id": 9999, "body": "Done, thanks!", "in_reply_to_id": 9876, "user": {"login": "me"}}`,
+	}}
+
+	reply, err := prview.ReplyToReviewComment(context.Background(), newTestClient(t, rt), testRepo, 5, 9876, "Done, thanks!")
+	if err != nil {
+		t.Fatalf("ReplyToReviewComment returned an error: %v", err)
+	}
+
+	if len(rt.requests) != 1 {
+		t.Fatalf("Expected a single request, got %d", len(rt.requests))
+	}
+	if path := rt.requests[0].URL.Path; path != "/repos/owner/repo/pulls/5/comments/9876/replies" {
+		t.Errorf("Unexpected request path: %s", path)
+	}
+	if rt.requests[0].Method != http.MethodPost {
+		t.Errorf("Expected a POST, got %s", rt.requests[0].Method)
+	}
+	if rt.bodies[0] != `{"body":"Done, thanks!"}` {
+		t.Errorf("Unexpected request body: %s", rt.bodies[0])
+	}
+	if reply.ID != 9999 || reply.InReplyToID == nil || *reply.InReplyToID != 9876 {
+		t.Errorf("Unexpected reply: %+v", reply)
+	}
+}
+
+func TestFindPRForBranch(t *testing.T) {
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls": `[{"number": 77}]`,
+	}}
+
+	prNumber, err := prview.FindPRForBranch(context.Background(), newTestClient(t, rt), testRepo, "feature/login")
+	if err != nil {
+		t.Fatalf("FindPRForBranch returned an error: %v", err)
+	}
+	if prNumber != 77 {
+		t.Errorf("Expected PR 77, got %d", prNumber)
+	}
+
+	query := rt.requests[0].URL.Query()
+	if head := query.Get("head"); head != "owner:feature/login" {
+		t.Errorf("Expected head owner:feature/login, got %q", head)
+	}
+	if state := query.Get("state"); state != "open" {
+		t.Errorf("Expected state open, got %q", state)
+	}
+}
+
+func TestGetCurrentPR(t *testing.T) {
+	prview.SetGitRunner(t, func(args ...string) ([]byte, error) {
+		if strings.Join(args, " ") != "branch --show-current" {
+			t.Errorf("Unexpected git command: git %s", strings.Join(args, " "))
+		}
+		return []byte("feature/login\n"), nil
+	})
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls": `[{"number": 77}]`,
+	}}
+
+	prNumber, err := prview.GetCurrentPR(context.Background(), newTestClient(t, rt), testRepo)
+	if err != nil {
+		t.Fatalf("GetCurrentPR returned an error: %v", err)
+	}
+	if prNumber != 77 {
+		t.Errorf("Expected PR 77, got %d", prNumber)
+	}
+
+	if len(rt.requests) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(rt.requests))
+	}
+	if url := rt.requests[0].URL; url.Path != "/repos/owner/repo/pulls" || url.Query().Get("head") != "owner:feature/login" || url.Query().Get("state") != "open" {
+		t.Errorf("Unexpected query URL %s", url)
+	}
+}
+
+func TestGetCurrentPRNoPR(t *testing.T) {
+	prview.SetGitRunner(t, func(args ...string) ([]byte, error) {
+		return []byte("scratch\n"), nil
+	})
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls": `[]`,
+	}}
+
+	_, err := prview.GetCurrentPR(context.Background(), newTestClient(t, rt), testRepo)
+	if !errors.Is(err, prview.ErrPRNotFound) {
+		t.Errorf("Expected ErrPRNotFound, got %v", err)
+	}
+}
+
+func TestFindPRForCommit(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected int
+		err      string
+	}{
+		{name: "none", response: `[]`, expected: 0},
+		{name: "single", response: `[{"number": 12, "merged_at": null}]`, expected: 12},
+		{
+			name:     "merged one of several",
+			response: `[{"number": 12, "merged_at": null}, {"number": 15, "merged_at": "2024-01-02T10:00:00Z"}]`,
+			expected: 15,
+		},
+		{
+			name:     "ambiguous",
+			response: `[{"number": 12, "merged_at": null}, {"number": 15, "merged_at": null}]`,
+			err:      "commit abc123 is in several PRs: #12, #15",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &stubTransport{responses: map[string]string{
+				"/repos/owner/repo/commits/abc123/pulls": tt.response,
+			}}
+
+			prNumber, err := prview.FindPRForCommit(context.Background(), newTestClient(t, rt), testRepo, "abc123")
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("Expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindPRForCommit returned an error: %v", err)
+			}
+			if prNumber != tt.expected {
+				t.Errorf("Expected PR %d, got %d", tt.expected, prNumber)
+			}
+		})
+	}
+}
+
+func TestFetchCurrentUser(t *testing.T) {
+	client := newTestClient(t, &stubTransport{responses: map[string]string{
+		"/user": `{"login": "me"}`,
+	}})
+
+	user, err := prview.FetchCurrentUser(context.Background(), client)
+	if err != nil {
+		t.Fatalf("FetchCurrentUser returned an error: %v", err)
+	}
+	if user.Login != "me" {
+		t.Errorf("Expected login me, got %q", user.Login)
+	}
+}
+
+func TestCurrentUserMemoized(t *testing.T) {
+	rt := &stubTransport{responses: map[string]string{
+		"/user": `{"login": "me"}`,
+	}}
+	opts := prview.LoadOptions{Repo: "owner/repo", Client: newTestClient(t, rt)}
+
+	for i := 0; i < 2; i++ {
+		login, err := prview.CurrentLogin(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("CurrentLogin returned an error: %v", err)
+		}
+		if login != "me" {
+			t.Errorf("Expected login me, got %q", login)
+		}
+	}
+	if len(rt.requests) != 1 {
+		t.Errorf("Expected the user to be fetched once, got %d requests", len(rt.requests))
+	}
+
+	if _, err := prview.CurrentUser(context.Background(), newTestClient(t, rt)); err != nil {
+		t.Fatalf("CurrentUser returned an error: %v", err)
+	}
+	if len(rt.requests) != 2 {
+		t.Errorf("Expected another client to fetch its own user, got %d requests", len(rt.requests))
+	}
+}
+
+func TestCurrentUserRetriesFailures(t *testing.T) {
+	rt := &stubTransport{responses: map[string]string{}}
+	client := newTestClient(t, rt)
+
+	if _, err := prview.CurrentUser(context.Background(), client); err == nil {
+		t.Fatal("Expected an error when the user can't be fetched")
+	}
+	rt.responses["/user"] = `{"login": "me"}`
+	user, err := prview.CurrentUser(context.Background(), client)
+	if err != nil || user.Login != "me" {
+		t.Errorf("Expected a later call to fetch the user, got %q, %v", user.Login, err)
 	}
 }
@@ -0,0 +1,98 @@
+package prview
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// apiURLTransport sends API requests to another base URL, for when the API
+// is reached through a proxy under a path prefix. go-gh only lets the host
+// be configured, so requests are rewritten on their way out.
+type apiURLTransport struct {
+	base *url.URL
+	next http.RoundTripper
+}
+
+func (t *apiURLTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target := strings.TrimSuffix(t.base.String(), "/") + "/" + apiPath(req.URL)
+	if req.URL.RawQuery != "" {
+		target += "?" + req.URL.RawQuery
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.URL = u
+	req.Host = u.Host
+	return t.next.RoundTrip(req)
+}
+
+// apiPath returns the escaped path of an API URL below the API root, which
+// is the host itself for api.github.com and /api/v3 or /api on GitHub
+// Enterprise Server
+func apiPath(u *url.URL) string {
+	p := strings.TrimPrefix(u.EscapedPath(), "/")
+	if strings.HasPrefix(u.Host, "api.") {
+		return p
+	}
+	for _, prefix := range []string{"api/v3/", "api/"} {
+		if strings.HasPrefix(p, prefix) {
+			return strings.TrimPrefix(p, prefix)
+		}
+	}
+	return p
+}
+
+// parseAPIURL checks that an API base URL is an absolute http or https URL
+func parseAPIURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API URL %q: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid API URL %q: must start with http:// or https://", raw)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid API URL %q: missing host", raw)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("invalid API URL %q: must not have a query or fragment", raw)
+	}
+	return u, nil
+}
+
+// transport returns the round tripper API clients should use: opts.Transport,
+// redirected to opts.APIURL or $GH_API_URL when either is set, and counted
+// by opts.Requests when that is
+func (opts LoadOptions) transport() (http.RoundTripper, error) {
+	rt, err := opts.redirectedTransport()
+	if err != nil || opts.Requests == nil {
+		return rt, err
+	}
+	return &countingTransport{counter: opts.Requests, base: rt}, nil
+}
+
+func (opts LoadOptions) redirectedTransport() (http.RoundTripper, error) {
+	apiURL := opts.APIURL
+	if apiURL == "" {
+		apiURL = os.Getenv("GH_API_URL")
+	}
+	if apiURL == "" {
+		return opts.Transport, nil
+	}
+
+	base, err := parseAPIURL(apiURL)
+	if err != nil {
+		return nil, err
+	}
+	next := opts.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &apiURLTransport{base: base, next: next}, nil
+}
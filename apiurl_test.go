@@ -0,0 +1,103 @@
+package prview_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestLoadPRAPIURL(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{
+		"/github/api/repos/owner/repo/pulls/7":           `{"number": 7, "title": "Proxied PR", "user": {"login": "author"}}`,
+		"/github/api/repos/owner/repo/issues/7/comments": `[]`,
+		"/github/api/repos/owner/repo/pulls/7/reviews":   `[]`,
+		"/github/api/repos/owner/repo/pulls/7/comments":  `[]`,
+		"/github/api/repos/owner/repo/pulls/7/commits":   `[]`,
+	}}
+
+	pr, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{
+		Repo:      "owner/repo",
+		Transport: rt,
+		APIURL:    "https://proxy.example.com/github/api/",
+	})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+	if pr.Title != "Proxied PR" {
+		t.Errorf("Unexpected PR: %+v", pr)
+	}
+
+	sawGraphQL := false
+	for _, req := range rt.requests {
+		if req.URL.Host != "proxy.example.com" || !strings.HasPrefix(req.URL.Path, "/github/api/") {
+			t.Errorf("Expected the request to go through the proxy, got %s", req.URL)
+		}
+		sawGraphQL = sawGraphQL || req.URL.String() == "https://proxy.example.com/github/api/graphql"
+	}
+	if !sawGraphQL {
+		t.Errorf("Expected GraphQL requests to go through the proxy too")
+	}
+}
+
+func TestLoadPRAPIURLFromEnv(t *testing.T) {
+	setTestAuth(t)
+	t.Setenv("GH_API_URL", "https://proxy.example.com/gh")
+	rt := &stubTransport{responses: map[string]string{
+		"/gh/repos/owner/repo/pulls/7":           `{"number": 7, "title": "Proxied PR", "user": {"login": "author"}}`,
+		"/gh/repos/owner/repo/issues/7/comments": `[]`,
+		"/gh/repos/owner/repo/pulls/7/reviews":   `[]`,
+		"/gh/repos/owner/repo/pulls/7/comments":  `[]`,
+		"/gh/repos/owner/repo/pulls/7/commits":   `[]`,
+	}}
+
+	if _, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt}); err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+	if len(rt.requests) == 0 || rt.requests[0].URL.String() != "https://proxy.example.com/gh/repos/owner/repo/pulls/7" {
+		t.Errorf("Expected GH_API_URL to set the base URL, got %v", rt.requests)
+	}
+}
+
+func TestLoadPRRepoHost(t *testing.T) {
+	setTestAuth(t)
+	t.Setenv("GH_ENTERPRISE_TOKEN", "ghe-token")
+	rt := &stubTransport{responses: map[string]string{
+		"/api/v3/repos/owner/repo/pulls/7":           `{"number": 7, "title": "Enterprise PR", "user": {"login": "author"}}`,
+		"/api/v3/repos/owner/repo/issues/7/comments": `[]`,
+		"/api/v3/repos/owner/repo/pulls/7/reviews":   `[]`,
+		"/api/v3/repos/owner/repo/pulls/7/comments":  `[]`,
+		"/api/v3/repos/owner/repo/pulls/7/commits":   `[]`,
+		"/api/graphql": `{"data": {"repository": {"pullRequest": {"reviewThreads": {"nodes": [], "pageInfo": {"hasNextPage": false}}}}}}`,
+	}}
+
+	if _, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "ghe.example.com/owner/repo", Transport: rt}); err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+	graphQL := false
+	for _, req := range rt.requests {
+		if req.URL.Host != "ghe.example.com" {
+			t.Errorf("Expected every request to go to ghe.example.com, got %s", req.URL)
+		}
+		graphQL = graphQL || req.URL.Path == "/api/graphql"
+	}
+	if !graphQL {
+		t.Errorf("Expected a GraphQL request to ghe.example.com, got %v", rt.requests)
+	}
+}
+
+func TestLoadPRInvalidAPIURL(t *testing.T) {
+	setTestAuth(t)
+	for _, apiURL := range []string{"proxy.example.com/api", "ftp://proxy.example.com", "https://", "https://proxy.example.com/api?x=1"} {
+		rt := &stubTransport{responses: map[string]string{}}
+		_, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt, APIURL: apiURL})
+		if err == nil || !strings.Contains(err.Error(), "invalid API URL") {
+			t.Errorf("Expected %q to be rejected, got %v", apiURL, err)
+		}
+		if len(rt.requests) != 0 {
+			t.Errorf("Expected no requests for %q, got %d", apiURL, len(rt.requests))
+		}
+	}
+}
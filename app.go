@@ -0,0 +1,191 @@
+package prview
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/auth"
+)
+
+// appJWTLifetime is how long the JWTs used to authenticate as a GitHub App
+// are valid for. GitHub rejects anything over ten minutes.
+const appJWTLifetime = 9 * time.Minute
+
+// appJWTClockSkew backdates a JWT's issue time to allow for the local clock
+// running ahead of GitHub's
+const appJWTClockSkew = 60 * time.Second
+
+// appTokenRefreshMargin is how long before an installation token expires
+// that AppTokenSource replaces it, so requests already under way with it
+// don't fail
+const appTokenRefreshMargin = 5 * time.Minute
+
+// AppToken is an installation access token and when it expires, which
+// GitHub sets an hour after it is minted
+type AppToken struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// InstallationToken mints an installation access token for a GitHub App,
+// which can be used like a personal access token, e.g. as
+// LoadOptions.AuthToken. key is the app's PEM encoded private key. The token
+// comes from the host in $GH_HOST, defaulting to github.com.
+func InstallationToken(appID, installID int64, key []byte) (string, error) {
+	token, err := MintInstallationToken(context.Background(), appID, installID, key, LoadOptions{})
+	return token.Token, err
+}
+
+// MintInstallationToken is InstallationToken for the host of the repository
+// opts name, or $GH_HOST outside one, with the request sent through opts'
+// transport and API URL like any other. It also reports when the token
+// expires.
+func MintInstallationToken(ctx context.Context, appID, installID int64, key []byte, opts LoadOptions) (AppToken, error) {
+	privateKey, err := parseAppKey(key)
+	if err != nil {
+		return AppToken{}, err
+	}
+	jwt, err := appJWT(appID, privateKey, now())
+	if err != nil {
+		return AppToken{}, err
+	}
+
+	host, _ := auth.DefaultHost()
+	if repo, err := ResolveRepo(opts.Repo); err == nil {
+		host = repo.Host
+	}
+	rt, err := opts.transport()
+	if err != nil {
+		return AppToken{}, err
+	}
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	client := &http.Client{Transport: withRequestTimeout(rt, opts.RequestTimeout)}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%sapp/installations/%d/access_tokens", apiRoot(host), installID), nil)
+	if err != nil {
+		return AppToken{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return AppToken{}, fmt.Errorf("error requesting installation token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+		Message   string    `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return AppToken{}, fmt.Errorf("error decoding installation token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusCreated || body.Token == "" {
+		return AppToken{}, fmt.Errorf("error requesting installation token: HTTP %d: %s", resp.StatusCode, body.Message)
+	}
+	return AppToken{Token: body.Token, ExpiresAt: body.ExpiresAt}, nil
+}
+
+// AppTokenSource hands out a GitHub App's installation token, minting a new
+// one with MintInstallationToken whenever the last is about to expire, so a
+// long --watch keeps working past the first token's hour. It is safe for
+// concurrent use.
+type AppTokenSource struct {
+	AppID          int64
+	InstallationID int64
+	// Key is the app's PEM encoded private key
+	Key []byte
+	// Options select the host, API URL and transport to mint tokens with
+	Options LoadOptions
+
+	mu    sync.Mutex
+	token AppToken
+}
+
+// Token returns a token that is valid for at least a few more minutes
+func (s *AppTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token.Token != "" && now().Add(appTokenRefreshMargin).Before(s.token.ExpiresAt) {
+		return s.token.Token, nil
+	}
+	token, err := MintInstallationToken(ctx, s.AppID, s.InstallationID, s.Key, s.Options)
+	if err != nil {
+		return "", err
+	}
+	s.token = token
+	return token.Token, nil
+}
+
+// apiRoot returns the base URL of the REST API on host, as go-gh's clients
+// use it
+func apiRoot(host string) string {
+	if auth.IsEnterprise(host) {
+		return fmt.Sprintf("https://%s/api/v3/", host)
+	}
+	return "https://api.github.com/"
+}
+
+// parseAppKey decodes a GitHub App private key, which GitHub issues in
+// PKCS #1 form, also accepting PKCS #8
+func parseAppKey(key []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, errors.New("invalid app private key: no PEM data found")
+	}
+
+	if privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return privateKey, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid app private key: %w", err)
+	}
+	privateKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid app private key: expected an RSA key, got %T", parsed)
+	}
+	return privateKey, nil
+}
+
+// appJWT returns the RS256 signed JWT a GitHub App authenticates with,
+// issued by the app at now
+func appJWT(appID int64, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("error signing app JWT: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,224 @@
+package prview_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestAppJWT(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate a key: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+
+	jwt, err := prview.AppJWT(12345, key, now)
+	if err != nil {
+		t.Fatalf("AppJWT returned an error: %v", err)
+	}
+
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("Expected a three part JWT, got %q", jwt)
+	}
+
+	var header map[string]string
+	decodeJWTPart(t, parts[0], &header)
+	if header["alg"] != "RS256" || header["typ"] != "JWT" {
+		t.Errorf("Unexpected JWT header: %v", header)
+	}
+
+	var claims struct {
+		IssuedAt  int64  `json:"iat"`
+		ExpiresAt int64  `json:"exp"`
+		Issuer    string `json:"iss"`
+	}
+	decodeJWTPart(t, parts[1], &claims)
+	if claims.Issuer != "12345" {
+		t.Errorf("Expected the app ID as the issuer, got %q", claims.Issuer)
+	}
+	if claims.IssuedAt != now.Unix()-60 {
+		t.Errorf("Expected the issue time to be backdated a minute, got %d", claims.IssuedAt)
+	}
+	if lifetime := claims.ExpiresAt - now.Unix(); lifetime <= 0 || lifetime > 600 {
+		t.Errorf("Expected the JWT to expire within GitHub's ten minute limit, got %ds", lifetime)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("Failed to decode the signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("Expected a valid RS256 signature: %v", err)
+	}
+}
+
+func decodeJWTPart(t *testing.T, part string, v interface{}) {
+	t.Helper()
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		t.Fatalf("Failed to decode JWT part %q: %v", part, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("Failed to parse JWT part %s: %v", data, err)
+	}
+}
+
+func TestInstallationToken(t *testing.T) {
+	t.Setenv("GH_HOST", "github.com")
+	t.Setenv("GH_API_URL", "")
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate a key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	tests := []struct {
+		name string
+		opts prview.LoadOptions
+		want string
+	}{
+		{"github.com", prview.LoadOptions{Repo: "owner/repo"}, "https://api.github.com/app/installations/678/access_tokens"},
+		{"enterprise", prview.LoadOptions{Repo: "ghe.example.com/owner/repo"}, "https://ghe.example.com/api/v3/app/installations/678/access_tokens"},
+		{"api url", prview.LoadOptions{Repo: "owner/repo", APIURL: "https://proxy.example.com/gh"}, "https://proxy.example.com/gh/app/installations/678/access_tokens"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *http.Request
+			tt.opts.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				got = req
+				return &http.Response{
+					StatusCode: http.StatusCreated,
+					Header:     http.Header{"Content-Type": []string{"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(`{"token": "ghs_installation", "expires_at": "2024-01-01T11:00:00Z"}`)),
+					Request:    req,
+				}, nil
+			})
+
+			token, err := prview.MintInstallationToken(context.Background(), 12345, 678, keyPEM, tt.opts)
+			if err != nil {
+				t.Fatalf("MintInstallationToken returned an error: %v", err)
+			}
+			if token.Token != "ghs_installation" || !token.ExpiresAt.Equal(time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)) {
+				t.Errorf("Expected the minted token and its expiry, got %+v", token)
+			}
+			if got.Method != http.MethodPost || got.URL.String() != tt.want {
+				t.Errorf("Expected POST %s, got %s %s", tt.want, got.Method, got.URL)
+			}
+			if !strings.HasPrefix(got.Header.Get("Authorization"), "Bearer ") {
+				t.Errorf("Expected the JWT as a bearer token, got %q", got.Header.Get("Authorization"))
+			}
+		})
+	}
+}
+
+func TestInstallationTokenCanceled(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate a key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, req.Context().Err()
+	})
+	if _, err := prview.MintInstallationToken(ctx, 1, 2, keyPEM, prview.LoadOptions{Repo: "owner/repo", Transport: rt}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the request to be canceled with its context, got %v", err)
+	}
+}
+
+func TestInstallationTokenInvalidKey(t *testing.T) {
+	if _, err := prview.InstallationToken(1, 2, []byte("not a key")); err == nil {
+		t.Error("Expected an error for an invalid private key")
+	}
+}
+
+func TestInstallationTokenDefaultHost(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate a key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/app/installations/678/access_tokens" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"token": "ghs_default"}`)
+	}))
+	defer srv.Close()
+	t.Setenv("GH_HOST", "github.com")
+	t.Setenv("GH_API_URL", srv.URL)
+
+	token, err := prview.InstallationToken(12345, 678, keyPEM)
+	if err != nil || token != "ghs_default" {
+		t.Errorf("Expected the minted token, got %q, %v", token, err)
+	}
+}
+
+func TestAppTokenSourceRefreshes(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate a key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	minted := 0
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		minted++
+		body := fmt.Sprintf(`{"token": "ghs_%d", "expires_at": %q}`, minted, start.Add(time.Duration(minted)*time.Hour).Format(time.RFC3339))
+		return &http.Response{
+			StatusCode: http.StatusCreated,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	source := &prview.AppTokenSource{AppID: 1, InstallationID: 2, Key: keyPEM, Options: prview.LoadOptions{Repo: "owner/repo", Transport: rt}}
+
+	for _, step := range []struct {
+		at   time.Duration
+		want string
+	}{
+		{0, "ghs_1"},
+		{50 * time.Minute, "ghs_1"},
+		// Within a few minutes of the first token's expiry
+		{57 * time.Minute, "ghs_2"},
+		{90 * time.Minute, "ghs_2"},
+	} {
+		prview.SetClock(t, start.Add(step.at))
+		token, err := source.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token returned an error: %v", err)
+		}
+		if token != step.want {
+			t.Errorf("At %v, expected %s, got %s", step.at, step.want, token)
+		}
+	}
+	if minted != 2 {
+		t.Errorf("Expected 2 tokens to be minted, got %d", minted)
+	}
+}
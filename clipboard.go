@@ -0,0 +1,94 @@
+package prview
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// Clipboard copies text to the system clipboard
+type Clipboard interface {
+	Copy(text []byte) error
+}
+
+// CommandClipboard copies by piping the text into a clipboard program, such
+// as pbcopy or xclip
+type CommandClipboard struct {
+	Name string
+	Args []string
+}
+
+// clipboardTimeout bounds how long a clipboard program may take
+const clipboardTimeout = 5 * time.Second
+
+func (c CommandClipboard) Copy(text []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), clipboardTimeout)
+	defer cancel()
+
+	// Stderr is left unset: xclip and xsel fork to serve the selection, and
+	// the child holding a pipe of ours open would keep Run from returning
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Stdin = bytes.NewReader(text)
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%s timed out after %s", c.Name, clipboardTimeout)
+		}
+		return fmt.Errorf("%s: %w", c.Name, err)
+	}
+	return nil
+}
+
+// OSC52Clipboard copies by writing the text to W, a terminal, in an OSC 52
+// escape sequence, which terminals that support it put on the clipboard.
+// This works over SSH, where there is no local clipboard program to run.
+type OSC52Clipboard struct {
+	W io.Writer
+}
+
+func (c OSC52Clipboard) Copy(text []byte) error {
+	_, err := fmt.Fprintf(c.W, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString(text))
+	return err
+}
+
+// clipboardCommands are the clipboard programs SelectClipboard looks for, in
+// order, each with the environment variable naming the display it needs, if
+// any
+var clipboardCommands = []struct {
+	env     string
+	command CommandClipboard
+}{
+	{"", CommandClipboard{Name: "pbcopy"}},
+	{"WAYLAND_DISPLAY", CommandClipboard{Name: "wl-copy"}},
+	{"DISPLAY", CommandClipboard{Name: "xclip", Args: []string{"-selection", "clipboard"}}},
+	{"DISPLAY", CommandClipboard{Name: "xsel", Args: []string{"--clipboard", "--input"}}},
+	// Windows, and WSL, where it copies to the Windows clipboard
+	{"", CommandClipboard{Name: "clip.exe"}},
+}
+
+// lookPath finds the clipboard programs on PATH. Tests replace it to choose
+// which are installed.
+var lookPath = exec.LookPath
+
+// SelectClipboard returns how to copy to the clipboard: with the first of
+// the clipboard programs installed that has a display to copy to, or when
+// there is none, with OSC 52 to terminal if isTerminal is set
+func SelectClipboard(terminal io.Writer, isTerminal bool) (Clipboard, error) {
+	for _, c := range clipboardCommands {
+		if c.env != "" && os.Getenv(c.env) == "" {
+			continue
+		}
+		if _, err := lookPath(c.command.Name); err == nil {
+			return c.command, nil
+		}
+	}
+	if isTerminal {
+		return OSC52Clipboard{W: terminal}, nil
+	}
+	return nil, errors.New("no clipboard available: install pbcopy, wl-copy, xclip or xsel, or write to a terminal that supports OSC 52")
+}
@@ -0,0 +1,70 @@
+package prview_test
+
+import (
+	"bytes"
+	"os/exec"
+	"reflect"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestSelectClipboard(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed []string
+		wayland   string
+		display   string
+		terminal  bool
+		want      prview.Clipboard
+	}{
+		{"wayland", []string{"wl-copy", "xclip"}, "wayland-0", ":0", false, prview.CommandClipboard{Name: "wl-copy"}},
+		{"x11", []string{"wl-copy", "xclip"}, "", ":0", false, prview.CommandClipboard{Name: "xclip", Args: []string{"-selection", "clipboard"}}},
+		{"xsel", []string{"xsel"}, "", ":0", true, prview.CommandClipboard{Name: "xsel", Args: []string{"--clipboard", "--input"}}},
+		{"no display", []string{"xclip"}, "", "", true, prview.OSC52Clipboard{}},
+		{"mac", []string{"pbcopy"}, "", "", true, prview.CommandClipboard{Name: "pbcopy"}},
+		{"nothing", nil, "", "", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WAYLAND_DISPLAY", tt.wayland)
+			t.Setenv("DISPLAY", tt.display)
+			prview.SetLookPath(t, func(name string) (string, error) {
+				for _, installed := range tt.installed {
+					if name == installed {
+						return "/usr/bin/" + name, nil
+					}
+				}
+				return "", exec.ErrNotFound
+			})
+
+			var terminal bytes.Buffer
+			got, err := prview.SelectClipboard(&terminal, tt.terminal)
+			if tt.want == nil {
+				if err == nil {
+					t.Fatalf("Expected an error with no clipboard, got %#v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SelectClipboard returned an error: %v", err)
+			}
+			if _, ok := tt.want.(prview.OSC52Clipboard); ok {
+				tt.want = prview.OSC52Clipboard{W: &terminal}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestOSC52Clipboard(t *testing.T) {
+	var terminal bytes.Buffer
+	if err := (prview.OSC52Clipboard{W: &terminal}).Copy([]byte("PR #1")); err != nil {
+		t.Fatalf("Copy returned an error: %v", err)
+	}
+	if got, want := terminal.String(), "\x1b]52;c;UFIgIzE=\a"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,52 @@
+package prview
+
+import (
+	"fmt"
+	"time"
+)
+
+// now returns the current time. Tests replace it to fix the clock.
+var now = time.Now
+
+// timestampLayout is how text output shows absolute times
+const timestampLayout = "2006-01-02 15:04:05"
+
+// timestamp formats t for text output, relative to now when
+// opts.RelativeTimes is set
+func (opts RenderOptions) timestamp(t time.Time) string {
+	if opts.RelativeTimes {
+		return relativeTime(t, now())
+	}
+	return t.Format(timestampLayout)
+}
+
+// relativeTime describes t from the point of view of current, e.g.
+// "2 hours ago" or "in 5 minutes", rounding down to the largest whole unit
+func relativeTime(t, current time.Time) string {
+	d := current.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	if d < time.Minute {
+		return "just now"
+	}
+
+	var amount string
+	switch day := 24 * time.Hour; {
+	case d < time.Hour:
+		amount = pluralize(int(d/time.Minute), "minute")
+	case d < day:
+		amount = pluralize(int(d/time.Hour), "hour")
+	case d < 30*day:
+		amount = pluralize(int(d/day), "day")
+	case d < 365*day:
+		amount = pluralize(int(d/(30*day)), "month")
+	default:
+		amount = pluralize(int(d/(365*day)), "year")
+	}
+	if future {
+		return fmt.Sprintf("in %s", amount)
+	}
+	return amount + " ago"
+}
@@ -0,0 +1,59 @@
+package prview_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestRenderRelativeTimes(t *testing.T) {
+	prview.SetClock(t, goldenTime)
+	pr := createMockPRAt(goldenTime.Add(-2 * time.Hour))
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{RelativeTimes: true}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output := buf.String()
+
+	for _, expected := range []string{
+		"Created: 4 hours ago\n",
+		"commenter1 COMMENTED at 3 hours ago\n",
+		"reviewer1 APPROVED at 2 hours ago\n",
+		"@reviewer1 at 2 hours ago:\n",
+		"commenter2 COMMENTED at 2 hours ago\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestRelativeTimeUnits(t *testing.T) {
+	prview.SetClock(t, goldenTime)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{30 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{59 * time.Minute, "59 minutes ago"},
+		{25 * time.Hour, "1 day ago"},
+		{45 * 24 * time.Hour, "1 month ago"},
+		{800 * 24 * time.Hour, "2 years ago"},
+		{-5 * time.Minute, "in 5 minutes"},
+	}
+	for _, tt := range tests {
+		comment := prview.Comment{Body: "Hi", CreatedAt: goldenTime.Add(-tt.ago), User: prview.User{Login: "alice"}, DiffHunk: "@@ -1 +1 @@"}
+		var buf bytes.Buffer
+		if err := prview.RenderComment(&buf, comment, prview.RenderOptions{RelativeTimes: true}); err != nil {
+			t.Fatalf("RenderComment returned an error: %v", err)
+		}
+		if !strings.Contains(buf.String(), "@alice at "+tt.want+":\n") {
+			t.Errorf("Expected %v ago to render as %q, got:\n%s", tt.ago, tt.want, buf.String())
+		}
+	}
+}
@@ -1,35 +1,613 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	prview "github.com/bmon/gh-prview"
+	"github.com/bmon/gh-prview/tui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/cli/go-gh/v2/pkg/term"
 )
 
+// stringList collects the values of a flag that may be repeated
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// Exit codes. Usage errors exit with 2, as the flag package does, so a
+// missing PR gets a code of its own.
+const (
+	exitError    = 1
+	exitNotFound = 3
+	exitStale    = 4
+)
+
+// exitCode returns the status to exit with after err
+func exitCode(err error) int {
+	if errors.Is(err, prview.ErrPRNotFound) {
+		return exitNotFound
+	}
+	return exitError
+}
+
 func main() {
+	var onlyFiles stringList
+	flag.Var(&onlyFiles, "only-files", "only show review threads on files matching `GLOB` (repeatable)")
+	var emitFlags stringList
+	flag.Var(&emitFlags, "emit", "also write the PR in `FORMAT=PATH`, e.g. json=report.json (repeatable)")
+	format := flag.String("format", "auto", "output `format`: auto, text, json, jsonl, patch, html, csv or files; auto is text, wrapped to the window on a terminal")
+	output := flag.String("output", "", "write the output to `file` instead of stdout")
+	clipboard := flag.Bool("clipboard", false, "copy the output to the clipboard instead of writing it to stdout")
+	repo := flag.String("repo", "", "select another repository using the [HOST/]OWNER/REPO format")
+	apiURL := flag.String("api-url", "", "send API requests to this base `URL`, e.g. a proxy (default $GH_API_URL)")
+	appID := flag.Int64("app-id", 0, "authenticate as the GitHub App with this `ID`, with --installation-id and --app-key")
+	installationID := flag.Int64("installation-id", 0, "the `ID` of the GitHub App installation to authenticate as")
+	appKey := flag.String("app-key", "", "the `file` holding the GitHub App's private key")
+	branch := flag.String("branch", "", "show the open PR for this `branch` instead of the current one")
+	sha := flag.String("sha", "", "show the PR that introduced this commit `SHA` instead of the current branch's")
+	expandHunk := flag.Int("expand-hunk", 0, "widen each review thread's diff hunk by up to `N` lines from the PR's diff")
+	contextLines := flag.Int("context", 0, "show `N` lines of file content around each review thread")
+	outdatedNow := flag.Bool("outdated-now", false, "show the current code alongside the diff of each outdated review thread")
+	sortBySeverity := flag.Bool("sort-by-severity", false, "order review threads by their blocker:, question: or nit: prefix")
+	squashPreview := flag.Bool("squash-preview", false, "print the default squash merge commit message instead of rendering the PR")
+	evalOutput := flag.Bool("eval", false, "print the PR's state and review counts as shell assignments instead of rendering it")
+	noBody := flag.Bool("no-body", false, "replace bodies with their length in JSON output")
+	jsonCompact := flag.Bool("json-compact", false, "write JSON output on a single line")
+	replyTo := flag.Int64("reply-to", 0, "reply to the review comment with this `ID` instead of rendering the PR")
+	replyBody := flag.String("body", "", "the `text` of the reply posted with --reply-to")
+	timeout := flag.Duration("timeout", 0, "give up loading the PR after this `duration`, e.g. 30s")
+	perPage := flag.Int("per-page", 100, "fetch paged listings `N` items per request, at most 100")
+	requestTimeout := flag.Duration("request-timeout", 0, "give up on an individual API request after this `duration`")
+	templateDir := flag.String("template-dir", "", "look for --template-name layouts in `dir`")
+	templateName := flag.String("template-name", "", "render the PR with the template `name`.tmpl from --template-dir")
+	reactionsDetail := flag.Bool("reactions-detail", false, "show who left each reaction on comments, which takes a request per comment with reactions")
+	resolveTeams := flag.Bool("resolve-teams", false, "follow each @org/team mention with the team's members in text output")
+	include := flag.String("include", "issue,review,inline", "comma separated `kinds` of discussion to show: issue comments, review summaries and inline review comments")
+	latency := flag.Bool("latency", false, "end with how long each reviewer took to first review the PR")
+	digest := flag.Bool("digest", false, "show each comment, review and commit as one short line, for scanning")
+	recap := flag.Bool("recap", false, "end with each reviewer's final state and number of inline comments")
+	relativeTimes := flag.Bool("relative-times", false, "show times relative to now, e.g. \"2 hours ago\"")
+	roundGap := flag.Duration("round-gap", 0, "split the timeline into review rounds wherever reviews are more than `duration` apart, e.g. 24h")
+	columns := flag.Int("columns", 1, "lay the timeline out across `N` columns, when writing to a terminal")
+	coalesce := flag.Bool("coalesce", false, "merge comments and reviews the same person posted within a couple of minutes into one block")
+	ascii := flag.Bool("ascii", false, "mark review states with ASCII symbols such as [+] instead of ✓")
+	showEmails := flag.Bool("show-emails", false, "show the name and email each commit was authored with")
+	showNoreply := flag.Bool("show-noreply", false, "with --show-emails, include GitHub noreply addresses")
+	staleDays := flag.Int("stale-days", 0, "flag the PR as stale when nothing has happened on it for more than `N` days")
+	failIfStale := flag.Bool("fail-if-stale", false, "with --stale-days, exit with status 4 when the PR is stale")
+	noHeader := flag.Bool("no-header", false, "leave out the PR header and render only the timeline")
+	headerOneline := flag.Bool("header-oneline", false, "show the PR header as one line: number, state, title, author and date")
+	bodyLines := flag.Int("body-lines", 0, "only show the first `N` lines of the PR body, or all of it when 0")
+	reviewID := flag.Int64("review", 0, "only render the review with this `ID`")
+	maxIndent := flag.Int("max-indent", 0, "nest replies to replies at most `N` levels deep, marking deeper ones with their depth, or without limit when 0")
+	indent := flag.Int("indent", 2, "indent nested text output by `N` spaces per level")
+	maxWidth := flag.Int("max-width", 0, "wrap text output to at most `N` columns, margin included")
+	margin := flag.Int("margin", 0, "indent every line of text output by `N` spaces")
+	expandAll := flag.Bool("expand-all", false, "render resolved threads and long diffs open in HTML output")
+	anonymize := flag.Bool("anonymize", false, "replace logins with stable pseudonyms such as user1")
+	redactURLs := flag.Bool("redact-urls", false, "with --anonymize, also replace URLs in bodies")
+	compactDiff := flag.Bool("compact-diff", false, "only show the changed lines of each diff hunk")
+	flatTimeline := flag.Bool("flat-timeline", false, "place review comments in the timeline by their own time instead of under their review")
+	groupByAuthor := flag.Bool("group-by-author", false, "render each person's comments and reviews together, most active first")
+	commentTasks := flag.Bool("comment-tasks", false, "include task list items in comments in the header's task progress")
+	plainDiff := flag.Bool("plain-diff", false, "show diff hunks as plain code without @@ headers or +/- markers")
+	diffLineNumbers := flag.Bool("diff-line-numbers", false, "prefix each line of a diff hunk with its old and new line numbers")
+	top := flag.Int("top", 0, "only render the `N` comments with the most reactions")
+	graphQL := flag.Bool("graphql", false, "load reviews and their comments with a single GraphQL query")
+	references := flag.Bool("references", false, "include the issues and PRs elsewhere that mention this PR in the timeline")
+	limit := flag.Int("limit", 0, "only load the first `N` comments and the first N reviews")
+	readStdin := flag.Bool("stdin", false, "read the PR as JSON from standard input instead of the API")
+	watch := flag.Bool("watch", false, "keep re-rendering the PR until interrupted")
+	interval := flag.Duration("interval", 30*time.Second, "how often --watch reloads the PR")
+	notify := flag.Bool("notify", false, "with --watch, ring the terminal bell when someone requests changes")
+	notifyDesktop := flag.Bool("notify-desktop", false, "with --notify, also show a desktop notification")
+	printRequestCount := flag.Bool("print-request-count", false, "report on stderr how many API requests were made")
+	noProgress := flag.Bool("no-progress", false, "don't show loading progress on a terminal")
+	onlyMyThreads := flag.Bool("only-my-threads", false, "only show review threads you commented in and comments by or mentioning you")
+	sinceMyLastReview := flag.Bool("since-my-last-review", false, "only show what happened after your most recent review")
+	showIgnored := flag.Bool("show-ignored", false, "show reviews and comments by the ignore_reviewers from config files")
+	excludeMe := flag.Bool("exclude-me", false, "leave out the comments and reviews you wrote")
+	openConcerns := flag.Bool("open-concerns", false, "only show what still needs attention: unresolved threads, reviews requesting changes and reviews with a summary")
+	grep := flag.String("grep", "", "only show comments, reviews and commits whose text contains `PATTERN`, ignoring case")
+	grepRegex := flag.Bool("grep-regex", false, "treat the --grep pattern as a regular expression")
+	warnUnchecked := flag.Bool("warn-unchecked", false, "warn on stderr about unchecked task list items in the PR description")
+	interactive := flag.Bool("interactive", false, "browse the timeline interactively instead of printing it")
+	links := flag.Bool("links", false, "print the links and image URLs referenced in the PR instead of rendering it")
+	flag.Parse()
+
 	// Parse command line arguments for PR number
 	var prNumber int
-	if len(os.Args) > 1 {
-		num, err := strconv.Atoi(os.Args[1])
+	if flag.NArg() > 0 {
+		num, err := strconv.Atoi(flag.Arg(0))
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: Invalid PR number: %v\n", err)
 			os.Exit(1)
 		}
 		prNumber = num
 	}
+	var grepPattern *regexp.Regexp
+	if *grep != "" {
+		re, err := prview.GrepPattern(*grep, *grepRegex)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --grep: %v\n", err)
+			os.Exit(1)
+		}
+		grepPattern = re
+	}
+	if *sha != "" && *branch != "" {
+		fmt.Fprintln(os.Stderr, "Error: --sha can't be combined with --branch")
+		os.Exit(1)
+	}
+
+	var emits []prview.Emit
+	emitPatch, emitFiles := false, false
+	for _, s := range emitFlags {
+		e, err := prview.ParseEmit(s)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		emits = append(emits, e)
+		emitPatch = emitPatch || e.Format == "patch"
+		emitFiles = emitFiles || e.Format == "files"
+	}
 
-	// Call the prview package to handle loading and rendering the PR
-	pr, err := prview.LoadPR(prNumber)
+	exclude, err := prview.ParseInclude(*include)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to load PR data: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	// Without reviews, there's no last review to show what came after
+	if *sinceMyLastReview && exclude.ReviewSummaries && exclude.InlineComments {
+		fmt.Fprintln(os.Stderr, "Error: --since-my-last-review requires --include to have review or inline")
+		os.Exit(1)
+	}
+
+	loadOpts := prview.LoadOptions{
+		Repo:            *repo,
+		Branch:          *branch,
+		SHA:             *sha,
+		ContextLines:    *contextLines,
+		ExpandHunk:      *expandHunk,
+		CommitDiffs:     *format == "patch" || emitPatch,
+		Files:           *format == "files" || emitFiles,
+		RequestTimeout:  *requestTimeout,
+		Limit:           *limit,
+		PerPage:         *perPage,
+		GraphQL:         *graphQL,
+		References:      *references,
+		ResolveTeams:    *resolveTeams,
+		ReactionDetails: *reactionsDetail,
+		Exclude:         exclude,
+		OutdatedCurrent: *outdatedNow,
+		APIURL:          *apiURL,
+	}
+	if *printRequestCount {
+		loadOpts.Requests = &prview.RequestCounter{}
+	}
+	// reportRequests writes the number of API requests made, with
+	// --print-request-count, once the run is over
+	reportRequests := func() {
+		if loadOpts.Requests == nil {
+			return
+		}
+		n := loadOpts.Requests.Count()
+		noun := "requests"
+		if n == 1 {
+			noun = "request"
+		}
+		fmt.Fprintf(os.Stderr, "Made %d API %s\n", n, noun)
+	}
+	if *maxIndent < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --max-indent must not be negative")
+		os.Exit(1)
+	}
+	if *columns < 1 {
+		fmt.Fprintln(os.Stderr, "Error: --columns must be at least 1")
+		os.Exit(1)
+	}
+	// Columns only make sense side by side on a screen; piped output keeps the
+	// single column other tools expect
+	if t := term.FromEnv(); *columns > 1 && t.IsTerminalOutput() {
+		if *maxWidth == 0 {
+			if width, _, err := t.Size(); err == nil {
+				*maxWidth = width
+			}
+		}
+	} else {
+		*columns = 1
+	}
+	renderOpts := prview.RenderOptions{
+		Format:          *format,
+		NoBody:          *noBody,
+		JSONCompact:     *jsonCompact,
+		Indent:          *indent,
+		MaxIndent:       *maxIndent,
+		CompactDiff:     *compactDiff,
+		FlatTimeline:    *flatTimeline,
+		CommentTasks:    *commentTasks,
+		GroupByAuthor:   *groupByAuthor,
+		MaxWidth:        *maxWidth,
+		Margin:          *margin,
+		ExpandAll:       *expandAll,
+		PlainDiff:       *plainDiff,
+		DiffLineNumbers: *diffLineNumbers,
+		BodyLines:       *bodyLines,
+		NoHeader:        *noHeader,
+		HeaderOneline:   *headerOneline,
+		StaleDays:       *staleDays,
+		ShowEmails:      *showEmails,
+		Coalesce:        *coalesce,
+		Columns:         *columns,
+		RoundGap:        *roundGap,
+		ASCII:           *ascii,
+		ShowNoreply:     *showNoreply,
+		RelativeTimes:   *relativeTimes,
+		Recap:           *recap,
+		Digest:          *digest,
+		Latency:         *latency,
+		Exclude:         exclude,
+		Highlight:       grepPattern,
+	}
+
+	// withTimeout bounds a single load of the PR by --timeout
+	withTimeout := func(ctx context.Context) (context.Context, context.CancelFunc) {
+		if *timeout > 0 {
+			return context.WithTimeout(ctx, *timeout)
+		}
+		return context.WithCancel(ctx)
+	}
+
+	// appTokens re-mints the installation token as it nears expiry, which
+	// a long --watch outlives
+	var appTokens *prview.AppTokenSource
+	if *appID != 0 || *installationID != 0 || *appKey != "" {
+		if *appID == 0 || *installationID == 0 || *appKey == "" {
+			fmt.Fprintln(os.Stderr, "Error: --app-id, --installation-id and --app-key must be given together")
+			os.Exit(1)
+		}
+		key, err := os.ReadFile(*appKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		appTokens = &prview.AppTokenSource{AppID: *appID, InstallationID: *installationID, Key: key, Options: loadOpts}
+		ctx, cancel := withTimeout(context.Background())
+		loadOpts.AuthToken, err = appTokens.Token(ctx)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *replyTo != 0 {
+		if *replyBody == "" {
+			fmt.Fprintln(os.Stderr, "Error: --reply-to requires --body")
+			os.Exit(1)
+		}
+		ctx, cancel := withTimeout(context.Background())
+		defer cancel()
+		reply, err := prview.PostReply(ctx, prNumber, *replyTo, *replyBody, loadOpts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to post reply: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		prview.RenderComment(os.Stdout, reply, renderOpts)
+		return
+	}
+
+	var myLogin string
+	if *onlyMyThreads || *excludeMe || *sinceMyLastReview {
+		ctx, cancel := withTimeout(context.Background())
+		login, err := prview.CurrentLogin(ctx, loadOpts)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		myLogin = login
+	}
+
+	cfg, err := prview.LoadConfig(prview.ConfigPaths()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	renderOpts.Glyphs = cfg.Glyphs
+	if (*templateDir == "") != (*templateName == "") {
+		fmt.Fprintln(os.Stderr, "Error: --template-dir and --template-name must be given together")
+		os.Exit(1)
+	}
+	if *templateName != "" {
+		renderOpts.Template, err = prview.LoadTemplate(*templateDir, *templateName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	severityKeywords := prview.DefaultSeverityKeywords
+	if len(cfg.SeverityKeywords) > 0 {
+		severityKeywords = prview.SeverityRanks(cfg.SeverityKeywords)
+	}
+	var ignoreReviewers []string
+	if !*showIgnored {
+		ignoreReviewers = cfg.IgnoreReviewers
+	}
+
+	// Progress goes to stderr, so it only makes sense when that's a terminal
+	showProgress := !*noProgress && term.IsTerminal(os.Stderr)
+
+	// load loads the PR, returning it both as loaded and as filtered and
+	// transformed for rendering
+	load := func(ctx context.Context) (loaded, pr prview.PullRequest, err error) {
+		ctx, cancel := withTimeout(ctx)
+		defer cancel()
+
+		if *readStdin {
+			pr, err = prview.ReadPR(os.Stdin)
+		} else {
+			opts := loadOpts
+			if appTokens != nil {
+				if opts.AuthToken, err = appTokens.Token(ctx); err != nil {
+					return pr, pr, err
+				}
+			}
+			if showProgress {
+				sp := startSpinner(os.Stderr)
+				opts.Progress = sp.Update
+				pr, err = prview.LoadPR(ctx, prNumber, opts)
+				sp.Stop()
+			} else {
+				pr, err = prview.LoadPR(ctx, prNumber, opts)
+			}
+		}
+		if err != nil {
+			return pr, pr, fmt.Errorf("Failed to load PR data: %w", err)
+		}
+		loaded = pr
+		if *warnUnchecked {
+			prview.WarnUnchecked(os.Stderr, pr)
+		}
+
+		// Find my last review before the filters below can remove it, such
+		// as --open-concerns dropping a bare approval
+		lastReview, reviewed := prview.LastReviewBy(loaded, myLogin)
+		pr = prview.ExcludeKinds(pr, exclude)
+		if len(ignoreReviewers) > 0 {
+			pr = prview.FilterAuthors(pr, ignoreReviewers, true)
+		}
+		pr = prview.FilterFiles(pr, onlyFiles)
+		if *openConcerns {
+			pr = prview.FilterOpenConcerns(pr)
+		}
+		if grepPattern != nil {
+			pr = prview.FilterGrep(pr, grepPattern)
+		}
+		if *onlyMyThreads {
+			pr = prview.FilterMyThreads(pr, myLogin)
+		}
+		if *sinceMyLastReview {
+			if reviewed {
+				pr = prview.FilterSince(pr, lastReview)
+			} else {
+				fmt.Fprintf(os.Stderr, "Note: you haven't reviewed PR #%d, showing everything\n", pr.Number)
+			}
+		}
+		if *excludeMe {
+			pr = prview.FilterAuthors(pr, []string{myLogin}, true)
+		}
+		if *anonymize {
+			pr = prview.Anonymize(pr, *redactURLs)
+		}
+		if *sortBySeverity {
+			pr = prview.SortBySeverity(pr, severityKeywords)
+		}
+		return loaded, pr, nil
+	}
+
+	// show loads the PR and writes it to w in the requested form, returning
+	// the PR as loaded
+	show := func(ctx context.Context, w io.Writer) (prview.PullRequest, error) {
+		loaded, pr, err := load(ctx)
+		if err != nil {
+			return loaded, err
+		}
+
+		if *links {
+			for _, link := range prview.ExtractLinks(pr) {
+				fmt.Fprintln(w, link)
+			}
+			return loaded, nil
+		}
+
+		if *reviewID != 0 {
+			review, findErr := prview.FindReview(pr, *reviewID)
+			if findErr != nil {
+				return loaded, fmt.Errorf("Error: %w", findErr)
+			}
+			err = prview.RenderReview(w, review, renderOpts)
+		} else if *top > 0 {
+			err = prview.RenderTopComments(w, pr, *top, renderOpts)
+		} else if *squashPreview {
+			err = prview.RenderSquashPreview(w, pr)
+		} else if *evalOutput {
+			err = prview.RenderEval(w, pr)
+		} else {
+			err = prview.Render(w, pr, renderOpts)
+		}
+		if err != nil {
+			return loaded, fmt.Errorf("Failed to render: %w", err)
+		}
+		if err := prview.WriteEmits(pr, emits, renderOpts); err != nil {
+			return loaded, fmt.Errorf("Failed to render: %w", err)
+		}
+		return loaded, nil
+	}
+
+	if *failIfStale && *staleDays <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: --fail-if-stale requires --stale-days")
+		os.Exit(1)
+	}
+	if *failIfStale && *watch {
+		fmt.Fprintln(os.Stderr, "Error: --fail-if-stale can't be combined with --watch")
+		os.Exit(1)
+	}
+	if *notify && !*watch {
+		fmt.Fprintln(os.Stderr, "Error: --notify requires --watch")
 		os.Exit(1)
 	}
+	if *interactive {
+		if *watch {
+			fmt.Fprintln(os.Stderr, "Error: --interactive can't be combined with --watch")
+			os.Exit(1)
+		}
+		_, pr, err := load(context.Background())
+		reportRequests()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitCode(err))
+		}
+		var options []tea.ProgramOption
+		if *readStdin {
+			// The PR came down the pipe, so keys have to come from the terminal
+			options = append(options, tea.WithInputTTY())
+		}
+		if err := tui.Run(pr, renderOpts, options...); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	err = prview.RenderPR(os.Stdout, pr)
+	if *output != "" && *watch {
+		fmt.Fprintln(os.Stderr, "Error: --output can't be combined with --watch")
+		os.Exit(1)
+	}
+	if *clipboard && (*watch || *output != "") {
+		fmt.Fprintln(os.Stderr, "Error: --clipboard can't be combined with --watch or --output")
+		os.Exit(1)
+	}
+	if !*watch {
+		var w io.Writer = os.Stdout
+		var copied bytes.Buffer
+		var cb prview.Clipboard
+		if *clipboard {
+			cb, err = prview.SelectClipboard(os.Stdout, term.FromEnv().IsTerminalOutput())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			w = &copied
+		}
+		var f *os.File
+		if *output != "" {
+			f, err = os.Create(*output)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			w = f
+		}
+		loaded, err := show(context.Background(), w)
+		reportRequests()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitCode(err))
+		}
+		// Closing is when a full disk or a failed network write shows up
+		if f != nil {
+			if err := f.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: failed to write %s: %v\n", *output, err)
+				os.Exit(1)
+			}
+		}
+		if cb != nil {
+			if err := cb.Copy(copied.Bytes()); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: failed to copy to the clipboard: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Fprintf(os.Stderr, "Copied PR #%d to the clipboard\n", loaded.Number)
+		}
+		if *failIfStale && prview.IsStale(loaded, *staleDays) {
+			os.Exit(exitStale)
+		}
+		return
+	}
+
+	if *readStdin {
+		fmt.Fprintln(os.Stderr, "Error: --watch can't be combined with --stdin")
+		os.Exit(1)
+	}
+	if *interval <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: --interval must be positive")
+		os.Exit(1)
+	}
+	// Each tick renders into a buffer, so settle auto against the real output
+	renderOpts = prview.ResolveFormat(os.Stdout, renderOpts)
+	// Revalidate with ETags rather than downloading everything every tick
+	loadOpts.Transport = prview.NewConditionalTransport(nil)
+	clearScreen := term.FromEnv().IsTerminalOutput()
+
+	var notifiers []prview.Notifier
+	if *notify {
+		notifiers = append(notifiers, prview.BellNotifier{W: os.Stdout})
+		if *notifyDesktop {
+			notifiers = append(notifiers, prview.DesktopNotifier{})
+		}
+	}
+	var tracker prview.ReviewTracker
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err = prview.Watch(ctx, *interval, prview.RealClock, func(ctx context.Context) error {
+		// Render into a buffer first so the screen isn't blank while loading
+		var buf bytes.Buffer
+		pr, err := show(ctx, &buf)
+		if err != nil {
+			// Keep watching through transient failures such as network errors
+			if ctx.Err() == nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			return nil
+		}
+		if clearScreen {
+			fmt.Print("\x1b[H\x1b[2J")
+		}
+		if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
+			return err
+		}
+
+		for _, review := range tracker.NewChangeRequests(pr) {
+			for _, n := range notifiers {
+				if err := n.Notify(pr, review); err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to notify: %v\n", err)
+				}
+			}
+		}
+		return nil
+	})
+	reportRequests()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to render: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
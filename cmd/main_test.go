@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"no PR for branch", fmt.Errorf("Failed to load PR data: %w", fmt.Errorf("error determining PR number: %w", prview.ErrPRNotFound)), exitNotFound},
+		{"rate limited", fmt.Errorf("Failed to load PR data: %w", prview.ErrRateLimited), exitError},
+		{"other failure", errors.New("connection refused"), exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// spinnerFrames are drawn in turn to show that loading is still going
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// spinner redraws a single line on a terminal with the current loading phase
+type spinner struct {
+	w io.Writer
+
+	mu    sync.Mutex
+	phase string
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// startSpinner starts drawing a spinner to w until Stop is called
+func startSpinner(w io.Writer) *spinner {
+	s := &spinner{w: w, done: make(chan struct{}), stopped: make(chan struct{})}
+	go s.run()
+	return s
+}
+
+func (s *spinner) run() {
+	defer close(s.stopped)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for frame := 0; ; frame++ {
+		s.mu.Lock()
+		phase := s.phase
+		s.mu.Unlock()
+		if phase != "" {
+			fmt.Fprintf(s.w, "\r\x1b[K%s %s...", spinnerFrames[frame%len(spinnerFrames)], phase)
+		}
+
+		select {
+		case <-s.done:
+			fmt.Fprint(s.w, "\r\x1b[K")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Update sets the phase shown next to the spinner. It is safe to call from
+// any goroutine.
+func (s *spinner) Update(phase string) {
+	s.mu.Lock()
+	s.phase = phase
+	s.mu.Unlock()
+}
+
+// Stop clears the spinner's line and waits for it to stop drawing
+func (s *spinner) Stop() {
+	close(s.done)
+	<-s.stopped
+}
@@ -0,0 +1,88 @@
+package prview
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// coalesceWindow is how soon after one item by the same author another must
+// come for RenderOptions.Coalesce to merge them
+const coalesceWindow = 2 * time.Minute
+
+// itemAuthor returns the login of whoever wrote a comment or review item,
+// or "" for other items
+func itemAuthor(item TimelineItem) string {
+	switch item.Type {
+	case "comment", "review_comment":
+		return item.Comment.User.Login
+	case "review":
+		return item.Review.User.Login
+	}
+	return ""
+}
+
+// coalesces reports whether item follows prev closely enough, from the same
+// author, to be shown in the same block. Only issue comments and reviews
+// merge; review comments in a flat timeline stay separate.
+func coalesces(prev, item TimelineItem) bool {
+	mergeable := func(i TimelineItem) bool { return i.Type == "comment" || i.Type == "review" }
+	if !mergeable(prev) || !mergeable(item) || !strings.EqualFold(itemAuthor(prev), itemAuthor(item)) {
+		return false
+	}
+	gap := item.CreatedAt.Sub(prev.CreatedAt)
+	return gap >= 0 && gap <= coalesceWindow
+}
+
+// timelineBlocks splits the timeline into the blocks it is shown in: an item
+// each, or with opts.Coalesce, runs of items that coalesce with the one
+// before them
+func timelineBlocks(items []TimelineItem, opts RenderOptions) [][]TimelineItem {
+	var blocks [][]TimelineItem
+	for i, item := range items {
+		if i > 0 && opts.Coalesce && coalesces(items[i-1], item) {
+			blocks[len(blocks)-1] = append(blocks[len(blocks)-1], item)
+		} else {
+			blocks = append(blocks, []TimelineItem{item})
+		}
+	}
+	return blocks
+}
+
+// renderBlock writes the items of a block. Those after the first were
+// coalesced into it: a comment adds just its body, as author and time go
+// without saying, while a review keeps its state line.
+func renderBlock(w io.Writer, block []TimelineItem, opts RenderOptions) {
+	for i, item := range block {
+		if i == 0 {
+			renderTimelineItem(w, item, opts)
+			continue
+		}
+		fmt.Fprintln(w)
+		if item.Type == "comment" {
+			fmt.Fprintln(w, bodyText(item.Comment.Body, opts))
+		} else {
+			renderTimelineItem(w, item, opts)
+		}
+	}
+}
+
+// renderTimeline writes each block of the timeline followed by a rule, or
+// lays the blocks out in columns when opts.Columns is above one. Review
+// rounds, with opts.RoundGap, each open with a separator.
+func renderTimeline(w io.Writer, items []TimelineItem, opts RenderOptions) {
+	blocks := timelineBlocks(items, opts)
+	if opts.Columns > 1 {
+		renderColumns(w, blocks, opts)
+		return
+	}
+	round := rounds(blocks, opts)
+	for i, block := range blocks {
+		if n, ok := round[i]; ok {
+			fmt.Fprintln(w, roundSeparator(n, opts))
+		}
+		renderBlock(w, block, opts)
+		fmt.Fprintln(w, opts.rule("-"))
+	}
+}
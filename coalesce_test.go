@@ -0,0 +1,51 @@
+package prview_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestRenderCoalesce(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	pr := prview.PullRequest{
+		Number: 1,
+		Title:  "Coalesce",
+		Comments: []prview.Comment{
+			{ID: 1, Body: "One more thing: update the docs", CreatedAt: at.Add(20 * time.Second), User: prview.User{Login: "alice"}},
+			{ID: 2, Body: "Will do", CreatedAt: at.Add(time.Minute), User: prview.User{Login: "bob"}},
+			{ID: 3, Body: "Much later", CreatedAt: at.Add(time.Hour), User: prview.User{Login: "bob"}},
+		},
+		Reviews: []prview.Review{
+			{ID: 10, State: "CHANGES_REQUESTED", Body: "Needs tests", SubmittedAt: at, User: prview.User{Login: "alice"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{Coalesce: true, NoHeader: true}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	rule := strings.Repeat("-", 80) + "\n"
+	blocks := strings.Split(strings.TrimSuffix(buf.String(), rule), rule)
+	if len(blocks) != 3 {
+		t.Fatalf("Expected 3 blocks, got %d:\n%s", len(blocks), buf.String())
+	}
+	expected := "✗ alice CHANGES_REQUESTED at 2024-03-01 12:00:00\n\nNeeds tests\n\nOne more thing: update the docs\n"
+	if blocks[0] != expected {
+		t.Errorf("Expected alice's review and comment in one block:\n%s\ngot:\n%s", expected, blocks[0])
+	}
+	if !strings.Contains(blocks[2], "Much later") {
+		t.Errorf("Expected a comment an hour later to stay separate, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{NoHeader: true}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if n := strings.Count(buf.String(), rule); n != 4 {
+		t.Errorf("Expected every item in its own block without --coalesce, got %d rules:\n%s", n, buf.String())
+	}
+}
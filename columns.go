@@ -0,0 +1,113 @@
+package prview
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// columnGutter separates the columns of a multi-column layout
+const columnGutter = " │ "
+
+// renderColumns lays the timeline's blocks out newspaper style across
+// opts.Columns columns: in order down the first column, then down the next,
+// with the columns as close to the same height as whole blocks allow. Each
+// block is wrapped to the column width.
+func renderColumns(w io.Writer, blocks [][]TimelineItem, opts RenderOptions) {
+	n := opts.Columns
+	width := (opts.ruleWidth() - (n-1)*runewidth.StringWidth(columnGutter)) / n
+	if width < 1 {
+		width = 1
+	}
+	colOpts := opts
+	colOpts.MaxWidth, colOpts.Margin = width, 0
+	rule := colOpts.rule("-")
+
+	round := rounds(blocks, opts)
+	rendered := make([][]string, len(blocks))
+	heights := make([]int, len(blocks))
+	for i, block := range blocks {
+		var buf bytes.Buffer
+		if n, ok := round[i]; ok {
+			fmt.Fprintln(&buf, roundSeparator(n, opts))
+		}
+		renderBlock(&buf, block, colOpts)
+		text := fitLines(strings.TrimSuffix(buf.String(), "\n"), width, 0)
+		rendered[i] = append(strings.Split(text, "\n"), rule)
+		heights[i] = len(rendered[i])
+	}
+
+	columns := make([][]string, n)
+	for i, col := range packColumns(heights, n) {
+		columns[col] = append(columns[col], rendered[i]...)
+	}
+	height := 0
+	for _, col := range columns {
+		height = max(height, len(col))
+	}
+
+	for row := 0; row < height; row++ {
+		var line strings.Builder
+		for c, col := range columns {
+			cell := ""
+			if row < len(col) {
+				cell = col[row]
+			}
+			if c > 0 {
+				line.WriteString(columnGutter)
+			}
+			line.WriteString(cell)
+			if pad := width - runewidth.StringWidth(cell); pad > 0 && c < n-1 {
+				line.WriteString(strings.Repeat(" ", pad))
+			}
+		}
+		fmt.Fprintln(w, strings.TrimRight(line.String(), " "))
+	}
+}
+
+// packColumns assigns items of the given heights, in order, to n columns so
+// that each column holds a contiguous run of items and the tallest column is
+// as short as possible. It returns the column of each item; columns at the
+// end may be left empty when there are few items.
+func packColumns(heights []int, n int) []int {
+	fits := func(limit int) bool {
+		cols, used := 1, 0
+		for _, h := range heights {
+			if used > 0 && used+h > limit {
+				cols++
+				used = 0
+			}
+			used += h
+		}
+		return cols <= n
+	}
+
+	lo, hi := 0, 0
+	for _, h := range heights {
+		lo = max(lo, h)
+		hi += h
+	}
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if fits(mid) {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	assigned := make([]int, len(heights))
+	col, used := 0, 0
+	for i, h := range heights {
+		if used > 0 && used+h > lo {
+			col++
+			used = 0
+		}
+		used += h
+		assigned[i] = col
+	}
+	return assigned
+}
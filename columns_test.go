@@ -0,0 +1,67 @@
+package prview_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+	"github.com/mattn/go-runewidth"
+)
+
+func TestPackColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		heights []int
+		n       int
+		want    []int
+	}{
+		{"balanced", []int{3, 3, 3, 3}, 2, []int{0, 0, 1, 1}},
+		{"tall item alone", []int{10, 2, 3, 4}, 2, []int{0, 1, 1, 1}},
+		{"in order", []int{2, 2, 8, 2}, 3, []int{0, 0, 1, 2}},
+		{"fewer items than columns", []int{5, 4}, 3, []int{0, 1}},
+		{"single column", []int{1, 2, 3}, 1, []int{0, 0, 0}},
+		{"no items", nil, 2, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := prview.PackColumns(tt.heights, tt.n); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PackColumns(%v, %d) = %v, want %v", tt.heights, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderColumns(t *testing.T) {
+	pr := createMockPR()
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{Columns: 2, MaxWidth: 83, NoHeader: true}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if n := len([]rune(line)); n > 83 {
+			t.Errorf("Line is %d columns wide, want at most 83: %q", n, line)
+		}
+	}
+	if !strings.Contains(buf.String(), " │ ") {
+		t.Errorf("Expected columns separated by a gutter, got:\n%s", buf.String())
+	}
+}
+
+func TestRenderColumnsWideCharacters(t *testing.T) {
+	pr := createMockPR()
+	pr.Comments[0].Body = strings.Repeat("表", 50)
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{Columns: 2, MaxWidth: 83, NoHeader: true}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	// Each column is (83 - 3) / 2 = 40 columns wide
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if i := strings.Index(line, " │ "); i >= 0 && runewidth.StringWidth(line[:i]) != 40 {
+			t.Errorf("Expected the gutter at column 40, got %d: %q", runewidth.StringWidth(line[:i]), line)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package prview
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// coAuthorTrailer matches a Co-authored-by trailer, capturing the name and
+// the optional email address
+var coAuthorTrailer = regexp.MustCompile(`(?im)^co-authored-by:[ \t]*([^<\r\n]*?)[ \t]*(?:<([^>\r\n]*)>)?[ \t]*$`)
+
+// CoAuthors returns the names credited in Co-authored-by trailers across the
+// PR's commits, in order of first appearance. People are deduplicated by
+// email address, or by name when a trailer has no address.
+func CoAuthors(pr PullRequest) []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	for _, commit := range pr.Commits {
+		for _, match := range coAuthorTrailer.FindAllStringSubmatch(commit.Body, -1) {
+			name, email := match[1], match[2]
+			if name == "" {
+				name = email
+			}
+			key := strings.ToLower(email)
+			if key == "" {
+				key = strings.ToLower(name)
+			}
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// commitAuthor formats the name and email a commit was authored with as
+// "Name <email>". GitHub's noreply addresses say nothing about the person,
+// so they are left out unless showNoreply is set.
+func commitAuthor(commit Commit, showNoreply bool) string {
+	email := commit.AuthorEmail
+	if !showNoreply && strings.Contains(strings.ToLower(email), "noreply") {
+		email = ""
+	}
+	if email == "" {
+		return commit.AuthorName
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s <%s>", commit.AuthorName, email))
+}
+
+// commitRange describes the commits the PR covers as its base and head
+// SHAs with a count, e.g. "abc1234..def5678 (7 commits)". It is empty
+// unless both SHAs are known; the count is left out if it isn't.
+func commitRange(pr PullRequest) string {
+	if pr.Base.SHA == "" || pr.Head.SHA == "" {
+		return ""
+	}
+	r := shortSHA(pr.Base.SHA) + ".." + shortSHA(pr.Head.SHA)
+	count := pr.CommitCount
+	if count == 0 {
+		count = len(pr.Commits)
+	}
+	if count > 0 {
+		r += " (" + pluralize(count, "commit") + ")"
+	}
+	return r
+}
@@ -0,0 +1,103 @@
+package prview_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func coAuthoredPR() prview.PullRequest {
+	return prview.PullRequest{
+		Number: 1,
+		Commits: []prview.Commit{
+			{
+				SHA:     "aaa",
+				Message: "Pair on the parser",
+				Body:    "Co-authored-by: Jane Doe <jane@example.com>\nCo-authored-by: John Smith <john@example.com>",
+			},
+			{
+				SHA:     "bbb",
+				Message: "Fix parser tests",
+				Body:    "Tidy up.\n\nco-authored-by: Jane Doe <JANE@example.com>",
+			},
+		},
+	}
+}
+
+func TestCoAuthors(t *testing.T) {
+	expected := []string{"Jane Doe", "John Smith"}
+	if got := prview.CoAuthors(coAuthoredPR()); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected co-authors %v, got %v", expected, got)
+	}
+}
+
+func TestRenderPRCoAuthors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, coAuthoredPR(), prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+
+	if count := strings.Count(buf.String(), "Co-authors: Jane Doe, John Smith\n"); count != 1 {
+		t.Errorf("Expected the co-authors line once, found it %d times in:\n%s", count, buf.String())
+	}
+}
+
+func TestRenderCommitEmails(t *testing.T) {
+	input := `{
+		"pull_request": {"number": 1, "title": "Emails", "user": {"login": "author"}},
+		"commits": [
+			{"sha": "aaaaaaa1", "commit": {"message": "First", "author": {"name": "Jane Doe", "email": "jane@example.com"}, "committer": {"date": "2024-03-01T10:00:00Z"}}, "author": {"login": "jane"}},
+			{"sha": "bbbbbbb2", "commit": {"message": "Second", "author": {"name": "John Smith", "email": "123+john@users.noreply.github.com"}, "committer": {"date": "2024-03-01T11:00:00Z"}}, "author": {"login": "john"}}
+		]
+	}`
+	pr, err := prview.ReadPR(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadPR returned an error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		opts        prview.RenderOptions
+		expected    []string
+		notExpected []string
+	}{
+		{
+			name:        "hidden by default",
+			opts:        prview.RenderOptions{},
+			notExpected: []string{"Author: Jane Doe"},
+		},
+		{
+			name:        "noreply suppressed",
+			opts:        prview.RenderOptions{ShowEmails: true},
+			expected:    []string{"  Author: Jane Doe <jane@example.com>\n", "  Author: John Smith\n"},
+			notExpected: []string{"noreply"},
+		},
+		{
+			name:     "noreply shown",
+			opts:     prview.RenderOptions{ShowEmails: true, ShowNoreply: true},
+			expected: []string{"  Author: John Smith <123+john@users.noreply.github.com>\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := prview.RenderPR(&buf, pr, tt.opts); err != nil {
+				t.Fatalf("RenderPR returned an error: %v", err)
+			}
+			for _, s := range tt.expected {
+				if !strings.Contains(buf.String(), s) {
+					t.Errorf("Expected %q in:\n%s", s, buf.String())
+				}
+			}
+			for _, s := range tt.notExpected {
+				if strings.Contains(buf.String(), s) {
+					t.Errorf("Didn't expect %q in:\n%s", s, buf.String())
+				}
+			}
+		})
+	}
+}
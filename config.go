@@ -0,0 +1,175 @@
+package prview
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// configFileName is the name of the config file at the root of a repository
+const configFileName = ".gh-prview.yml"
+
+// Config holds the settings read from config files
+type Config struct {
+	// IgnoreReviewers are the logins, typically bots, whose reviews and
+	// comments are hidden
+	IgnoreReviewers []string
+	// Glyphs override the symbols shown for review states, keyed by state.
+	// They are set with keys such as "approved_glyph".
+	Glyphs map[string]string
+	// SeverityKeywords are the comment prefixes --sort-by-severity ranks,
+	// most severe first, replacing DefaultSeverityKeywords when set
+	SeverityKeywords []string
+}
+
+// ConfigPaths returns where config files are looked for: the user's, under
+// their config directory, then the one at the root of the current git
+// repository. Either is left out when it can't be located.
+func ConfigPaths() []string {
+	var paths []string
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, "gh-prview", "config.yml"))
+	}
+	if out, err := runGit("rev-parse", "--show-toplevel"); err == nil {
+		paths = append(paths, filepath.Join(strings.TrimSpace(string(out)), configFileName))
+	}
+	return paths
+}
+
+// LoadConfig reads and merges the config files at paths, skipping any that
+// don't exist. Lists from later files are added to those from earlier ones,
+// except severity_keywords, which is a ranking and so is replaced, and their
+// other settings take precedence.
+func LoadConfig(paths ...string) (Config, error) {
+	var cfg Config
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Config{}, fmt.Errorf("error reading config: %w", err)
+		}
+		c, err := ParseConfig(f)
+		f.Close()
+		if err != nil {
+			return Config{}, fmt.Errorf("error reading %s: %w", path, err)
+		}
+		cfg.IgnoreReviewers = append(cfg.IgnoreReviewers, c.IgnoreReviewers...)
+		if c.SeverityKeywords != nil {
+			cfg.SeverityKeywords = c.SeverityKeywords
+		}
+		for state, glyph := range c.Glyphs {
+			if cfg.Glyphs == nil {
+				cfg.Glyphs = make(map[string]string)
+			}
+			cfg.Glyphs[state] = glyph
+		}
+	}
+	return cfg, nil
+}
+
+// ParseConfig reads a config file. The format is a small subset of YAML:
+// "key: value" lines, where a list is given either inline, as in
+// "ignore_reviewers: [dependabot[bot], codecov[bot]]", or as "- item" lines
+// below its key, and "<state>_glyph: ✓" sets the glyph for a review state.
+// Comments start with #; unknown keys are ignored.
+func ParseConfig(r io.Reader) (Config, error) {
+	var cfg Config
+	var list *[]string
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(stripConfigComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		if item, ok := strings.CutPrefix(line, "-"); ok {
+			if list == nil {
+				return Config{}, fmt.Errorf("line %d: list item outside a list", n)
+			}
+			*list = append(*list, unquote(strings.TrimSpace(item)))
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return Config{}, fmt.Errorf("line %d: expected key: value", n)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		list = nil
+		if state, ok := strings.CutSuffix(key, "_glyph"); ok {
+			if cfg.Glyphs == nil {
+				cfg.Glyphs = make(map[string]string)
+			}
+			cfg.Glyphs[strings.ToUpper(state)] = unquote(value)
+			continue
+		}
+		switch key {
+		case "ignore_reviewers":
+			list = &cfg.IgnoreReviewers
+		case "severity_keywords":
+			list = &cfg.SeverityKeywords
+		default:
+			continue
+		}
+
+		if value == "" {
+			continue
+		}
+		items, err := inlineList(value)
+		if err != nil {
+			return Config{}, fmt.Errorf("line %d: %w", n, err)
+		}
+		*list = append(*list, items...)
+		list = nil
+	}
+	if err := scanner.Err(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// inlineList parses a list written as [a, b, c]. Brackets inside items, as
+// in bot logins such as dependabot[bot], are kept.
+func inlineList(value string) ([]string, error) {
+	inner, ok := strings.CutPrefix(value, "[")
+	if ok {
+		inner, ok = strings.CutSuffix(inner, "]")
+	}
+	if !ok {
+		return nil, fmt.Errorf("expected a list in [brackets], got %q", value)
+	}
+
+	var items []string
+	for _, item := range strings.Split(inner, ",") {
+		if item = unquote(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// stripConfigComment removes a # comment from the end of a line. A # only
+// starts a comment at the start of the line or after whitespace, as in YAML.
+func stripConfigComment(line string) string {
+	for i, r := range line {
+		if r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// unquote removes matching single or double quotes around s
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,139 @@
+package prview_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "inline list",
+			input:    "ignore_reviewers: [dependabot[bot], \"codecov[bot]\"] # bots\n",
+			expected: []string{"dependabot[bot]", "codecov[bot]"},
+		},
+		{
+			name:     "block list",
+			input:    "# Hide the bots\nunknown: 1\nignore_reviewers:\n  - dependabot[bot]\n  - 'renovate[bot]'\n",
+			expected: []string{"dependabot[bot]", "renovate[bot]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := prview.ParseConfig(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ParseConfig returned an error: %v", err)
+			}
+			if !reflect.DeepEqual(cfg.IgnoreReviewers, tt.expected) {
+				t.Errorf("Expected %q, got %q", tt.expected, cfg.IgnoreReviewers)
+			}
+		})
+	}
+
+	if _, err := prview.ParseConfig(strings.NewReader("- stray\n")); err == nil {
+		t.Errorf("Expected an error for a list item without a key")
+	}
+}
+
+func TestLoadConfigIgnoresReviewers(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "config.yml")
+	repo := filepath.Join(dir, ".gh-prview.yml")
+	if err := os.WriteFile(user, []byte("ignore_reviewers: [codecov[bot]]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(repo, []byte("ignore_reviewers: [dependabot[bot]]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := prview.LoadConfig(user, filepath.Join(dir, "missing.yml"), repo)
+	if err != nil {
+		t.Fatalf("LoadConfig returned an error: %v", err)
+	}
+
+	now := time.Now()
+	pr := prview.PullRequest{
+		Number: 1,
+		Title:  "Bump deps",
+		Comments: []prview.Comment{
+			{ID: 1, Body: "Coverage report", CreatedAt: now, User: prview.User{Login: "codecov[bot]"}},
+			{ID: 2, Body: "Thanks!", CreatedAt: now.Add(time.Minute), User: prview.User{Login: "alice"}},
+		},
+		Reviews: []prview.Review{
+			{ID: 10, State: "COMMENTED", Body: "Rebased", SubmittedAt: now.Add(2 * time.Minute), User: prview.User{Login: "dependabot[bot]"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, prview.FilterAuthors(pr, cfg.IgnoreReviewers, true), prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output := buf.String()
+	if strings.Contains(output, "Coverage report") || strings.Contains(output, "Rebased") {
+		t.Errorf("Expected the ignored reviewers to be hidden, got:\n%s", output)
+	}
+	if !strings.Contains(output, "Thanks!") {
+		t.Errorf("Expected other comments to remain, got:\n%s", output)
+	}
+}
+
+func TestParseConfigGlyphs(t *testing.T) {
+	cfg, err := prview.ParseConfig(strings.NewReader("approved_glyph: \"👍\"\nchanges_requested_glyph: 🛑\n"))
+	if err != nil {
+		t.Fatalf("ParseConfig returned an error: %v", err)
+	}
+	if cfg.Glyphs["APPROVED"] != "👍" || cfg.Glyphs["CHANGES_REQUESTED"] != "🛑" {
+		t.Errorf("Unexpected glyphs: %v", cfg.Glyphs)
+	}
+}
+
+func TestLoadConfigSeverityKeywords(t *testing.T) {
+	dir := t.TempDir()
+	user := filepath.Join(dir, "config.yml")
+	repo := filepath.Join(dir, ".gh-prview.yml")
+	if err := os.WriteFile(user, []byte("severity_keywords: [blocker, nit]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(repo, []byte("severity_keywords:\n  - must\n  - Should\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := prview.LoadConfig(user, repo)
+	if err != nil {
+		t.Fatalf("LoadConfig returned an error: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.SeverityKeywords, []string{"must", "Should"}) {
+		t.Fatalf("Expected the repository's ranking to replace the user's, got %q", cfg.SeverityKeywords)
+	}
+
+	pr := prview.PullRequest{
+		Reviews: []prview.Review{{
+			ID: 1,
+			Threads: []prview.CommentThread{
+				{Comments: []prview.Comment{{ID: 1, Body: "blocker: leaks", Path: "a.go"}}},
+				{Comments: []prview.Comment{{ID: 2, Body: "should: rename", Path: "b.go"}}},
+				{Comments: []prview.Comment{{ID: 3, Body: "must: fix", Path: "c.go"}}},
+			},
+		}},
+	}
+	sorted := prview.SortBySeverity(pr, prview.SeverityRanks(cfg.SeverityKeywords))
+	var ids []int64
+	for _, thread := range sorted.Reviews[0].Threads {
+		ids = append(ids, thread.Comments[0].ID)
+	}
+	if !reflect.DeepEqual(ids, []int64{3, 2, 1}) {
+		t.Errorf("Expected the configured keywords to rank first, got %v", ids)
+	}
+}
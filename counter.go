@@ -0,0 +1,47 @@
+package prview
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// RequestCounter counts the API requests made through the clients of the
+// LoadOptions it is set on. Responses served from the cache aren't counted,
+// as they never reach GitHub.
+type RequestCounter struct {
+	n atomic.Int64
+}
+
+// Count returns how many requests have been made so far
+func (c *RequestCounter) Count() int {
+	return int(c.n.Load())
+}
+
+// countingTransport counts each request before passing it to base, or to
+// http.DefaultTransport when base is nil
+type countingTransport struct {
+	counter *RequestCounter
+	base    http.RoundTripper
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.counter.n.Add(1)
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// cacheable reports whether clients sending requests through rt should keep
+// go-gh's response cache: only those using the default transport, which a
+// counter or request timeout wrapped around it doesn't change
+func cacheable(rt http.RoundTripper) bool {
+	switch t := rt.(type) {
+	case *countingTransport:
+		return cacheable(t.base)
+	case *timeoutTransport:
+		return cacheable(t.base)
+	}
+	return rt == nil
+}
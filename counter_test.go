@@ -0,0 +1,30 @@
+package prview_test
+
+import (
+	"context"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestLoadPRCountsRequests(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7":           `{"number": 7, "title": "Counted", "user": {"login": "author"}}`,
+		"/repos/owner/repo/issues/7/comments": `[{"id": 1, "body": "Hello", "user": {"login": "bob"}}]`,
+		"/repos/owner/repo/pulls/7/reviews":   `[{"id": 10, "state": "APPROVED", "user": {"login": "bob"}}]`,
+		"/repos/owner/repo/pulls/7/comments":  `[]`,
+		"/repos/owner/repo/pulls/7/commits":   `[]`,
+	}}
+	counter := &prview.RequestCounter{}
+
+	if _, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt, Requests: counter}); err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+	if len(rt.requests) == 0 {
+		t.Fatal("Expected LoadPR to make requests")
+	}
+	if got := counter.Count(); got != len(rt.requests) {
+		t.Errorf("Expected a count of %d requests, got %d", len(rt.requests), got)
+	}
+}
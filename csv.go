@@ -0,0 +1,53 @@
+package prview
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvHeader names the columns RenderCSV writes
+var csvHeader = []string{"type", "author", "created_at", "state", "path", "line", "body"}
+
+// RenderCSV writes every comment and review on the PR as a CSV row, in the
+// order they were written. Review comments get rows of their own, with the
+// file and line they are on; state is only set for reviews.
+func RenderCSV(w io.Writer, pr PullRequest, opts RenderOptions) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, item := range flattenTimeline(buildTimeline(pr)) {
+		var record []string
+		switch item.Type {
+		case "comment", "review_comment":
+			c := item.Comment
+			line := c.Line
+			if line == nil {
+				line = c.OriginalLine
+			}
+			lineText := ""
+			if line != nil {
+				lineText = strconv.Itoa(*line)
+			}
+			record = []string{item.Type, c.User.Login, csvTime(c.CreatedAt), "", c.Path, lineText, normalizeNewlines(c.Body)}
+		case "review":
+			r := item.Review
+			record = []string{item.Type, r.User.Login, csvTime(r.SubmittedAt), r.State, "", "", normalizeNewlines(r.Body)}
+		default:
+			continue
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
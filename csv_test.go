@@ -0,0 +1,55 @@
+package prview_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestRenderCSV(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	pr := prview.PullRequest{
+		Number: 1,
+		Comments: []prview.Comment{
+			{ID: 1, Body: "Hello, world\nSecond line", CreatedAt: start, User: prview.User{Login: "alice"}},
+		},
+		Reviews: []prview.Review{{
+			ID: 10, Body: `Say "yes"`, State: "APPROVED", SubmittedAt: start.Add(time.Hour), User: prview.User{Login: "bob"},
+			Threads: []prview.CommentThread{{Comments: []prview.Comment{
+				{ID: 2, Body: "Nit", Path: "main.go", Line: intPtr(12), CreatedAt: start.Add(30 * time.Minute), User: prview.User{Login: "bob"}},
+			}}},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := prview.Render(&buf, pr, prview.RenderOptions{Format: "csv"}); err != nil {
+		t.Fatalf("Render returned an error: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "\"Hello, world\nSecond line\"") || !strings.Contains(buf.String(), `"Say ""yes"""`) {
+		t.Errorf("Expected bodies to be quoted, got:\n%s", buf.String())
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected valid CSV, got %v", err)
+	}
+	expected := [][]string{
+		{"type", "author", "created_at", "state", "path", "line", "body"},
+		{"comment", "alice", "2024-01-01T10:00:00Z", "", "", "", "Hello, world\nSecond line"},
+		{"review_comment", "bob", "2024-01-01T10:30:00Z", "", "main.go", "12", "Nit"},
+		{"review", "bob", "2024-01-01T11:00:00Z", "APPROVED", "", "", `Say "yes"`},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("Expected %d records, got %q", len(expected), records)
+	}
+	for i := range expected {
+		if strings.Join(records[i], "|") != strings.Join(expected[i], "|") {
+			t.Errorf("Record %d: expected %q, got %q", i, expected[i], records[i])
+		}
+	}
+}
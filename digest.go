@@ -0,0 +1,60 @@
+package prview
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// digestBodyRunes is how much of a body a digest line shows
+const digestBodyRunes = 60
+
+// RenderDigest writes every comment, review, commit and reference as a
+// single line, "15:04 alice [review/APPROVED]: the start of the body…", in
+// the order they happened. Review comments get lines of their own.
+func RenderDigest(w io.Writer, pr PullRequest, opts RenderOptions) error {
+	items := flattenTimeline(buildTimeline(pr))
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+
+	for _, item := range items {
+		kind, author, body := item.Type, itemAuthor(item), ""
+		switch item.Type {
+		case "comment", "review_comment":
+			body = item.Comment.Body
+		case "review":
+			kind += "/" + item.Review.State
+			body = item.Review.Body
+		case "commit":
+			author = item.Commit.Author.Login
+			body = item.Commit.Message
+		case "reference":
+			author = item.Reference.Actor.Login
+			body = item.Reference.Title
+		}
+		if author == "" {
+			author = "unknown"
+		}
+
+		line := fmt.Sprintf("%s %s [%s]", item.CreatedAt.Format("15:04"), author, kind)
+		if summary := digestSummary(body); summary != "" {
+			line += ": " + summary
+		}
+		if _, err := fmt.Fprintln(w, sanitizeForTerminal(line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// digestSummary collapses body onto one line and cuts it to
+// digestBodyRunes, ending it with an ellipsis when anything was cut
+func digestSummary(body string) string {
+	runes := []rune(strings.Join(strings.Fields(body), " "))
+	if len(runes) <= digestBodyRunes {
+		return string(runes)
+	}
+	return strings.TrimRight(string(runes[:digestBodyRunes]), " ") + "…"
+}
@@ -0,0 +1,42 @@
+package prview_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestRenderDigest(t *testing.T) {
+	pr := createMockPR()
+	pr.Comments[0].Body = "A long first line of review feedback that runs well past sixty characters\nand carries on"
+	pr.Comments[1].Body = "Ünïcödé " + strings.Repeat("é", 70)
+
+	var buf bytes.Buffer
+	if err := prview.Render(&buf, pr, prview.RenderOptions{Digest: true}); err != nil {
+		t.Fatalf("Render returned an error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+
+	// The two comments, the review and its inline comment
+	if len(lines) != 4 {
+		t.Fatalf("Expected one line per item, got %d:\n%s", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[0], pr.Comments[0].CreatedAt.Format("15:04")+" commenter1 [comment]: A long first line") {
+		t.Errorf("Unexpected first line %q", lines[0])
+	}
+	if !strings.Contains(lines[1], " reviewer1 [review/APPROVED]: Here's my review") {
+		t.Errorf("Expected the review second, got %q", lines[1])
+	}
+	if !strings.Contains(lines[2], " reviewer1 [review_comment]: This looks good") {
+		t.Errorf("Expected the inline comment third, got %q", lines[2])
+	}
+	for _, line := range []string{lines[0], lines[3]} {
+		_, body, _ := strings.Cut(line, "]: ")
+		if !strings.HasSuffix(body, "…") || utf8.RuneCountInString(body) > 61 || !utf8.ValidString(body) {
+			t.Errorf("Expected the body cut to 60 runes with an ellipsis, got %q", body)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package prview
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Emit asks for the PR to be rendered in Format and written to Path, in
+// addition to the main output
+type Emit struct {
+	Format string
+	Path   string
+}
+
+// ParseEmit parses an emit given as FORMAT=PATH, e.g. json=report.json
+func ParseEmit(s string) (Emit, error) {
+	format, path, ok := strings.Cut(s, "=")
+	if !ok || format == "" || path == "" {
+		return Emit{}, fmt.Errorf("invalid emit %q: expected FORMAT=PATH", s)
+	}
+	return Emit{Format: format, Path: path}, nil
+}
+
+// WriteEmits renders the PR once for each emit, writing each rendering to
+// its file. opts controls the rendering apart from the format. The emits are
+// rendered concurrently; if any fail, the error of the first of them is
+// returned.
+func WriteEmits(pr PullRequest, emits []Emit, opts RenderOptions) error {
+	errs := make([]error, len(emits))
+	var wg sync.WaitGroup
+	for i, e := range emits {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = writeEmit(pr, e, opts)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeEmit(pr PullRequest, e Emit, opts RenderOptions) error {
+	f, err := os.Create(e.Path)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", e.Path, err)
+	}
+
+	// The template, if any, is for the main output
+	opts.Format, opts.Template = e.Format, nil
+	if err := Render(f, pr, opts); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing %s: %w", e.Path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing %s: %w", e.Path, err)
+	}
+	return nil
+}
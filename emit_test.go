@@ -0,0 +1,129 @@
+package prview_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestWriteEmits(t *testing.T) {
+	dir := t.TempDir()
+	var emits []prview.Emit
+	for _, s := range []string{"text=" + filepath.Join(dir, "report.txt"), "json=" + filepath.Join(dir, "report.json")} {
+		e, err := prview.ParseEmit(s)
+		if err != nil {
+			t.Fatalf("ParseEmit(%q) returned an error: %v", s, err)
+		}
+		emits = append(emits, e)
+	}
+
+	if err := prview.WriteEmits(createMockPR(), emits, prview.RenderOptions{}); err != nil {
+		t.Fatalf("WriteEmits returned an error: %v", err)
+	}
+
+	text, err := os.ReadFile(filepath.Join(dir, "report.txt"))
+	if err != nil {
+		t.Fatalf("Expected the text report to be written: %v", err)
+	}
+	if !strings.HasPrefix(string(text), "PR #123: Test PR\n") {
+		t.Errorf("Unexpected text report:\n%s", text)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "report.json"))
+	if err != nil {
+		t.Fatalf("Expected the JSON report to be written: %v", err)
+	}
+	var doc struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Expected valid JSON, got %v:\n%s", err, data)
+	}
+	if doc.Number != 123 || doc.Title != "Test PR" {
+		t.Errorf("Unexpected JSON report: %+v", doc)
+	}
+}
+
+func TestParseEmitInvalid(t *testing.T) {
+	for _, s := range []string{"json", "=out.json", "json="} {
+		if _, err := prview.ParseEmit(s); err == nil {
+			t.Errorf("Expected an error for %q", s)
+		}
+	}
+}
+
+func TestWriteEmitsUnknownFormat(t *testing.T) {
+	err := prview.WriteEmits(createMockPR(), []prview.Emit{{Format: "yaml", Path: filepath.Join(t.TempDir(), "out.yaml")}}, prview.RenderOptions{})
+	if err == nil || !strings.Contains(err.Error(), `unknown format "yaml"`) {
+		t.Errorf("Expected an unknown format error, got %v", err)
+	}
+}
+
+// TestRenderConcurrently renders one PR in several formats at once, to be run
+// with -race, and checks each matches a rendering done on its own
+func TestRenderConcurrently(t *testing.T) {
+	pr := createMockPR()
+	opts := prview.RenderOptions{MaxWidth: 60, FlatTimeline: true}
+	// There is no Markdown format, so Markdown comes from a template, which
+	// the goroutines share
+	markdown := template.Must(template.New("markdown").Parse(
+		"## #{{ .Number }} {{ .Title }}\n{{ range .Timeline }}{{ with .Comment }}\n**{{ .User.Login }}**: {{ .Body }}\n{{ end }}{{ end }}"))
+	type variant struct {
+		name string
+		opts prview.RenderOptions
+	}
+	var variants []variant
+	for _, format := range []string{"text", "json", "html", "csv"} {
+		o := opts
+		o.Format = format
+		variants = append(variants, variant{format, o})
+	}
+	md := opts
+	md.Template = markdown
+	variants = append(variants, variant{"markdown", md})
+
+	expected := make(map[string]string)
+	for _, v := range variants {
+		var buf bytes.Buffer
+		if err := prview.Render(&buf, pr, v.opts); err != nil {
+			t.Fatalf("Render(%s) returned an error: %v", v.name, err)
+		}
+		expected[v.name] = buf.String()
+	}
+	if !strings.HasPrefix(expected["markdown"], "## #123 Test PR\n") || !strings.Contains(expected["markdown"], "**commenter1**: This is a regular comment\n") {
+		t.Fatalf("Unexpected Markdown output:\n%s", expected["markdown"])
+	}
+
+	const rounds = 5
+	results := make([]string, rounds*len(variants))
+	errs := make([]error, len(results))
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var buf bytes.Buffer
+			errs[i] = prview.Render(&buf, pr, variants[i%len(variants)].opts)
+			results[i] = buf.String()
+		}()
+	}
+	wg.Wait()
+
+	for i, got := range results {
+		name := variants[i%len(variants)].name
+		if errs[i] != nil {
+			t.Fatalf("Concurrent Render(%s) returned an error: %v", name, errs[i])
+		}
+		if got != expected[name] {
+			t.Errorf("Concurrent %s rendering differs from a sequential one:\n%s", name, got)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package prview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// Errors returned, wrapped, by LoadPR and the Fetch functions so callers can
+// tell failures apart with errors.Is. The underlying *api.HTTPError, if any,
+// is still available through errors.As.
+var (
+	// ErrPRNotFound means the pull request doesn't exist, or no open PR
+	// matches the branch being looked up
+	ErrPRNotFound = errors.New("pull request not found")
+	// ErrNoRepo means no repository was given and none could be determined
+	// from the working directory
+	ErrNoRepo = errors.New("no GitHub repository")
+	// ErrNoToken means there is no authentication token for the host
+	ErrNoToken = errors.New("no GitHub authentication token")
+	// ErrRateLimited means GitHub refused the request because the API rate
+	// limit has been exceeded
+	ErrRateLimited = errors.New("GitHub API rate limit exceeded")
+	// ErrRequestTimeout means a single API request took longer than
+	// LoadOptions.RequestTimeout allows. Running out of the overall time
+	// the context gives is context.DeadlineExceeded instead.
+	ErrRequestTimeout = errors.New("API request timed out")
+)
+
+// taggedError reports a specific message while matching one of the Err
+// values above with errors.Is
+type taggedError struct {
+	tag error
+	msg string
+}
+
+func (e *taggedError) Error() string {
+	return e.msg
+}
+
+func (e *taggedError) Unwrap() error {
+	return e.tag
+}
+
+// tagError returns an error with the formatted message that matches tag
+func tagError(tag error, format string, args ...interface{}) error {
+	return &taggedError{tag: tag, msg: fmt.Sprintf(format, args...)}
+}
+
+// doRequest sends a request with client, tagging rate limit failures with
+// ErrRateLimited
+func doRequest(ctx context.Context, client *api.RESTClient, method string, path string, body io.Reader, response interface{}) error {
+	return tagAPIError(client.DoWithContext(ctx, method, path, body, response))
+}
+
+// tagAPIError wraps rate limit failures from the API with ErrRateLimited
+func tagAPIError(err error) error {
+	var httpErr *api.HTTPError
+	if errors.As(err, &httpErr) && isRateLimited(httpErr) {
+		return fmt.Errorf("%w: %w", ErrRateLimited, err)
+	}
+	return err
+}
+
+// isRateLimited reports whether GitHub rejected a request for exceeding
+// either the primary or a secondary rate limit
+func isRateLimited(err *api.HTTPError) bool {
+	switch err.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		return err.Headers.Get("X-RateLimit-Remaining") == "0" || err.Headers.Get("Retry-After") != ""
+	}
+	return false
+}
+
+// isNotFound reports whether err is a 404 from the API
+func isNotFound(err error) bool {
+	var httpErr *api.HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound
+}
+
+// isInaccessible reports whether err is the API refusing to show something
+// the token may not see: a 404, or a 403 other than for the rate limit
+func isInaccessible(err error) bool {
+	var httpErr *api.HTTPError
+	if !errors.As(err, &httpErr) || errors.Is(err, ErrRateLimited) || isRateLimited(httpErr) {
+		return false
+	}
+	return httpErr.StatusCode == http.StatusNotFound || httpErr.StatusCode == http.StatusForbidden
+}
@@ -0,0 +1,72 @@
+package prview_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/repository"
+)
+
+func TestLoadPRNotFound(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{}}
+
+	_, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt})
+	if !errors.Is(err, prview.ErrPRNotFound) {
+		t.Fatalf("Expected ErrPRNotFound, got %v", err)
+	}
+	var httpErr *api.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected the underlying HTTP error to be preserved, got %v", err)
+	}
+}
+
+func TestLoadPRNoToken(t *testing.T) {
+	t.Setenv("GH_HOST", "github.com")
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	// Keep go-gh from asking an installed gh for a token
+	t.Setenv("PATH", t.TempDir())
+
+	_, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo"})
+	if !errors.Is(err, prview.ErrNoToken) {
+		t.Errorf("Expected ErrNoToken, got %v", err)
+	}
+}
+
+func TestLoadPRNoRepo(t *testing.T) {
+	prview.SetRepoResolver(t, func() (repository.Repository, error) {
+		return repository.Repository{}, errors.New("not a git repository")
+	})
+
+	_, err := prview.LoadPR(context.Background(), 1, prview.LoadOptions{})
+	if !errors.Is(err, prview.ErrNoRepo) {
+		t.Errorf("Expected ErrNoRepo, got %v", err)
+	}
+}
+
+func TestFetchRateLimited(t *testing.T) {
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusForbidden,
+			Header: http.Header{
+				"Content-Type":          []string{"application/json"},
+				"X-Ratelimit-Remaining": []string{"0"},
+			},
+			Body:    io.NopCloser(strings.NewReader(`{"message": "API rate limit exceeded"}`)),
+			Request: req,
+		}, nil
+	})
+
+	_, err := prview.FetchPRComments(context.Background(), newTestClient(t, rt), testRepo, 7, 0)
+	if !errors.Is(err, prview.ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got %v", err)
+	}
+}
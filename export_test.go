@@ -0,0 +1,61 @@
+package prview
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/repository"
+)
+
+// SetRepoResolver replaces the current repository resolver until the test ends
+func SetRepoResolver(t testing.TB, resolve func() (repository.Repository, error)) {
+	orig := currentRepo
+	currentRepo = resolve
+	t.Cleanup(func() { currentRepo = orig })
+}
+
+var AddFileContext = addFileContext
+
+var RunCommand = runCommand
+
+var OsascriptArgs = osascriptArgs
+
+// SetLookPath replaces how clipboard programs are found until the test ends
+func SetLookPath(t testing.TB, look func(name string) (string, error)) {
+	orig := lookPath
+	lookPath = look
+	t.Cleanup(func() { lookPath = orig })
+}
+
+// SetGitRunner replaces how git is run until the test ends
+func SetGitRunner(t testing.TB, run func(args ...string) ([]byte, error)) {
+	orig := runGit
+	runGit = run
+	t.Cleanup(func() { runGit = orig })
+}
+
+var SanitizeForTerminal = sanitizeForTerminal
+
+var DecodeComments = decodeArray[Comment]
+
+var AppJWT = appJWT
+
+var PackColumns = packColumns
+
+var NewGraphQLClient = newGraphQLClient
+
+// SetClock fixes the current time seen by the package until the test ends
+func SetClock(t testing.TB, at time.Time) {
+	orig := now
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = orig })
+}
+
+// SetTerminalWidth makes every writer look like a terminal of the given
+// width until the test ends
+func SetTerminalWidth(t testing.TB, width int) {
+	orig := terminalWidth
+	terminalWidth = func(io.Writer) (int, bool) { return width, true }
+	t.Cleanup(func() { terminalWidth = orig })
+}
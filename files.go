@@ -0,0 +1,140 @@
+package prview
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RenderFiles writes the diff of each file the PR changes with the review
+// threads on it placed under the lines they comment on, like GitHub's "Files
+// changed" tab. Threads that aren't on a line of the file's current diff,
+// such as outdated ones, follow it.
+func RenderFiles(w io.Writer, pr PullRequest, opts RenderOptions) error {
+	if len(pr.Files) == 0 {
+		return fmt.Errorf("PR #%d has no changed files to render", pr.Number)
+	}
+
+	return renderFitted(w, opts, func(w io.Writer) error {
+		indent := opts.indent()
+		for _, file := range pr.Files {
+			name := file.Filename
+			if file.PreviousFilename != "" {
+				name = file.PreviousFilename + " → " + file.Filename
+			}
+			fmt.Fprintln(w, sanitizeForTerminal(name))
+			fmt.Fprintln(w, opts.rule("-"))
+
+			threads := fileThreads(pr, file)
+			if file.Patch == "" {
+				// GitHub omits the patch for binary and very large files
+				fmt.Fprintf(w, "%s(diff not available)\n", indent)
+			}
+			for _, line := range patchLines(file.Patch) {
+				fmt.Fprintf(w, "%s%s\n", indent, sanitizeForTerminal(line.Text))
+				for i := 0; i < len(threads); i++ {
+					if onLine(threads[i].Comments[0], line) {
+						renderThreadComments(w, indent+indent, threads[i].Comments, opts)
+						threads = append(threads[:i], threads[i+1:]...)
+						i--
+					}
+				}
+			}
+
+			if len(threads) > 0 {
+				fmt.Fprintf(w, "\n%sNot on the current diff:\n", indent)
+				for _, thread := range threads {
+					root := thread.Comments[0]
+					location := root.Path
+					if root.OriginalLine != nil {
+						location += ":" + strconv.Itoa(*root.OriginalLine)
+					}
+					fmt.Fprintf(w, "%s%s\n", indent, sanitizeForTerminal(location))
+					renderThreadComments(w, indent+indent, thread.Comments, opts)
+				}
+			}
+			fmt.Fprintln(w, opts.rule("="))
+		}
+		return nil
+	})
+}
+
+// fileThreads returns the submitted review threads on file, under either of
+// its names, with their comments in order and the threads ordered by when
+// they were started
+func fileThreads(pr PullRequest, file ChangedFile) []CommentThread {
+	var threads []CommentThread
+	for _, review := range pr.Reviews {
+		for _, thread := range review.Threads {
+			if len(thread.Comments) == 0 {
+				continue
+			}
+			thread.Comments = chronological(thread.Comments)
+			path := thread.Comments[0].Path
+			if path == file.Filename || (file.PreviousFilename != "" && path == file.PreviousFilename) {
+				threads = append(threads, thread)
+			}
+		}
+	}
+	sort.SliceStable(threads, func(i, j int) bool {
+		return threads[i].Comments[0].CreatedAt.Before(threads[j].Comments[0].CreatedAt)
+	})
+	return threads
+}
+
+// patchLine is a line of a file's patch, with its line numbers in the old
+// and new versions of the file. Either is zero when the line isn't in that
+// version, as both are for hunk headers.
+type patchLine struct {
+	Text string
+	Old  int
+	New  int
+}
+
+// onLine reports whether a review comment is on line of the patch, looking
+// at the old version of the file for comments on the LEFT side
+func onLine(c Comment, line patchLine) bool {
+	if c.Line == nil {
+		return false
+	}
+	if c.Side == "LEFT" {
+		return line.Old != 0 && *c.Line == line.Old
+	}
+	return line.New != 0 && *c.Line == line.New
+}
+
+// patchLines splits a patch into its lines, numbering them from the hunk
+// headers
+func patchLines(patch string) []patchLine {
+	if patch == "" {
+		return nil
+	}
+
+	var lines []patchLine
+	nextOld, nextNew := 0, 0
+	for _, text := range strings.Split(strings.TrimSuffix(normalizeNewlines(patch), "\n"), "\n") {
+		line := patchLine{Text: text}
+		switch {
+		case strings.HasPrefix(text, "@@"):
+			if m := hunkHeaderPattern.FindStringSubmatch(text); m != nil {
+				nextOld, _ = strconv.Atoi(m[1])
+				nextNew, _ = strconv.Atoi(m[2])
+			}
+		case strings.HasPrefix(text, `\`):
+		case strings.HasPrefix(text, "-"):
+			line.Old = nextOld
+			nextOld++
+		case strings.HasPrefix(text, "+"):
+			line.New = nextNew
+			nextNew++
+		default:
+			line.Old, line.New = nextOld, nextNew
+			nextOld++
+			nextNew++
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,65 @@
+package prview_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestRenderFiles(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	pr := prview.PullRequest{
+		Number: 7,
+		Files: []prview.ChangedFile{
+			{Filename: "main.go", Status: "modified", Patch: "@@ -1,3 +1,4 @@\n package main\n-import \"fmt\"\n+import \"os\"\n+import \"fmt\"\n func main() {}"},
+			{Filename: "util.go", Status: "added", Patch: "@@ -0,0 +1,2 @@\n+package main\n+func helper() {}"},
+		},
+		Reviews: []prview.Review{{
+			ID:    10,
+			State: "COMMENTED",
+			User:  prview.User{Login: "bob"},
+			Threads: []prview.CommentThread{
+				{Comments: []prview.Comment{{ID: 1, Path: "util.go", Line: intPtr(2), Body: "Unused?", CreatedAt: now, User: prview.User{Login: "bob"}}}},
+				{Comments: []prview.Comment{{ID: 2, Path: "main.go", Line: intPtr(2), Body: "Why os?", CreatedAt: now, User: prview.User{Login: "bob"}}}},
+				{Comments: []prview.Comment{{ID: 3, Path: "main.go", OriginalLine: intPtr(9), Body: "Old note", CreatedAt: now, User: prview.User{Login: "bob"}}}},
+				{Comments: []prview.Comment{{ID: 4, Path: "main.go", Line: intPtr(2), Side: "LEFT", Body: "Keep fmt?", CreatedAt: now, User: prview.User{Login: "bob"}}}},
+			},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := prview.Render(&buf, pr, prview.RenderOptions{Format: "files"}); err != nil {
+		t.Fatalf("Render returned an error: %v", err)
+	}
+	output := buf.String()
+
+	expected := []string{
+		"main.go\n",
+		"  -import \"fmt\"\n    @bob at 2024-03-01 12:00:00:\n      Keep fmt?\n\n  +import \"os\"\n    @bob at 2024-03-01 12:00:00:\n      Why os?\n\n  +import \"fmt\"\n",
+		"  Not on the current diff:\n  main.go:9\n    @bob at 2024-03-01 12:00:00:\n      Old note\n",
+		"util.go\n",
+		"  +func helper() {}\n    @bob at 2024-03-01 12:00:00:\n      Unused?\n",
+	}
+	last := -1
+	for _, e := range expected {
+		i := strings.Index(output, e)
+		if i < 0 {
+			t.Fatalf("Expected output to contain %q, got:\n%s", e, output)
+		}
+		if i < last {
+			t.Errorf("Expected %q later in the output, got:\n%s", e, output)
+		}
+		last = i
+	}
+}
+
+func TestRenderFilesWithoutFiles(t *testing.T) {
+	var buf bytes.Buffer
+	err := prview.Render(&buf, prview.PullRequest{Number: 7}, prview.RenderOptions{Format: "files"})
+	if err == nil || !strings.Contains(err.Error(), "no changed files") {
+		t.Errorf("Expected an error about missing files, got %v", err)
+	}
+}
@@ -0,0 +1,379 @@
+package prview
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FilterFiles returns a copy of the PR keeping only review threads on files
+// matching at least one of the globs. Reviews left without matching threads
+// are dropped unless they have a summary body. Globs use path.Match syntax,
+// with "**" additionally matching any number of directories.
+func FilterFiles(pr PullRequest, globs []string) PullRequest {
+	if len(globs) == 0 {
+		return pr
+	}
+
+	var reviews []Review
+	for _, review := range pr.Reviews {
+		var threads []CommentThread
+		for _, thread := range review.Threads {
+			if len(thread.Comments) > 0 && matchAnyGlob(globs, thread.Comments[0].Path) {
+				threads = append(threads, thread)
+			}
+		}
+		if len(threads) == 0 && review.Body == "" {
+			continue
+		}
+		review.Threads = threads
+		reviews = append(reviews, review)
+	}
+	pr.Reviews = reviews
+
+	return pr
+}
+
+// FilterMyThreads returns a copy of the PR keeping only the conversations
+// login took part in: review threads where they wrote any comment, and issue
+// comments they wrote or that mention them. Reviews are kept when they hold
+// such a thread, or login wrote them or is mentioned in their body, but
+// either way only login's threads remain under them.
+func FilterMyThreads(pr PullRequest, login string) PullRequest {
+	var comments []Comment
+	for _, comment := range pr.Comments {
+		if isOrMentions(comment.User, comment.Body, login) {
+			comments = append(comments, comment)
+		}
+	}
+	pr.Comments = comments
+
+	var reviews []Review
+	for _, review := range pr.Reviews {
+		var threads []CommentThread
+		for _, thread := range review.Threads {
+			if participated(thread, login) {
+				threads = append(threads, thread)
+			}
+		}
+		if len(threads) == 0 && !isOrMentions(review.User, review.Body, login) {
+			continue
+		}
+		review.Threads = threads
+		reviews = append(reviews, review)
+	}
+	pr.Reviews = reviews
+
+	return pr
+}
+
+// FilterAuthors returns a copy of the PR keeping only the issue comments,
+// reviews and review comments written by one of logins, or, with exclude
+// set, only those that weren't. Threads are kept less the comments that are
+// filtered out, and dropped once empty. A review by someone filtered out
+// still holds the threads others replied to, but loses its body.
+func FilterAuthors(pr PullRequest, logins []string, exclude bool) PullRequest {
+	keep := func(user User) bool {
+		return isAnyLogin(user, logins) != exclude
+	}
+
+	var comments []Comment
+	for _, comment := range pr.Comments {
+		if keep(comment.User) {
+			comments = append(comments, comment)
+		}
+	}
+	pr.Comments = comments
+
+	var reviews []Review
+	for _, review := range pr.Reviews {
+		var threads []CommentThread
+		for _, thread := range review.Threads {
+			var kept []Comment
+			for _, comment := range thread.Comments {
+				if keep(comment.User) {
+					kept = append(kept, comment)
+				}
+			}
+			if len(kept) > 0 {
+				thread.Comments = kept
+				threads = append(threads, thread)
+			}
+		}
+		if !keep(review.User) {
+			if len(threads) == 0 {
+				continue
+			}
+			review.Body = ""
+		}
+		review.Threads = threads
+		reviews = append(reviews, review)
+	}
+	pr.Reviews = reviews
+
+	return pr
+}
+
+// FilterOpenConcerns returns a copy of the PR keeping only what may still
+// need attention: resolved threads are dropped, and so are reviews left with
+// nothing to show, such as approvals without a body. Reviews requesting
+// changes are always kept. Threads whose resolution couldn't be loaded
+// count as unresolved.
+func FilterOpenConcerns(pr PullRequest) PullRequest {
+	var reviews []Review
+	for _, review := range pr.Reviews {
+		var threads []CommentThread
+		for _, thread := range review.Threads {
+			if !thread.Resolved {
+				threads = append(threads, thread)
+			}
+		}
+		if len(threads) == 0 && review.Body == "" && review.State != "CHANGES_REQUESTED" {
+			continue
+		}
+		review.Threads = threads
+		reviews = append(reviews, review)
+	}
+	pr.Reviews = reviews
+
+	return pr
+}
+
+// GrepPattern compiles the pattern for FilterGrep. It matches pattern as a
+// case-insensitive substring, or with regex set, as a regular expression.
+func GrepPattern(pattern string, regex bool) (*regexp.Regexp, error) {
+	if !regex {
+		pattern = "(?i)" + regexp.QuoteMeta(pattern)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	return re, nil
+}
+
+// markMatches wraps each match of re in s, as »match«, so that what --grep
+// found stands out without relying on color. A nil re leaves s alone.
+func markMatches(s string, re *regexp.Regexp) string {
+	if re == nil {
+		return s
+	}
+	return re.ReplaceAllStringFunc(s, func(match string) string {
+		if match == "" {
+			return match
+		}
+		return "»" + match + "«"
+	})
+}
+
+// FilterGrep returns a copy of the PR keeping only the timeline items whose
+// text matches re: issue comments by body, commits by message,
+// cross-references by title, and review threads with a matching comment,
+// which are kept whole. Reviews are kept
+// when their body matches or they hold a matching thread, but either way
+// only the matching threads remain under them.
+func FilterGrep(pr PullRequest, re *regexp.Regexp) PullRequest {
+	var comments []Comment
+	for _, comment := range pr.Comments {
+		if re.MatchString(comment.Body) {
+			comments = append(comments, comment)
+		}
+	}
+	pr.Comments = comments
+
+	var reviews []Review
+	for _, review := range pr.Reviews {
+		var threads []CommentThread
+		for _, thread := range review.Threads {
+			if threadMatches(thread, re) {
+				threads = append(threads, thread)
+			}
+		}
+		if len(threads) == 0 && !re.MatchString(review.Body) {
+			continue
+		}
+		review.Threads = threads
+		reviews = append(reviews, review)
+	}
+	pr.Reviews = reviews
+
+	var commits []Commit
+	for _, commit := range pr.Commits {
+		if re.MatchString(commit.Message) || re.MatchString(commit.Body) {
+			commits = append(commits, commit)
+		}
+	}
+	pr.Commits = commits
+
+	var references []CrossReference
+	for _, ref := range pr.References {
+		if re.MatchString(ref.Title) {
+			references = append(references, ref)
+		}
+	}
+	pr.References = references
+
+	return pr
+}
+
+// threadMatches reports whether any comment in the thread matches re
+func threadMatches(thread CommentThread, re *regexp.Regexp) bool {
+	for _, comment := range thread.Comments {
+		if re.MatchString(comment.Body) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAnyLogin reports whether user is one of logins, ignoring case as GitHub
+// does
+func isAnyLogin(user User, logins []string) bool {
+	for _, login := range logins {
+		if strings.EqualFold(user.Login, login) {
+			return true
+		}
+	}
+	return false
+}
+
+// LastReviewBy returns when login last submitted a review of the PR, and
+// false if they never have
+func LastReviewBy(pr PullRequest, login string) (time.Time, bool) {
+	var last time.Time
+	found := false
+	for _, review := range pr.Reviews {
+		if strings.EqualFold(review.User.Login, login) && review.State != pendingState && review.SubmittedAt.After(last) {
+			last = review.SubmittedAt
+			found = true
+		}
+	}
+	return last, found
+}
+
+// FilterSince returns a copy of the PR keeping only the timeline items from
+// after t. Earlier reviews stay when a thread under them has comments from
+// after t, such as replies, keeping just those threads.
+func FilterSince(pr PullRequest, t time.Time) PullRequest {
+	var comments []Comment
+	for _, comment := range pr.Comments {
+		if comment.CreatedAt.After(t) {
+			comments = append(comments, comment)
+		}
+	}
+	pr.Comments = comments
+
+	var reviews []Review
+	for _, review := range pr.Reviews {
+		if review.SubmittedAt.After(t) {
+			reviews = append(reviews, review)
+			continue
+		}
+		var threads []CommentThread
+		for _, thread := range review.Threads {
+			if activeSince(thread, t) {
+				threads = append(threads, thread)
+			}
+		}
+		if len(threads) > 0 {
+			review.Threads = threads
+			reviews = append(reviews, review)
+		}
+	}
+	pr.Reviews = reviews
+
+	var commits []Commit
+	for _, commit := range pr.Commits {
+		if commit.CreatedAt.After(t) {
+			commits = append(commits, commit)
+		}
+	}
+	pr.Commits = commits
+
+	var references []CrossReference
+	for _, ref := range pr.References {
+		if ref.CreatedAt.After(t) {
+			references = append(references, ref)
+		}
+	}
+	pr.References = references
+
+	return pr
+}
+
+// activeSince reports whether any comment in the thread is from after t
+func activeSince(thread CommentThread, t time.Time) bool {
+	for _, comment := range thread.Comments {
+		if comment.CreatedAt.After(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// participated reports whether login wrote any comment in the thread
+func participated(thread CommentThread, login string) bool {
+	for _, comment := range thread.Comments {
+		if strings.EqualFold(comment.User.Login, login) {
+			return true
+		}
+	}
+	return false
+}
+
+// isOrMentions reports whether author is login or body @mentions login
+func isOrMentions(author User, body, login string) bool {
+	if strings.EqualFold(author.Login, login) {
+		return true
+	}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		if strings.EqualFold(match[2], login) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAnyGlob(globs []string, name string) bool {
+	for _, glob := range globs {
+		if matchGlob(strings.Split(glob, "/"), strings.Split(name, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches path segments against pattern segments, where a "**"
+// segment matches zero or more path segments
+func matchGlob(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchGlob(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// FindReview returns the review on the PR with the given ID
+func FindReview(pr PullRequest, id int64) (Review, error) {
+	for _, review := range pr.Reviews {
+		if review.ID == id {
+			return review, nil
+		}
+	}
+	return Review{}, fmt.Errorf("review %d not found on PR #%d", id, pr.Number)
+}
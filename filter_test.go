@@ -0,0 +1,412 @@
+package prview_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestFilterFiles(t *testing.T) {
+	now := time.Now()
+	thread := func(id int64, path string) prview.CommentThread {
+		return prview.CommentThread{Comments: []prview.Comment{
+			{ID: id, Body: "Comment on " + path, CreatedAt: now, Path: path},
+		}}
+	}
+
+	pr := prview.PullRequest{
+		Reviews: []prview.Review{
+			{
+				ID:      101,
+				Threads: []prview.CommentThread{thread(1, "src/main.go"), thread(2, "test/main_test.go")},
+			},
+			{
+				ID:      102,
+				Threads: []prview.CommentThread{thread(3, "src/pkg/deep/util.go")},
+			},
+			{
+				ID:      103,
+				Threads: []prview.CommentThread{thread(4, "test/util_test.go")},
+			},
+			{
+				ID:      104,
+				Body:    "Overall looks good",
+				Threads: []prview.CommentThread{thread(5, "test/other_test.go")},
+			},
+		},
+	}
+
+	filtered := prview.FilterFiles(pr, []string{"src/**"})
+
+	var ids []int64
+	var paths []string
+	for _, review := range filtered.Reviews {
+		ids = append(ids, review.ID)
+		for _, thread := range review.Threads {
+			paths = append(paths, thread.Comments[0].Path)
+		}
+	}
+
+	if len(ids) != 3 || ids[0] != 101 || ids[1] != 102 || ids[2] != 104 {
+		t.Errorf("Expected reviews [101 102 104], got %v", ids)
+	}
+	if len(paths) != 2 || paths[0] != "src/main.go" || paths[1] != "src/pkg/deep/util.go" {
+		t.Errorf("Expected only src/ threads, got %v", paths)
+	}
+	if len(pr.Reviews[0].Threads) != 2 {
+		t.Errorf("Expected the original PR to be left untouched")
+	}
+}
+
+func TestFilterMyThreads(t *testing.T) {
+	thread := func(path string, logins ...string) prview.CommentThread {
+		var comments []prview.Comment
+		for _, login := range logins {
+			comments = append(comments, prview.Comment{Path: path, DiffHunk: "@@ -1 +1 @@", User: prview.User{Login: login}})
+		}
+		return prview.CommentThread{Comments: comments}
+	}
+	pr := prview.PullRequest{
+		Comments: []prview.Comment{
+			{ID: 1, User: prview.User{Login: "alice"}, Body: "Looks risky"},
+			{ID: 2, User: prview.User{Login: "bob"}, Body: "@Me what do you think?"},
+			{ID: 3, User: prview.User{Login: "me"}, Body: "Agreed"},
+			{ID: 4, User: prview.User{Login: "carol"}, Body: "email me@example.com"},
+		},
+		Reviews: []prview.Review{
+			{ID: 10, User: prview.User{Login: "alice"}, Threads: []prview.CommentThread{
+				thread("a.go", "alice", "me"),
+				thread("b.go", "alice", "bob"),
+			}},
+			{ID: 11, User: prview.User{Login: "bob"}, Body: "LGTM", Threads: []prview.CommentThread{
+				thread("c.go", "bob"),
+			}},
+		},
+	}
+
+	filtered := prview.FilterMyThreads(pr, "me")
+
+	if len(filtered.Reviews) != 1 || filtered.Reviews[0].ID != 10 {
+		t.Fatalf("Expected only the review holding my thread, got %+v", filtered.Reviews)
+	}
+	threads := filtered.Reviews[0].Threads
+	if len(threads) != 1 || threads[0].Comments[0].Path != "a.go" {
+		t.Errorf("Expected only the a.go thread, got %+v", threads)
+	}
+	var ids []int64
+	for _, c := range filtered.Comments {
+		ids = append(ids, c.ID)
+	}
+	if len(ids) != 2 || ids[0] != 2 || ids[1] != 3 {
+		t.Errorf("Expected the comments mentioning me and by me, got %v", ids)
+	}
+	if len(pr.Reviews[0].Threads) != 2 {
+		t.Errorf("Expected the original PR to be left untouched")
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, filtered, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "a.go") || strings.Contains(buf.String(), "b.go") {
+		t.Errorf("Expected only the a.go thread to render, got:\n%s", buf.String())
+	}
+}
+
+func TestFilterAuthorsExclude(t *testing.T) {
+	user := func(login string) prview.User { return prview.User{Login: login} }
+	pr := prview.PullRequest{
+		Comments: []prview.Comment{
+			{ID: 1, User: user("alice")},
+			{ID: 2, User: user("Me")},
+			{ID: 3, User: user("bob")},
+		},
+		Reviews: []prview.Review{
+			{ID: 10, User: user("me"), Body: "My review", Threads: []prview.CommentThread{
+				{Comments: []prview.Comment{{ID: 20, User: user("me")}}},
+			}},
+			{ID: 11, User: user("alice"), Threads: []prview.CommentThread{
+				{Comments: []prview.Comment{{ID: 21, User: user("alice")}, {ID: 22, User: user("me")}, {ID: 23, User: user("bob")}}},
+			}},
+			{ID: 12, User: user("me"), Body: "Another of mine", Threads: []prview.CommentThread{
+				{Comments: []prview.Comment{{ID: 24, User: user("me")}, {ID: 25, User: user("bob")}}},
+			}},
+		},
+	}
+
+	filtered := prview.FilterAuthors(pr, []string{"me"}, true)
+
+	var ids []int64
+	for _, c := range filtered.Comments {
+		ids = append(ids, c.ID)
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 3 {
+		t.Errorf("Expected only the comments by others, got %v", ids)
+	}
+	if len(filtered.Reviews) != 2 || filtered.Reviews[0].ID != 11 || filtered.Reviews[1].ID != 12 {
+		t.Fatalf("Expected only my review without replies to be removed, got %+v", filtered.Reviews)
+	}
+	if mine := filtered.Reviews[1]; mine.Body != "" || len(mine.Threads) != 1 || len(mine.Threads[0].Comments) != 1 || mine.Threads[0].Comments[0].ID != 25 {
+		t.Errorf("Expected only bob's reply to remain under my review, got %+v", mine)
+	}
+	ids = nil
+	for _, c := range filtered.Reviews[0].Threads[0].Comments {
+		ids = append(ids, c.ID)
+	}
+	if len(ids) != 2 || ids[0] != 21 || ids[1] != 23 {
+		t.Errorf("Expected my reply to be removed from the thread, got %v", ids)
+	}
+	if len(pr.Comments) != 3 || len(pr.Reviews[1].Threads[0].Comments) != 3 || pr.Reviews[2].Body == "" {
+		t.Errorf("Expected the original PR to be left untouched")
+	}
+}
+
+func TestFilterAuthorsKeep(t *testing.T) {
+	pr := prview.PullRequest{
+		Comments: []prview.Comment{
+			{ID: 1, User: prview.User{Login: "alice"}},
+			{ID: 2, User: prview.User{Login: "bob"}},
+		},
+		Reviews: []prview.Review{
+			{ID: 10, User: prview.User{Login: "alice"}},
+			{ID: 11, User: prview.User{Login: "bob"}},
+		},
+	}
+
+	filtered := prview.FilterAuthors(pr, []string{"bob"}, false)
+	if len(filtered.Comments) != 1 || filtered.Comments[0].ID != 2 || len(filtered.Reviews) != 1 || filtered.Reviews[0].ID != 11 {
+		t.Errorf("Expected only bob's comment and review, got %+v", filtered)
+	}
+}
+
+func TestFilterGrep(t *testing.T) {
+	now := time.Now()
+	user := prview.User{Login: "alice"}
+	pr := prview.PullRequest{
+		Number: 1,
+		Title:  "Audit",
+		Comments: []prview.Comment{
+			{ID: 1, Body: "Looks fine to me", CreatedAt: now, User: user},
+			{ID: 2, Body: "Is this a Security risk?", CreatedAt: now.Add(time.Minute), User: user},
+		},
+		Reviews: []prview.Review{
+			{ID: 10, State: "COMMENTED", SubmittedAt: now.Add(2 * time.Minute), User: user, Threads: []prview.CommentThread{
+				{Comments: []prview.Comment{{ID: 20, Body: "Rename this", Path: "a.go", User: user}}},
+				{Comments: []prview.Comment{{ID: 21, Body: "Escape the input", Path: "b.go", User: user}, {ID: 22, Body: "security fix pushed", Path: "b.go", User: user}}},
+			}},
+			{ID: 11, State: "APPROVED", Body: "LGTM", SubmittedAt: now.Add(3 * time.Minute), User: user},
+		},
+	}
+
+	re, err := prview.GrepPattern("security", false)
+	if err != nil {
+		t.Fatalf("GrepPattern returned an error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, prview.FilterGrep(pr, re), prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output := buf.String()
+
+	for _, expected := range []string{"Is this a Security risk?", "Escape the input", "security fix pushed"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in the output, got:\n%s", expected, output)
+		}
+	}
+	for _, unexpected := range []string{"Looks fine to me", "Rename this", "LGTM"} {
+		if strings.Contains(output, unexpected) {
+			t.Errorf("Didn't expect %q in the output, got:\n%s", unexpected, output)
+		}
+	}
+
+	buf.Reset()
+	if err := prview.RenderPR(&buf, prview.FilterGrep(pr, re), prview.RenderOptions{Highlight: re}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output = buf.String()
+	for _, expected := range []string{"Is this a »Security« risk?", "Escape the input\n", "»security« fix pushed"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in the output, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestGrepPattern(t *testing.T) {
+	re, err := prview.GrepPattern("a.b", false)
+	if err != nil {
+		t.Fatalf("GrepPattern returned an error: %v", err)
+	}
+	if re.MatchString("axb") || !re.MatchString("see A.B") {
+		t.Errorf("Expected a case-insensitive literal match, got %v", re)
+	}
+
+	re, err = prview.GrepPattern(`CVE-\d+`, true)
+	if err != nil {
+		t.Fatalf("GrepPattern returned an error: %v", err)
+	}
+	if !re.MatchString("fixes CVE-2024") || re.MatchString("cve-2024") {
+		t.Errorf("Expected a case-sensitive regex match, got %v", re)
+	}
+
+	if _, err := prview.GrepPattern("(", true); err == nil {
+		t.Errorf("Expected an error for an invalid regex")
+	}
+}
+
+func TestFilterOpenConcerns(t *testing.T) {
+	now := time.Now()
+	alice, bob := prview.User{Login: "alice"}, prview.User{Login: "bob"}
+	pr := prview.PullRequest{
+		Number: 1,
+		Title:  "Sign-off",
+		Reviews: []prview.Review{
+			{ID: 10, State: "COMMENTED", SubmittedAt: now, User: alice, Threads: []prview.CommentThread{
+				{Resolved: true, Comments: []prview.Comment{{ID: 20, Body: "Typo here", Path: "a.go", User: alice}}},
+				{Comments: []prview.Comment{{ID: 21, Body: "This leaks the file", Path: "b.go", User: alice}}},
+			}},
+			{ID: 11, State: "COMMENTED", SubmittedAt: now.Add(time.Minute), User: bob, Threads: []prview.CommentThread{
+				{Resolved: true, Comments: []prview.Comment{{ID: 22, Body: "Rename this", Path: "c.go", User: bob}}},
+			}},
+			{ID: 12, State: "CHANGES_REQUESTED", SubmittedAt: now.Add(2 * time.Minute), User: bob},
+			{ID: 13, State: "APPROVED", SubmittedAt: now.Add(3 * time.Minute), User: alice},
+		},
+	}
+
+	got := prview.FilterOpenConcerns(pr)
+	var ids []int64
+	for _, review := range got.Reviews {
+		ids = append(ids, review.ID)
+	}
+	if !reflect.DeepEqual(ids, []int64{10, 12}) {
+		t.Fatalf("Expected reviews 10 and 12 to remain, got %v", ids)
+	}
+	if threads := got.Reviews[0].Threads; len(threads) != 1 || threads[0].Comments[0].ID != 21 {
+		t.Errorf("Expected only the unresolved thread to remain, got %+v", threads)
+	}
+}
+
+func TestFilterSinceMyLastReview(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return start.Add(time.Duration(hours) * time.Hour) }
+	pr := prview.PullRequest{
+		Number: 1,
+		Comments: []prview.Comment{
+			{ID: 1, Body: "Old comment", CreatedAt: at(0), User: prview.User{Login: "alice"}},
+			{ID: 2, Body: "New comment", CreatedAt: at(3), User: prview.User{Login: "alice"}},
+		},
+		Reviews: []prview.Review{
+			{ID: 10, Body: "Old review", State: "COMMENTED", SubmittedAt: at(1), User: prview.User{Login: "bob"}, Threads: []prview.CommentThread{
+				{Comments: []prview.Comment{
+					{ID: 20, Body: "Question", Path: "a.go", DiffHunk: "@@ -1 +1 @@", CreatedAt: at(1), User: prview.User{Login: "bob"}},
+					{ID: 21, Body: "Late reply", CreatedAt: at(4), User: prview.User{Login: "alice"}},
+				}},
+				{Comments: []prview.Comment{
+					{ID: 22, Body: "Settled", Path: "b.go", DiffHunk: "@@ -1 +1 @@", CreatedAt: at(1), User: prview.User{Login: "bob"}},
+				}},
+			}},
+			{ID: 11, Body: "My review", State: "CHANGES_REQUESTED", SubmittedAt: at(2), User: prview.User{Login: "me"}},
+			{ID: 12, Body: "Fixed it", State: "COMMENTED", SubmittedAt: at(5), User: prview.User{Login: "alice"}},
+		},
+		Commits: []prview.Commit{
+			{SHA: "aaaaaaa", Message: "Old commit", CreatedAt: at(0)},
+			{SHA: "bbbbbbb", Message: "New commit", CreatedAt: at(4)},
+		},
+	}
+
+	last, ok := prview.LastReviewBy(pr, "me")
+	if !ok || !last.Equal(at(2)) {
+		t.Fatalf("Expected my last review at %v, got %v, %v", at(2), last, ok)
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, prview.FilterSince(pr, last), prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output := buf.String()
+	for _, expected := range []string{"New comment", "Fixed it", "New commit", "Late reply"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q, got:\n%s", expected, output)
+		}
+	}
+	for _, unexpected := range []string{"Old comment", "My review", "Old commit", "Settled"} {
+		if strings.Contains(output, unexpected) {
+			t.Errorf("Didn't expect %q, got:\n%s", unexpected, output)
+		}
+	}
+
+	if _, ok := prview.LastReviewBy(pr, "carol"); ok {
+		t.Errorf("Expected carol never to have reviewed")
+	}
+}
+
+func TestFilterSinceMyLastReviewOpenConcerns(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return start.Add(time.Duration(hours) * time.Hour) }
+	me := prview.User{Login: "me"}
+	pr := prview.PullRequest{
+		Number: 1,
+		Comments: []prview.Comment{
+			{ID: 1, Body: "Addressed your points", CreatedAt: at(2), User: prview.User{Login: "alice"}},
+			{ID: 2, Body: "One more thing", CreatedAt: at(4), User: prview.User{Login: "alice"}},
+		},
+		Reviews: []prview.Review{
+			{ID: 10, Body: "Please fix", State: "CHANGES_REQUESTED", SubmittedAt: at(1), User: me},
+			{ID: 11, State: "APPROVED", SubmittedAt: at(3), User: me},
+		},
+		References: []prview.CrossReference{
+			{CreatedAt: at(0), Number: 41, Repo: "other/old"},
+			{CreatedAt: at(5), Number: 42, Repo: "other/new"},
+		},
+	}
+
+	// The anchor comes from the PR as loaded: --open-concerns drops the
+	// bare approval, which would otherwise move it back to the older review
+	last, ok := prview.LastReviewBy(pr, "me")
+	if !ok || !last.Equal(at(3)) {
+		t.Fatalf("Expected my last review at %v, got %v, %v", at(3), last, ok)
+	}
+	filtered := prview.FilterSince(prview.FilterOpenConcerns(pr), last)
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, filtered, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output := buf.String()
+	for _, expected := range []string{"One more thing", "referenced by #42 in other/new"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q, got:\n%s", expected, output)
+		}
+	}
+	for _, unexpected := range []string{"Addressed your points", "Please fix", "#41"} {
+		if strings.Contains(output, unexpected) {
+			t.Errorf("Didn't expect %q, got:\n%s", unexpected, output)
+		}
+	}
+}
+
+func TestFindReview(t *testing.T) {
+	pr := prview.PullRequest{
+		Number: 123,
+		Reviews: []prview.Review{
+			{ID: 101, Body: "First review"},
+			{ID: 102, Body: "Second review"},
+		},
+	}
+
+	review, err := prview.FindReview(pr, 102)
+	if err != nil {
+		t.Fatalf("FindReview returned an error: %v", err)
+	}
+	if review.Body != "Second review" {
+		t.Errorf("Expected the second review, got %+v", review)
+	}
+
+	_, err = prview.FindReview(pr, 999)
+	if err == nil || err.Error() != "review 999 not found on PR #123" {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package prview
+
+// defaultGlyphs marks each review state in text output
+var defaultGlyphs = map[string]string{
+	"APPROVED":          "✓",
+	"CHANGES_REQUESTED": "✗",
+	"COMMENTED":         "💬",
+}
+
+// asciiGlyphs stand in for defaultGlyphs with RenderOptions.ASCII, for
+// terminals and fonts without them
+var asciiGlyphs = map[string]string{
+	"APPROVED":          "[+]",
+	"CHANGES_REQUESTED": "[-]",
+	"COMMENTED":         "[~]",
+}
+
+// glyph returns the symbol for a review state: the ASCII one with
+// opts.ASCII, otherwise any override from opts.Glyphs or the default. States
+// without one, like DISMISSED, get "".
+func (opts RenderOptions) glyph(state string) string {
+	if opts.ASCII {
+		return asciiGlyphs[state]
+	}
+	if g, ok := opts.Glyphs[state]; ok {
+		return g
+	}
+	return defaultGlyphs[state]
+}
+
+// withGlyph prefixes s with the glyph for state, if it has one
+func (opts RenderOptions) withGlyph(state, s string) string {
+	if g := opts.glyph(state); g != "" {
+		return g + " " + s
+	}
+	return s
+}
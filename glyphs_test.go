@@ -0,0 +1,38 @@
+package prview_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestRenderReviewGlyphs(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     prview.RenderOptions
+		expected string
+	}{
+		{name: "default", opts: prview.RenderOptions{}, expected: "✓ reviewer1 APPROVED at"},
+		{name: "ascii", opts: prview.RenderOptions{ASCII: true}, expected: "[+] reviewer1 APPROVED at"},
+		{name: "configured", opts: prview.RenderOptions{Glyphs: map[string]string{"APPROVED": "👍"}}, expected: "👍 reviewer1 APPROVED at"},
+		{
+			name:     "ascii over configured",
+			opts:     prview.RenderOptions{ASCII: true, Glyphs: map[string]string{"APPROVED": "👍"}},
+			expected: "[+] reviewer1 APPROVED at",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := prview.RenderPR(&buf, createMockPR(), tt.opts); err != nil {
+				t.Fatalf("RenderPR returned an error: %v", err)
+			}
+			if !strings.Contains(buf.String(), "\n"+tt.expected) {
+				t.Errorf("Expected %q in:\n%s", tt.expected, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package prview_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata with the current output")
+
+// goldenTime is when the latest comment in the golden PR was made
+var goldenTime = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// assertGolden compares got byte for byte with testdata/name, or rewrites
+// the file when the tests are run with -update
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("Failed to update %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s, run the tests with -update to create it: %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Output doesn't match %s, run the tests with -update if the change is intended.\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestRenderGolden(t *testing.T) {
+	prview.SetClock(t, goldenTime.Add(48*time.Hour))
+
+	var buf bytes.Buffer
+	if err := prview.Render(&buf, createMockPRAt(goldenTime), prview.RenderOptions{}); err != nil {
+		t.Fatalf("Render returned an error: %v", err)
+	}
+	assertGolden(t, "pr.golden", buf.Bytes())
+}
@@ -0,0 +1,224 @@
+package prview
+
+import (
+	"context"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/repository"
+)
+
+// graphQLPageSize is the most nodes GitHub returns for a single connection
+const graphQLPageSize = 100
+
+// reviewCommentFields selects what a Comment needs from a review comment
+const reviewCommentFields = `comments(first: 100, after: $commentCursor) {
+  nodes {
+    databaseId
+    body
+    createdAt
+    author { login }
+    diffHunk
+    path
+    line
+    originalLine
+    commit { oid }
+    originalCommit { oid }
+    replyTo { databaseId }
+    reactions { totalCount }
+  }
+  pageInfo { hasNextPage endCursor }
+}`
+
+// reviewsWithCommentsQuery lists a PR's reviews together with the first
+// page of each review's comments
+const reviewsWithCommentsQuery = `query($owner: String!, $name: String!, $number: Int!, $first: Int!, $cursor: String, $commentCursor: String) {
+  repository(owner: $owner, name: $name) {
+    pullRequest(number: $number) {
+      reviews(first: $first, after: $cursor) {
+        nodes {
+          id
+          databaseId
+          body
+          state
+          submittedAt
+          author { login }
+          ` + reviewCommentFields + `
+        }
+        pageInfo { hasNextPage endCursor }
+      }
+    }
+  }
+}`
+
+// reviewCommentsQuery continues the comments of a single review, found by
+// its node ID, once they overflow the first page
+const reviewCommentsQuery = `query($id: ID!, $commentCursor: String) {
+  node(id: $id) {
+    ... on PullRequestReview {
+      ` + reviewCommentFields + `
+    }
+  }
+}`
+
+type graphQLPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type graphQLCommitRef struct {
+	OID string `json:"oid"`
+}
+
+type graphQLReviewComments struct {
+	Nodes []struct {
+		DatabaseID     int64            `json:"databaseId"`
+		Body           string           `json:"body"`
+		CreatedAt      time.Time        `json:"createdAt"`
+		Author         User             `json:"author"`
+		DiffHunk       string           `json:"diffHunk"`
+		Path           string           `json:"path"`
+		Line           *int             `json:"line"`
+		OriginalLine   *int             `json:"originalLine"`
+		Commit         graphQLCommitRef `json:"commit"`
+		OriginalCommit graphQLCommitRef `json:"originalCommit"`
+		ReplyTo        *struct {
+			DatabaseID int64 `json:"databaseId"`
+		} `json:"replyTo"`
+		Reactions struct {
+			TotalCount int `json:"totalCount"`
+		} `json:"reactions"`
+	} `json:"nodes"`
+	PageInfo graphQLPageInfo `json:"pageInfo"`
+}
+
+type reviewsWithCommentsResponse struct {
+	Repository struct {
+		PullRequest struct {
+			Reviews struct {
+				Nodes []struct {
+					ID          string                `json:"id"`
+					DatabaseID  int64                 `json:"databaseId"`
+					Body        string                `json:"body"`
+					State       string                `json:"state"`
+					SubmittedAt time.Time             `json:"submittedAt"`
+					Author      User                  `json:"author"`
+					Comments    graphQLReviewComments `json:"comments"`
+				} `json:"nodes"`
+				PageInfo graphQLPageInfo `json:"pageInfo"`
+			} `json:"reviews"`
+		} `json:"pullRequest"`
+	} `json:"repository"`
+}
+
+type reviewCommentsResponse struct {
+	Node struct {
+		Comments graphQLReviewComments `json:"comments"`
+	} `json:"node"`
+}
+
+// comments converts a page of review comments for the review with the given
+// database ID into the REST shape the rest of the package works with
+func (page graphQLReviewComments) comments(reviewID int64) []Comment {
+	comments := make([]Comment, 0, len(page.Nodes))
+	for _, node := range page.Nodes {
+		c := Comment{
+			ID:                  node.DatabaseID,
+			Body:                node.Body,
+			CreatedAt:           node.CreatedAt,
+			User:                node.Author,
+			DiffHunk:            node.DiffHunk,
+			Path:                node.Path,
+			CommitID:            node.Commit.OID,
+			OriginalCommitID:    node.OriginalCommit.OID,
+			Line:                node.Line,
+			OriginalLine:        node.OriginalLine,
+			PullRequestReviewID: reviewID,
+			Reactions:           Reactions{TotalCount: node.Reactions.TotalCount},
+		}
+		if node.ReplyTo != nil {
+			id := node.ReplyTo.DatabaseID
+			c.InReplyToID = &id
+		}
+		comments = append(comments, c)
+	}
+	return comments
+}
+
+// FetchReviewsWithComments retrieves a PR's reviews and all of their review
+// comments through GraphQL. A single query covers up to perPage reviews, as
+// pageSize clamps it, with 100 comments each; only reviews beyond that, or
+// with more comments, take further queries. Like FetchPRReviews it stops
+// after the first limit reviews when limit is positive. The comments are
+// returned separately, as FetchAllReviewComments would, ready for
+// attachThreads.
+func FetchReviewsWithComments(ctx context.Context, client *api.GraphQLClient, repo repository.Repository, prNumber int, limit int, perPage int) ([]Review, []Comment, error) {
+	var reviews []Review
+	var comments []Comment
+	variables := map[string]interface{}{
+		"owner":         repo.Owner,
+		"name":          repo.Name,
+		"number":        prNumber,
+		"cursor":        nil,
+		"commentCursor": nil,
+	}
+
+	for {
+		first := pageSize(perPage, graphQLPageSize)
+		if limit > 0 && limit-len(reviews) < first {
+			first = limit - len(reviews)
+		}
+		variables["first"] = first
+
+		var response reviewsWithCommentsResponse
+		if err := client.DoWithContext(ctx, reviewsWithCommentsQuery, variables, &response); err != nil {
+			return nil, nil, tagAPIError(err)
+		}
+
+		page := response.Repository.PullRequest.Reviews
+		for _, node := range page.Nodes {
+			reviews = append(reviews, Review{
+				ID:          node.DatabaseID,
+				Body:        node.Body,
+				State:       node.State,
+				SubmittedAt: node.SubmittedAt,
+				User:        node.Author,
+			})
+			comments = append(comments, node.Comments.comments(node.DatabaseID)...)
+
+			if node.Comments.PageInfo.HasNextPage {
+				more, err := fetchMoreReviewComments(ctx, client, node.ID, node.DatabaseID, node.Comments.PageInfo.EndCursor)
+				if err != nil {
+					return nil, nil, err
+				}
+				comments = append(comments, more...)
+			}
+		}
+
+		if !page.PageInfo.HasNextPage || (limit > 0 && len(reviews) >= limit) {
+			return reviews, comments, nil
+		}
+		variables["cursor"] = page.PageInfo.EndCursor
+	}
+}
+
+// fetchMoreReviewComments pages through the rest of one review's comments,
+// starting after cursor
+func fetchMoreReviewComments(ctx context.Context, client *api.GraphQLClient, nodeID string, reviewID int64, cursor string) ([]Comment, error) {
+	var comments []Comment
+	variables := map[string]interface{}{"id": nodeID, "commentCursor": cursor}
+
+	for {
+		var response reviewCommentsResponse
+		if err := client.DoWithContext(ctx, reviewCommentsQuery, variables, &response); err != nil {
+			return nil, tagAPIError(err)
+		}
+
+		page := response.Node.Comments
+		comments = append(comments, page.comments(reviewID)...)
+		if !page.PageInfo.HasNextPage {
+			return comments, nil
+		}
+		variables["commentCursor"] = page.PageInfo.EndCursor
+	}
+}
@@ -0,0 +1,147 @@
+package prview_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestFetchReviewsWithComments(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{
+		"/graphql": `{"data": {"repository": {"pullRequest": {"reviews": {
+			"nodes": [
+				{"id": "R_1", "databaseId": 10, "body": "Some thoughts", "state": "COMMENTED", "submittedAt": "2024-01-01T10:00:00Z", "author": {"login": "alice"},
+				 "comments": {"nodes": [
+					{"databaseId": 1, "body": "Why?", "path": "a.go", "line": 4, "diffHunk": "@@ -1 +1 @@", "commit": {"oid": "abc"}, "author": {"login": "alice"}, "reactions": {"totalCount": 2}},
+					{"databaseId": 2, "body": "Also here", "path": "b.go", "author": {"login": "alice"}, "reactions": {"totalCount": 0}}
+				 ], "pageInfo": {"hasNextPage": false}}},
+				{"id": "R_2", "databaseId": 20, "body": "", "state": "COMMENTED", "submittedAt": "2024-01-01T11:00:00Z", "author": {"login": "bob"},
+				 "comments": {"nodes": [
+					{"databaseId": 3, "body": "Because", "path": "a.go", "author": {"login": "bob"}, "replyTo": {"databaseId": 1}, "reactions": {"totalCount": 0}}
+				 ], "pageInfo": {"hasNextPage": false}}}
+			],
+			"pageInfo": {"hasNextPage": false}
+		}}}}}`,
+	}}
+	client, err := prview.NewGraphQLClient(rt, 0, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	reviews, comments, err := prview.FetchReviewsWithComments(context.Background(), client, testRepo, 7, 0, 0)
+	if err != nil {
+		t.Fatalf("FetchReviewsWithComments returned an error: %v", err)
+	}
+	if len(rt.requests) != 1 {
+		t.Errorf("Expected a single query, got %d requests", len(rt.requests))
+	}
+	if len(reviews) != 2 || reviews[0].ID != 10 || reviews[0].User.Login != "alice" || reviews[1].ID != 20 {
+		t.Fatalf("Unexpected reviews: %+v", reviews)
+	}
+	if len(comments) != 3 {
+		t.Fatalf("Expected 3 comments, got %+v", comments)
+	}
+	first := comments[0]
+	if first.ID != 1 || first.PullRequestReviewID != 10 || first.Line == nil || *first.Line != 4 || first.CommitID != "abc" || first.Reactions.TotalCount != 2 {
+		t.Errorf("Unexpected first comment: %+v", first)
+	}
+	reply := comments[2]
+	if reply.PullRequestReviewID != 20 || reply.InReplyToID == nil || *reply.InReplyToID != 1 {
+		t.Errorf("Expected the reply to belong to review 20 and answer comment 1, got %+v", reply)
+	}
+}
+
+func TestFetchReviewsWithCommentsNextPage(t *testing.T) {
+	setTestAuth(t)
+	var queries []string
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		queries = append(queries, string(body))
+		resp := `{"data": {"repository": {"pullRequest": {"reviews": {
+			"nodes": [{"id": "R_1", "databaseId": 10, "state": "COMMENTED", "author": {"login": "alice"},
+				"comments": {"nodes": [{"databaseId": 1, "body": "One"}], "pageInfo": {"hasNextPage": true, "endCursor": "c1"}}}],
+			"pageInfo": {"hasNextPage": false}
+		}}}}}`
+		if strings.Contains(string(body), "node(id: $id)") {
+			resp = `{"data": {"node": {"comments": {"nodes": [{"databaseId": 2, "body": "Two"}], "pageInfo": {"hasNextPage": false}}}}}`
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(resp)),
+			Request:    req,
+		}, nil
+	})
+	client, err := prview.NewGraphQLClient(rt, 0, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	_, comments, err := prview.FetchReviewsWithComments(context.Background(), client, testRepo, 7, 0, 0)
+	if err != nil {
+		t.Fatalf("FetchReviewsWithComments returned an error: %v", err)
+	}
+	if len(queries) != 2 || !strings.Contains(queries[1], `"commentCursor":"c1"`) || !strings.Contains(queries[1], `"id":"R_1"`) {
+		t.Errorf("Expected a follow-up node query for the review's next page, got %q", queries)
+	}
+	if len(comments) != 2 || comments[1].ID != 2 || comments[1].PullRequestReviewID != 10 {
+		t.Errorf("Expected both pages of comments for review 10, got %+v", comments)
+	}
+}
+
+func TestLoadPRGraphQL(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7":           `{"number": 7, "title": "Loaded PR", "user": {"login": "author"}}`,
+		"/repos/owner/repo/issues/7/comments": `[]`,
+		"/repos/owner/repo/pulls/7/commits":   `[]`,
+		"/graphql": `{"data": {"repository": {"pullRequest": {"reviews": {
+			"nodes": [{"id": "R_1", "databaseId": 10, "state": "APPROVED", "author": {"login": "alice"},
+				"comments": {"nodes": [{"databaseId": 1, "body": "Nice", "path": "a.go"}], "pageInfo": {"hasNextPage": false}}}],
+			"pageInfo": {"hasNextPage": false}
+		}}}}}`,
+	}}
+
+	pr, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt, GraphQL: true})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+	for _, req := range rt.requests {
+		if strings.Contains(req.URL.Path, "/reviews") || strings.HasSuffix(req.URL.Path, "/pulls/7/comments") {
+			t.Errorf("Expected no REST requests for reviews, got %s", req.URL.Path)
+		}
+	}
+	if len(pr.Reviews) != 1 || len(pr.Reviews[0].Threads) != 1 || pr.Reviews[0].Threads[0].Comments[0].Body != "Nice" {
+		t.Errorf("Expected the review's comment threaded under it, got %+v", pr.Reviews)
+	}
+}
+
+func TestFetchResolvedThreads(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{
+		"/graphql": `{"data": {"repository": {"pullRequest": {"reviewDecision": "APPROVED", "reviewThreads": {
+			"nodes": [
+				{"isResolved": true, "comments": {"nodes": [{"databaseId": 1}]}},
+				{"isResolved": false, "comments": {"nodes": [{"databaseId": 2}]}}
+			],
+			"pageInfo": {"hasNextPage": false}
+		}}}}}`,
+	}}
+	client, err := prview.NewGraphQLClient(rt, 0, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	resolved, err := prview.FetchResolvedThreads(context.Background(), client, testRepo, 7, 0)
+	if err != nil {
+		t.Fatalf("FetchResolvedThreads returned an error: %v", err)
+	}
+	if len(resolved) != 2 || !resolved[1] || resolved[2] {
+		t.Errorf("Expected thread 1 resolved and thread 2 not, got %v", resolved)
+	}
+}
@@ -0,0 +1,85 @@
+package prview
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// authorSection gathers one person's comments and reviews
+type authorSection struct {
+	Login    string
+	Items    []TimelineItem
+	Comments int
+	Reviews  int
+}
+
+// groupByAuthor splits the comments and reviews in a timeline into a
+// section per author, ordered by how many items each wrote with ties going
+// to whoever appeared first. Review comments count separately from the
+// review they were posted in; commits are left out.
+func groupByAuthor(timeline []TimelineItem) []authorSection {
+	var sections []*authorSection
+	byLogin := make(map[string]*authorSection)
+
+	for _, item := range flattenTimeline(timeline) {
+		login := itemAuthor(item)
+		if login == "" {
+			continue
+		}
+
+		section, ok := byLogin[login]
+		if !ok {
+			section = &authorSection{Login: login}
+			byLogin[login] = section
+			sections = append(sections, section)
+		}
+		section.Items = append(section.Items, item)
+		if item.Type == "review" {
+			section.Reviews++
+		} else {
+			section.Comments++
+		}
+	}
+
+	sort.SliceStable(sections, func(i, j int) bool {
+		return len(sections[i].Items) > len(sections[j].Items)
+	})
+
+	grouped := make([]authorSection, len(sections))
+	for i, section := range sections {
+		grouped[i] = *section
+	}
+	return grouped
+}
+
+func renderByAuthor(w io.Writer, timeline []TimelineItem, opts RenderOptions) {
+	for _, section := range groupByAuthor(timeline) {
+		fmt.Fprintf(w, "%s: %s\n", sanitizeForTerminal(section.Login), activitySummary(section))
+		fmt.Fprintln(w, opts.rule("="))
+		for _, item := range section.Items {
+			renderTimelineItem(w, item, opts)
+			fmt.Fprintln(w, opts.rule("-"))
+		}
+	}
+}
+
+// activitySummary describes a section's contents, e.g. "3 comments, 1 review"
+func activitySummary(section authorSection) string {
+	var parts []string
+	if section.Comments > 0 {
+		parts = append(parts, pluralize(section.Comments, "comment"))
+	}
+	if section.Reviews > 0 {
+		parts = append(parts, pluralize(section.Reviews, "review"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
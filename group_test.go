@@ -0,0 +1,56 @@
+package prview_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestRenderGroupByAuthor(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	pr := prview.PullRequest{
+		Number: 1,
+		Title:  "Grouped",
+		User:   prview.User{Login: "author"},
+		Comments: []prview.Comment{
+			{ID: 1, Body: "Alice first", CreatedAt: base, User: prview.User{Login: "alice"}},
+			{ID: 2, Body: "Bob first", CreatedAt: base.Add(time.Minute), User: prview.User{Login: "bob"}},
+			{ID: 3, Body: "Bob second", CreatedAt: base.Add(2 * time.Minute), User: prview.User{Login: "bob"}},
+		},
+		Reviews: []prview.Review{
+			{ID: 10, State: "APPROVED", Body: "Bob approves", SubmittedAt: base.Add(3 * time.Minute), User: prview.User{Login: "bob"}},
+		},
+		Commits: []prview.Commit{
+			{SHA: "abc1234", Message: "Not an author section", CreatedAt: base, Author: prview.User{Login: "author"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{GroupByAuthor: true}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output := buf.String()
+
+	bobSection := strings.Index(output, "bob: 2 comments, 1 review\n")
+	aliceSection := strings.Index(output, "alice: 1 comment\n")
+	if bobSection == -1 || aliceSection == -1 {
+		t.Fatalf("Expected a section per author, got:\n%s", output)
+	}
+	if bobSection > aliceSection {
+		t.Errorf("Expected the more active author first, got:\n%s", output)
+	}
+
+	bobItems := []int{strings.Index(output, "Bob first"), strings.Index(output, "Bob second"), strings.Index(output, "Bob approves")}
+	for i, pos := range bobItems {
+		if pos < bobSection || pos > aliceSection || (i > 0 && pos < bobItems[i-1]) {
+			t.Errorf("Expected bob's items in time order within their section, got:\n%s", output)
+			break
+		}
+	}
+	if strings.Contains(output, "Not an author section") {
+		t.Errorf("Expected commits to be left out, got:\n%s", output)
+	}
+}
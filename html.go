@@ -0,0 +1,189 @@
+package prview
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// htmlLongDiffLines is the length beyond which a diff hunk is collapsed in
+// HTML output
+const htmlLongDiffLines = 20
+
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>PR #{{ .PR.Number }}: {{ .PR.Title }}</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; padding: 0 1em; }
+pre { white-space: pre-wrap; }
+.item { border-top: 1px solid #ccc; padding: 0.5em 0; }
+.thread { margin: 0.5em 0 0.5em 2em; }
+.diff { background: #f6f8fa; padding: 0.5em; }
+.meta { color: #666; }
+</style>
+</head>
+<body>
+<h1>PR #{{ .PR.Number }}: {{ .PR.Title }}</h1>
+<p class="meta">Author: {{ .PR.User.Login }}<br>Created: {{ timestamp .PR.CreatedAt }}</p>
+<pre class="body">{{ body .PR.Body }}</pre>
+{{- range .Items }}
+<div class="item">
+{{- if .Comment }}
+<p class="meta">{{ .Comment.User.Login }} commented at {{ timestamp .Comment.CreatedAt }}</p>
+<pre>{{ body .Comment.Body }}</pre>
+{{- else if .Review }}
+<p class="meta">{{ .Review.User.Login }} {{ .Review.State }} at {{ timestamp .Review.SubmittedAt }}</p>
+{{- with .Review.Body }}
+<pre>{{ body . }}</pre>
+{{- end }}
+{{- range .Threads }}
+{{- if .Resolved }}
+<details class="thread resolved"{{ if $.ExpandAll }} open{{ end }}>
+<summary>Resolved thread on {{ .Path }} ({{ .Summary }})</summary>
+{{ template "thread" . }}
+</details>
+{{- else }}
+<div class="thread">
+{{ template "thread" . }}
+</div>
+{{- end }}
+{{- end }}
+{{- else if .Commit }}
+<p class="meta">{{ .Commit.Author.Login }} committed <code>{{ .ShortSHA }}</code>: {{ .Commit.Message }}</p>
+{{- else if .Reference }}
+<p class="meta">referenced by #{{ .Reference.Number }} in {{ .Reference.Repo }} at {{ timestamp .Reference.CreatedAt }}{{ with .Reference.Title }}: {{ . }}{{ end }}</p>
+{{- end }}
+</div>
+{{- end }}
+</body>
+</html>
+{{ define "thread" -}}
+{{- if .Diff }}
+<p class="meta">{{ .Path }}{{ if .Outdated }} [outdated]{{ end }}</p>
+{{- if .LongDiff }}
+<details{{ if .ExpandAll }} open{{ end }}>
+<summary>Diff ({{ .DiffLines }} lines)</summary>
+<pre class="diff">{{ .Diff }}</pre>
+</details>
+{{- else }}
+<pre class="diff">{{ .Diff }}</pre>
+{{- end }}
+{{- end }}
+{{- range .Comments }}
+<p class="meta">@{{ .User.Login }} at {{ timestamp .CreatedAt }}</p>
+<pre>{{ body .Body }}</pre>
+{{- end }}
+{{- end }}
+`
+
+// htmlTmpl is parsed once and only ever cloned and executed afterwards,
+// which templates allow from several goroutines at once
+var htmlTmpl = template.Must(template.New("pr-html").Funcs(template.FuncMap{
+	"timestamp": func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+	"body":      func(body string) template.HTML { return htmlBody(body, "") },
+}).Parse(htmlTemplate))
+
+// htmlBody escapes a body for the page, except for its embedded images,
+// which become <img> tags, and with commitURL set, commit SHAs, which link
+// to commitURL followed by the SHA
+func htmlBody(body, commitURL string) template.HTML {
+	text := template.HTMLEscapeString
+	if commitURL != "" {
+		text = func(s string) string {
+			return linkCommitSHAs(s, template.HTMLEscapeString, func(sha string) string {
+				return fmt.Sprintf(`<a href="%s">%s</a>`, template.HTMLEscapeString(commitURL+sha), sha)
+			})
+		}
+	}
+	return template.HTML(mapImages(body, text, func(alt, url string) string {
+		return fmt.Sprintf(`<img src="%s" alt="%s">`, template.HTMLEscapeString(url), template.HTMLEscapeString(alt))
+	}))
+}
+
+// htmlItem is a timeline item prepared for the HTML template
+type htmlItem struct {
+	Comment   *Comment
+	Review    *Review
+	Threads   []htmlThread
+	Commit    *Commit
+	ShortSHA  string
+	Reference *CrossReference
+}
+
+// htmlThread is a review thread prepared for the HTML template
+type htmlThread struct {
+	Path      string
+	Outdated  bool
+	Resolved  bool
+	Summary   string
+	Diff      string
+	DiffLines int
+	LongDiff  bool
+	ExpandAll bool
+	Comments  []Comment
+}
+
+// RenderHTML writes the PR as a standalone HTML page. Resolved threads and
+// long diffs are collapsed behind <details> elements unless opts.ExpandAll
+// is set.
+func RenderHTML(w io.Writer, pr PullRequest, opts RenderOptions) error {
+	data := struct {
+		PR        PullRequest
+		Items     []htmlItem
+		ExpandAll bool
+	}{PR: pr, ExpandAll: opts.ExpandAll}
+
+	for _, item := range buildTimeline(pr) {
+		switch item.Type {
+		case "comment":
+			data.Items = append(data.Items, htmlItem{Comment: item.Comment})
+		case "review":
+			h := htmlItem{Review: item.Review}
+			for _, thread := range item.Review.Threads {
+				h.Threads = append(h.Threads, newHTMLThread(thread, opts))
+			}
+			data.Items = append(data.Items, h)
+		case "commit":
+			data.Items = append(data.Items, htmlItem{Commit: item.Commit, ShortSHA: shortSHA(item.Commit.SHA)})
+		case "reference":
+			data.Items = append(data.Items, htmlItem{Reference: item.Reference})
+		}
+	}
+
+	tmpl := template.Must(htmlTmpl.Clone())
+	commitURL := commitURLPrefix(pr)
+	tmpl.Funcs(template.FuncMap{"body": func(body string) template.HTML { return htmlBody(body, commitURL) }})
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("error rendering HTML: %w", err)
+	}
+	return nil
+}
+
+func newHTMLThread(thread CommentThread, opts RenderOptions) htmlThread {
+	comments := chronological(thread.Comments)
+	root := comments[0]
+	diff := root.DiffHunk
+	if diff != "" {
+		diff = strings.Join(transformDiff(strings.Split(normalizeNewlines(diff), "\n"), opts), "\n")
+	}
+	diffLines := 0
+	if diff != "" {
+		diffLines = strings.Count(diff, "\n") + 1
+	}
+
+	return htmlThread{
+		Path:      displayPath(root),
+		Outdated:  root.Line == nil && root.OriginalLine != nil,
+		Resolved:  thread.Resolved,
+		Summary:   pluralize(len(comments), "comment"),
+		Diff:      diff,
+		DiffLines: diffLines,
+		LongDiff:  diffLines > htmlLongDiffLines,
+		ExpandAll: opts.ExpandAll,
+		Comments:  comments,
+	}
+}
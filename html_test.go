@@ -0,0 +1,126 @@
+package prview_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func htmlTestPR() prview.PullRequest {
+	now := time.Now()
+	comment := func(id int64, path string, body string) prview.Comment {
+		return prview.Comment{ID: id, Body: body, Path: path, DiffHunk: "@@ -1 +1 @@\n+x", CreatedAt: now, User: prview.User{Login: "reviewer"}, Line: intPtr(1)}
+	}
+	return prview.PullRequest{
+		Number: 9,
+		Title:  "HTML <PR>",
+		User:   prview.User{Login: "author"},
+		Reviews: []prview.Review{{
+			ID:          10,
+			State:       "COMMENTED",
+			SubmittedAt: now,
+			User:        prview.User{Login: "reviewer"},
+			Threads: []prview.CommentThread{
+				{Comments: []prview.Comment{comment(1, "done.go", "Fixed now")}, Resolved: true},
+				{Comments: []prview.Comment{comment(2, "open.go", "Still open")}},
+			},
+		}},
+	}
+}
+
+func TestRenderHTMLCollapsesResolvedThreads(t *testing.T) {
+	var buf bytes.Buffer
+	if err := prview.RenderHTML(&buf, htmlTestPR(), prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderHTML returned an error: %v", err)
+	}
+	output := buf.String()
+
+	resolved := strings.Index(output, `<details class="thread resolved">`)
+	summary := strings.Index(output, "<summary>Resolved thread on done.go (1 comment)</summary>")
+	body := strings.Index(output, "Fixed now")
+	end := strings.Index(output[max(body, 0):], "</details>")
+	if resolved == -1 || summary < resolved || body < summary || end == -1 {
+		t.Errorf("Expected the resolved thread to be wrapped in a closed <details>, got:\n%s", output)
+	}
+	if !strings.Contains(output, "<div class=\"thread\">\n\n<p class=\"meta\">open.go</p>") {
+		t.Errorf("Expected the unresolved thread to be shown directly, got:\n%s", output)
+	}
+	if !strings.Contains(output, "HTML &lt;PR&gt;") {
+		t.Errorf("Expected the title to be escaped, got:\n%s", output)
+	}
+}
+
+func TestRenderHTMLExpandAll(t *testing.T) {
+	pr := htmlTestPR()
+	pr.Reviews[0].Threads[1].Comments[0].DiffHunk = "@@ -1,30 +1,30 @@" + strings.Repeat("\n context", 30)
+
+	var buf bytes.Buffer
+	if err := prview.RenderHTML(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderHTML returned an error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "<details>\n<summary>Diff (31 lines)</summary>") {
+		t.Errorf("Expected the long diff to start collapsed, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := prview.RenderHTML(&buf, pr, prview.RenderOptions{ExpandAll: true}); err != nil {
+		t.Fatalf("RenderHTML returned an error: %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, `<details class="thread resolved" open>`) || !strings.Contains(output, "<details open>\n<summary>Diff (31 lines)</summary>") {
+		t.Errorf("Expected every <details> to be open, got:\n%s", output)
+	}
+}
+
+func TestLoadPRResolvedThreads(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7":           `{"number": 7, "title": "Loaded PR", "user": {"login": "author"}}`,
+		"/repos/owner/repo/issues/7/comments": `[]`,
+		"/repos/owner/repo/pulls/7/reviews":   `[{"id": 10, "state": "COMMENTED", "user": {"login": "bob"}}]`,
+		"/repos/owner/repo/pulls/7/comments": `[
+			{"id": 1, "body": "Done", "path": "a.go", "pull_request_review_id": 10, "user": {"login": "bob"}},
+			{"id": 2, "body": "Open", "path": "b.go", "pull_request_review_id": 10, "user": {"login": "bob"}}
+		]`,
+		"/repos/owner/repo/pulls/7/commits": `[]`,
+		"/graphql": `{"data": {"repository": {"pullRequest": {"reviewThreads": {
+			"nodes": [
+				{"isResolved": true, "comments": {"nodes": [{"databaseId": 1}]}},
+				{"isResolved": false, "comments": {"nodes": [{"databaseId": 2}]}}
+			],
+			"pageInfo": {"hasNextPage": false}
+		}}}}}`,
+	}}
+
+	pr, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+	threads := pr.Reviews[0].Threads
+	if len(threads) != 2 || !threads[0].Resolved || threads[1].Resolved {
+		t.Errorf("Expected only the first thread to be resolved, got %+v", threads)
+	}
+}
+
+func TestRenderHTMLLinksCommitSHAs(t *testing.T) {
+	pr := htmlTestPR()
+	pr.HTMLURL = "https://github.com/owner/repo/pull/9"
+	pr.Comments = []prview.Comment{{ID: 3, Body: "Fixed in abc1234, the defaced 2024010 test; see abc1234/def and https://example.com/f00dcafe", User: prview.User{Login: "author"}}}
+
+	var buf bytes.Buffer
+	if err := prview.RenderHTML(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderHTML returned an error: %v", err)
+	}
+	output := buf.String()
+
+	if !strings.Contains(output, `Fixed in <a href="https://github.com/owner/repo/commit/abc1234">abc1234</a>, the defaced 2024010 test`) {
+		t.Errorf("Expected the SHA to link to its commit, got:\n%s", output)
+	}
+	if n := strings.Count(output, "<a href="); n != 1 {
+		t.Errorf("Expected only the SHA to be linked, got %d links:\n%s", n, output)
+	}
+}
@@ -0,0 +1,196 @@
+package prview
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// fullHunkHeaderPattern matches a hunk header, capturing the old start and
+// count, the new start and count, and any section heading after it
+var fullHunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@(.*)$`)
+
+// maxExpandHunkLines is the longest hunk of a patch expandHunk searches for
+// a comment's hunk. Finding it compares the stored lines at every offset,
+// which for a hunk of n lines takes up to n² comparisons, so threads in
+// larger hunks, such as generated files, keep the hunk GitHub stored.
+const maxExpandHunkLines = 2000
+
+// expandHunks widens the diff hunk of each thread's first comment with up to
+// n more lines either side, taken from the same hunk of its file's patch.
+// The hunk GitHub stores with a comment stops at the commented line, so
+// usually only the lines after it are added. Hunks that can't be found in
+// the patches, such as those of outdated comments, are left as stored.
+func expandHunks(reviews []Review, files []ChangedFile, n int) {
+	patches := make(map[string]string)
+	for _, f := range files {
+		patches[f.Filename] = f.Patch
+	}
+
+	for i := range reviews {
+		for j := range reviews[i].Threads {
+			thread := &reviews[i].Threads[j]
+			if len(thread.Comments) == 0 {
+				continue
+			}
+			root := &thread.Comments[0]
+			if patch, ok := patches[root.Path]; ok && root.DiffHunk != "" {
+				if expanded, ok := expandHunk(root.DiffHunk, patch, n); ok {
+					root.DiffHunk = expanded
+				}
+			}
+		}
+	}
+}
+
+// expandHunk finds hunk within one of the hunks of patch and returns it with
+// up to n of that hunk's lines added either side. The header is rewritten
+// when lines before the stored ones are shown, so line numbers stay right.
+func expandHunk(hunk, patch string, n int) (string, bool) {
+	lines := strings.Split(strings.TrimSuffix(normalizeNewlines(hunk), "\n"), "\n")
+	if len(lines) < 2 || !strings.HasPrefix(lines[0], "@@") {
+		return "", false
+	}
+	header, body := lines[0], lines[1:]
+
+	for _, candidate := range splitHunks(patch) {
+		if candidate[0] != header {
+			continue
+		}
+		full := candidate[1:]
+		if len(full) > maxExpandHunkLines {
+			return "", false
+		}
+		at := indexLines(full, body)
+		if at < 0 {
+			continue
+		}
+
+		start := max(at-n, 0)
+		end := min(at+len(body)+n, len(full))
+		if start > 0 {
+			var ok bool
+			if header, ok = skipHunkLines(header, full[:start]); !ok {
+				return "", false
+			}
+		}
+		return strings.Join(append([]string{header}, full[start:end]...), "\n"), true
+	}
+	return "", false
+}
+
+// hunkConsistent reports whether each header of a diff hunk parses and
+// covers the lines below it. Fewer lines than the header counts is expected,
+// as GitHub cuts the hunk stored with a comment off at the commented line;
+// more than it counts, on either side, means the hunk was mangled on the way.
+func hunkConsistent(hunk string) bool {
+	for _, lines := range splitHunks(hunk) {
+		m := fullHunkHeaderPattern.FindStringSubmatch(lines[0])
+		if m == nil {
+			return false
+		}
+		count := func(s string) int {
+			if s == "" {
+				return 1
+			}
+			n, _ := strconv.Atoi(s)
+			return n
+		}
+		oldCount, newCount := count(m[2]), count(m[4])
+
+		for _, line := range lines[1:] {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				newCount--
+			case strings.HasPrefix(line, "-"):
+				oldCount--
+			case strings.HasPrefix(line, `\`):
+			default:
+				oldCount--
+				newCount--
+			}
+		}
+		if oldCount < 0 || newCount < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// sameHunk reports whether two comments are on the same diff hunk of the same
+// file, ignoring differences in whitespace
+func sameHunk(a, b Comment) bool {
+	if a.DiffHunk == "" || a.Path != b.Path {
+		return false
+	}
+	return strings.Join(strings.Fields(a.DiffHunk), " ") == strings.Join(strings.Fields(b.DiffHunk), " ")
+}
+
+// splitHunks splits a file's patch into its hunks, each starting with its
+// header line
+func splitHunks(patch string) [][]string {
+	var hunks [][]string
+	for _, line := range strings.Split(strings.TrimSuffix(normalizeNewlines(patch), "\n"), "\n") {
+		if strings.HasPrefix(line, "@@") {
+			hunks = append(hunks, []string{line})
+		} else if len(hunks) > 0 {
+			hunks[len(hunks)-1] = append(hunks[len(hunks)-1], line)
+		}
+	}
+	return hunks
+}
+
+// indexLines returns the index of the first run of lines in s equal to sub,
+// or -1 if there is none
+func indexLines(s, sub []string) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		match := true
+		for j := range sub {
+			if s[i+j] != sub[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+// skipHunkLines rewrites a hunk header for the hunk that remains once its
+// first lines, skipped, are left out
+func skipHunkLines(header string, skipped []string) (string, bool) {
+	m := fullHunkHeaderPattern.FindStringSubmatch(header)
+	if m == nil {
+		return "", false
+	}
+	number := func(s string) int {
+		if s == "" {
+			return 1
+		}
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+	oldStart, oldCount := number(m[1]), number(m[2])
+	newStart, newCount := number(m[3]), number(m[4])
+
+	for _, line := range skipped {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			newStart++
+			newCount--
+		case strings.HasPrefix(line, "-"):
+			oldStart++
+			oldCount--
+		case strings.HasPrefix(line, `\`):
+		default:
+			oldStart++
+			oldCount--
+			newStart++
+			newCount--
+		}
+	}
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@%s", oldStart, oldCount, newStart, newCount, m[5]), true
+}
@@ -0,0 +1,179 @@
+package prview_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestLoadPRExpandHunk(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7":           `{"number": 7, "title": "Loaded PR", "user": {"login": "author"}}`,
+		"/repos/owner/repo/issues/7/comments": `[]`,
+		"/repos/owner/repo/pulls/7/reviews":   `[{"id": 10, "state": "COMMENTED", "user": {"login": "bob"}}]`,
+		"/repos/owner/repo/pulls/7/comments": `[
+			{"id": 1, "body": "Short hunk", "path": "main.go", "line": 3, "diff_hunk": "@@ -1,6 +1,7 @@\n package main\n \n+import \"os\"", "pull_request_review_id": 10, "user": {"login": "bob"}},
+			{"id": 2, "body": "Mid hunk", "path": "util.go", "line": 13, "diff_hunk": "@@ -10,5 +10,5 @@ func helper() {\n-\told()\n+\tnew()", "pull_request_review_id": 10, "user": {"login": "bob"}},
+			{"id": 3, "body": "Outdated", "path": "gone.go", "diff_hunk": "@@ -1 +1 @@\n-x\n+y", "pull_request_review_id": 10, "user": {"login": "bob"}}
+		]`,
+		"/repos/owner/repo/pulls/7/files": `[
+			{"filename": "main.go", "status": "modified", "patch": "@@ -1,6 +1,7 @@\n package main\n \n+import \"os\"\n import \"fmt\"\n \n func main() {\n }"},
+			{"filename": "util.go", "status": "modified", "patch": "@@ -10,5 +10,5 @@ func helper() {\n \ta()\n \tb()\n \tc()\n-\told()\n+\tnew()\n \td()"}
+		]`,
+		"/repos/owner/repo/pulls/7/commits": `[]`,
+	}}
+
+	pr, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt, ExpandHunk: 2})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+	threads := pr.Reviews[0].Threads
+
+	expected := "@@ -1,6 +1,7 @@\n package main\n \n+import \"os\"\n import \"fmt\"\n "
+	if got := threads[0].Comments[0].DiffHunk; got != expected {
+		t.Errorf("Expected the hunk to gain the next 2 lines:\n%s\ngot:\n%s", expected, got)
+	}
+	expected = "@@ -11,4 +11,4 @@ func helper() {\n \tb()\n \tc()\n-\told()\n+\tnew()\n \td()"
+	if got := threads[1].Comments[0].DiffHunk; got != expected {
+		t.Errorf("Expected 2 lines either side with a shifted header:\n%s\ngot:\n%s", expected, got)
+	}
+	if got := threads[2].Comments[0].DiffHunk; got != "@@ -1 +1 @@\n-x\n+y" {
+		t.Errorf("Expected the outdated hunk to be left as stored, got:\n%s", got)
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{DiffLineNumbers: true}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "      11 11  \tb()\n") {
+		t.Errorf("Expected the expanded lines to be numbered from the new header, got:\n%s", buf.String())
+	}
+}
+
+func TestRenderPRMalformedHunk(t *testing.T) {
+	tests := []struct {
+		name string
+		hunk string
+		note bool
+	}{
+		{"consistent", "@@ -1,3 +1,4 @@\n a\n+b\n c\n d", false},
+		{"cut off at the commented line", "@@ -1,6 +1,7 @@\n a\n+b", false},
+		{"too many added lines", "@@ -1,2 +1,2 @@\n a\n+b\n+c\n+d", true},
+		{"too many removed lines", "@@ -5 +5 @@\n-a\n-b", true},
+		{"unparsable header", "@@ -x +y @@\n a", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := createMockPR()
+			pr.Reviews[0].Threads[0].Comments[0].DiffHunk = tt.hunk
+
+			var buf bytes.Buffer
+			if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+				t.Fatalf("RenderPR returned an error: %v", err)
+			}
+			if note := strings.Contains(buf.String(), "(diff hunk may be truncated)"); note != tt.note {
+				t.Errorf("Expected note %v for hunk %q, got:\n%s", tt.note, tt.hunk, buf.String())
+			}
+		})
+	}
+}
+
+func TestRenderPRSameHunk(t *testing.T) {
+	pr := createMockPR()
+	threads := pr.Reviews[0].Threads
+	second := threads[0].Comments[0]
+	second.ID = 2
+	second.Body = "And a second point on the same lines"
+	second.CreatedAt = second.CreatedAt.Add(time.Minute)
+	// GitHub sometimes differs only in trailing whitespace
+	second.DiffHunk += "  \n"
+	other := second
+	other.ID = 3
+	other.Path = "other.go"
+	other.CreatedAt = other.CreatedAt.Add(time.Minute)
+	pr.Reviews[0].Threads = append(threads, prview.CommentThread{Comments: []prview.Comment{second}}, prview.CommentThread{Comments: []prview.Comment{other}})
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output := buf.String()
+
+	if n := strings.Count(output, "+  return 42;"); n != 2 {
+		t.Errorf("Expected the shared hunk once for main.go and once for other.go, got %d:\n%s", n, output)
+	}
+	if n := strings.Count(output, "(same hunk)"); n != 1 {
+		t.Errorf("Expected one (same hunk) note, got %d:\n%s", n, output)
+	}
+	if !strings.Contains(output, "And a second point on the same lines") {
+		t.Errorf("Expected the second comment to render, got:\n%s", output)
+	}
+}
+
+func TestLoadPRExpandHunkLarge(t *testing.T) {
+	setTestAuth(t)
+	patch := largeHunk(3000)
+	stored := strings.Join(strings.Split(patch, "\n")[:4], "\n")
+	comments, _ := json.Marshal([]map[string]any{{
+		"id": 1, "body": "Generated", "path": "gen.go", "line": 3, "diff_hunk": stored,
+		"pull_request_review_id": 10, "user": map[string]string{"login": "bob"},
+	}})
+	files, _ := json.Marshal([]map[string]string{{"filename": "gen.go", "status": "modified", "patch": patch}})
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7":           `{"number": 7, "title": "Loaded PR", "user": {"login": "author"}}`,
+		"/repos/owner/repo/issues/7/comments": `[]`,
+		"/repos/owner/repo/pulls/7/reviews":   `[{"id": 10, "state": "COMMENTED", "user": {"login": "bob"}}]`,
+		"/repos/owner/repo/pulls/7/comments":  string(comments),
+		"/repos/owner/repo/pulls/7/files":     string(files),
+		"/repos/owner/repo/pulls/7/commits":   `[]`,
+	}}
+
+	pr, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt, ExpandHunk: 2})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+	if got := pr.Reviews[0].Threads[0].Comments[0].DiffHunk; got != stored {
+		t.Errorf("Expected a hunk in an oversized patch hunk to be left as stored, got:\n%s", got)
+	}
+}
+
+// largeHunk returns a diff hunk of n lines mixing added, removed and context
+// lines, the added ones long enough to wrap
+func largeHunk(n int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "@@ -1,%d +1,%d @@", n-n/3, n-n/3)
+	for i := 0; i < n; i++ {
+		switch i % 3 {
+		case 0:
+			fmt.Fprintf(&b, "\n+\tadded line %d, with enough words in it to run past the width of a terminal", i)
+		case 1:
+			fmt.Fprintf(&b, "\n-\tremoved line %d", i)
+		default:
+			fmt.Fprintf(&b, "\n \tcontext line %d", i)
+		}
+	}
+	return b.String()
+}
+
+// BenchmarkRenderLargeDiff renders a thread on a 10,000 line hunk with every
+// diff transform and wrapping on, which should take time in proportion to
+// the hunk's length
+func BenchmarkRenderLargeDiff(b *testing.B) {
+	pr := createMockPR()
+	pr.Reviews[0].Threads[0].Comments[0].DiffHunk = largeHunk(10000)
+	opts := prview.RenderOptions{MaxWidth: 80, DiffLineNumbers: true, CompactDiff: true}
+
+	for b.Loop() {
+		if err := prview.RenderPR(io.Discard, pr, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
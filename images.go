@@ -0,0 +1,84 @@
+package prview
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// markdownImagePattern matches a Markdown image, ![alt](url "title"),
+// capturing the alt text and URL
+var markdownImagePattern = regexp.MustCompile(`!\[([^\]]*)\]\(\s*<?(https?://[^\s)>]+)>?(?:\s+"[^"]*")?\s*\)`)
+
+// htmlImagePattern matches an HTML <img> tag, which GitHub uses for
+// uploaded images given a size
+var htmlImagePattern = regexp.MustCompile(`(?i)<img\s[^>]*>`)
+
+// imgAttrPattern matches the src and alt attributes of an <img> tag
+var imgAttrPattern = regexp.MustCompile(`(?i)\b(src|alt)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+// image is an image embedded in a body, at body[start:end]
+type image struct {
+	alt, url   string
+	start, end int
+}
+
+// findImages returns the images embedded in body in order, whether in
+// Markdown or as <img> tags. Tags without an http(s) src are skipped.
+func findImages(body string) []image {
+	var images []image
+	for _, m := range markdownImagePattern.FindAllStringSubmatchIndex(body, -1) {
+		images = append(images, image{alt: body[m[2]:m[3]], url: body[m[4]:m[5]], start: m[0], end: m[1]})
+	}
+	for _, m := range htmlImagePattern.FindAllStringIndex(body, -1) {
+		img := image{start: m[0], end: m[1]}
+		for _, attr := range imgAttrPattern.FindAllStringSubmatch(body[m[0]:m[1]], -1) {
+			value := attr[2] + attr[3]
+			if strings.EqualFold(attr[1], "src") {
+				img.url = value
+			} else {
+				img.alt = value
+			}
+		}
+		if strings.HasPrefix(img.url, "https://") || strings.HasPrefix(img.url, "http://") {
+			images = append(images, img)
+		}
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].start < images[j].start })
+	return images
+}
+
+// mapImages rebuilds body with each embedded image replaced by image(alt,
+// url) and the text around them passed through text
+func mapImages(body string, text func(string) string, image func(alt, url string) string) string {
+	var b strings.Builder
+	pos := 0
+	for _, img := range findImages(body) {
+		if img.start < pos {
+			continue
+		}
+		b.WriteString(text(body[pos:img.start]))
+		b.WriteString(image(img.alt, img.url))
+		pos = img.end
+	}
+	b.WriteString(text(body[pos:]))
+	return b.String()
+}
+
+// imagesAsLinks replaces the images embedded in body with references that
+// survive a terminal, e.g. "[image: diagram] (https://...)"
+func imagesAsLinks(body string) string {
+	return mapImages(body, func(s string) string { return s }, func(alt, url string) string {
+		if alt == "" {
+			return fmt.Sprintf("[image] (%s)", url)
+		}
+		return fmt.Sprintf("[image: %s] (%s)", alt, url)
+	})
+}
+
+// bodyText prepares a comment, review or PR body for text output, marking
+// what opts.Highlight matches
+func bodyText(body string, opts RenderOptions) string {
+	return markMatches(sanitizeForTerminal(markQuotes(imagesAsLinks(body))), opts.Highlight)
+}
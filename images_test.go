@@ -0,0 +1,76 @@
+package prview_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func imagePR() prview.PullRequest {
+	return prview.PullRequest{
+		Number: 1,
+		Title:  "Images",
+		Body:   "Before:\n![old layout](https://user-images.githubusercontent.com/1/before.png)\nAfter: ![](https://example.com/after.png \"After\")",
+		Comments: []prview.Comment{{
+			ID:        1,
+			Body:      `Screenshot <img width="300" alt="dialog" src="https://github.com/user-attachments/assets/abc"> <b>x</b>`,
+			CreatedAt: time.Now(),
+			User:      prview.User{Login: "alice"},
+		}},
+	}
+}
+
+func TestRenderImagesAsLinks(t *testing.T) {
+	var buf bytes.Buffer
+	if err := prview.Render(&buf, imagePR(), prview.RenderOptions{}); err != nil {
+		t.Fatalf("Render returned an error: %v", err)
+	}
+	output := buf.String()
+
+	for _, expected := range []string{
+		"Before:\n[image: old layout] (https://user-images.githubusercontent.com/1/before.png)\n",
+		"After: [image] (https://example.com/after.png)\n",
+		"Screenshot [image: dialog] (https://github.com/user-attachments/assets/abc) <b>x</b>\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "![") || strings.Contains(output, "<img") {
+		t.Errorf("Expected no image markup left, got:\n%s", output)
+	}
+}
+
+func TestRenderHTMLImages(t *testing.T) {
+	var buf bytes.Buffer
+	if err := prview.Render(&buf, imagePR(), prview.RenderOptions{Format: "html"}); err != nil {
+		t.Fatalf("Render returned an error: %v", err)
+	}
+	output := buf.String()
+
+	for _, expected := range []string{
+		`<img src="https://user-images.githubusercontent.com/1/before.png" alt="old layout">`,
+		`<img src="https://example.com/after.png" alt="">`,
+		`Screenshot <img src="https://github.com/user-attachments/assets/abc" alt="dialog"> &lt;b&gt;x&lt;/b&gt;`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestRenderHTMLImageEscaping(t *testing.T) {
+	pr := prview.PullRequest{Number: 1, Body: `![x"><script>](https://example.com/a.png?q="b") <img src="javascript:alert(1)">`}
+
+	var buf bytes.Buffer
+	if err := prview.RenderHTML(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderHTML returned an error: %v", err)
+	}
+	output := buf.String()
+	if strings.Contains(output, "<script>") || strings.Contains(output, `<img src="javascript`) {
+		t.Errorf("Expected unsafe markup to be escaped, got:\n%s", output)
+	}
+}
@@ -0,0 +1,108 @@
+package prview
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Exclude leaves kinds of discussion out of the timeline. The zero value
+// leaves everything in.
+type Exclude struct {
+	// IssueComments are the comments on the PR's conversation tab
+	IssueComments bool
+	// ReviewSummaries are the reviews themselves: their states and bodies
+	ReviewSummaries bool
+	// InlineComments are the review comments on lines of the diff
+	InlineComments bool
+}
+
+// ParseInclude reads a comma separated list of the kinds of discussion to
+// show, from "issue", "review" and "inline", and returns what it leaves out
+func ParseInclude(list string) (Exclude, error) {
+	ex := Exclude{IssueComments: true, ReviewSummaries: true, InlineComments: true}
+	for _, name := range strings.Split(list, ",") {
+		switch strings.TrimSpace(name) {
+		case "issue":
+			ex.IssueComments = false
+		case "review":
+			ex.ReviewSummaries = false
+		case "inline":
+			ex.InlineComments = false
+		case "":
+		default:
+			return Exclude{}, fmt.Errorf("unknown kind %q to include; expected issue, review or inline", strings.TrimSpace(name))
+		}
+	}
+	if ex.IssueComments && ex.ReviewSummaries && ex.InlineComments {
+		return Exclude{}, fmt.Errorf("nothing to include; expected a list of issue, review or inline")
+	}
+	return ex, nil
+}
+
+// excludeCategories drops the kinds of discussion ex leaves out from the
+// timeline. Without review summaries, inline comments become items of their
+// own, as in a flat timeline.
+func excludeCategories(timeline []TimelineItem, ex Exclude) []TimelineItem {
+	if ex == (Exclude{}) {
+		return timeline
+	}
+
+	var kept []TimelineItem
+	for _, item := range timeline {
+		switch {
+		case item.Type == "comment" && ex.IssueComments:
+			continue
+		case item.Type == "review_comment" && ex.InlineComments:
+			continue
+		case item.Type == "review" && ex.InlineComments:
+			review := *item.Review
+			review.Threads = nil
+			review.ReplyCount = 0
+			if review.Body == "" && review.State == "COMMENTED" {
+				// It only held inline comments
+				continue
+			}
+			item.Review = &review
+		}
+		kept = append(kept, item)
+	}
+
+	if ex.ReviewSummaries {
+		var inline []TimelineItem
+		for _, item := range flattenTimeline(kept) {
+			if item.Type != "review" {
+				inline = append(inline, item)
+			}
+		}
+		kept = inline
+	}
+	return kept
+}
+
+// ExcludeKinds drops the kinds of discussion ex leaves out from the PR
+// itself, so that every format agrees on what is shown. Without review
+// summaries, a review stands only for the inline comments it holds.
+func ExcludeKinds(pr PullRequest, ex Exclude) PullRequest {
+	if ex.IssueComments {
+		pr.Comments = nil
+	}
+
+	var reviews []Review
+	for _, review := range pr.Reviews {
+		if ex.InlineComments {
+			review.Threads = nil
+			review.ReplyCount = 0
+		}
+		if ex.ReviewSummaries {
+			review.Body = ""
+			review.State = "COMMENTED"
+		}
+		if review.Body == "" && review.State == "COMMENTED" && len(review.Threads) == 0 {
+			// It only held inline comments
+			continue
+		}
+		reviews = append(reviews, review)
+	}
+	pr.Reviews = reviews
+	return pr
+}
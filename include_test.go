@@ -0,0 +1,122 @@
+package prview_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestParseInclude(t *testing.T) {
+	tests := []struct {
+		list    string
+		want    prview.Exclude
+		wantErr bool
+	}{
+		{"issue,review,inline", prview.Exclude{}, false},
+		{"inline", prview.Exclude{IssueComments: true, ReviewSummaries: true}, false},
+		{"issue, review", prview.Exclude{InlineComments: true}, false},
+		{"issue,threads", prview.Exclude{}, true},
+		{"", prview.Exclude{}, true},
+	}
+	for _, tt := range tests {
+		got, err := prview.ParseInclude(tt.list)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseInclude(%q) returned error %v", tt.list, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseInclude(%q) = %+v, want %+v", tt.list, got, tt.want)
+		}
+	}
+}
+
+func TestRenderIncludeInlineOnly(t *testing.T) {
+	ex, err := prview.ParseInclude("inline")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, createMockPR(), prview.RenderOptions{NoHeader: true, Exclude: ex}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output := buf.String()
+
+	if !strings.Contains(output, "This looks good") || !strings.Contains(output, "+  return 42;") {
+		t.Errorf("Expected the inline comment with its hunk, got:\n%s", output)
+	}
+	for _, unexpected := range []string{"This is a regular comment", "This is a later comment", "APPROVED", "Here's my review"} {
+		if strings.Contains(output, unexpected) {
+			t.Errorf("Expected %q to be left out, got:\n%s", unexpected, output)
+		}
+	}
+}
+
+func TestExcludeKindsJSON(t *testing.T) {
+	ex, err := prview.ParseInclude("inline")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	pr := prview.ExcludeKinds(createMockPR(), ex)
+	if err := prview.RenderJSON(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderJSON returned an error: %v", err)
+	}
+	var out struct {
+		Comments []json.RawMessage `json:"comments"`
+		Reviews  []struct {
+			State   string              `json:"state"`
+			Body    string              `json:"body"`
+			Threads [][]json.RawMessage `json:"threads"`
+		} `json:"reviews"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+
+	if len(out.Comments) != 0 {
+		t.Errorf("Expected the issue comments to be left out, got %d", len(out.Comments))
+	}
+	if len(out.Reviews) != 1 {
+		t.Fatalf("Expected the review holding the inline comment, got %d reviews", len(out.Reviews))
+	}
+	if r := out.Reviews[0]; r.State != "COMMENTED" || r.Body != "" || len(r.Threads) != 1 {
+		t.Errorf("Expected only the review's thread, got %+v", r)
+	}
+	if strings.Contains(buf.String(), "Here's my review") {
+		t.Errorf("Expected the review summary to be left out, got:\n%s", buf.String())
+	}
+
+	pr = prview.ExcludeKinds(createMockPR(), prview.Exclude{InlineComments: true})
+	if len(pr.Reviews) != 1 || len(pr.Reviews[0].Threads) != 0 || len(pr.Comments) != 2 {
+		t.Errorf("Expected the review without its threads and both issue comments, got %+v", pr)
+	}
+}
+
+func TestLoadPRIncludeSkipsFetches(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7":          `{"number": 7, "title": "Inline only", "user": {"login": "author"}}`,
+		"/repos/owner/repo/pulls/7/reviews":  `[{"id": 10, "state": "COMMENTED", "user": {"login": "bob"}}]`,
+		"/repos/owner/repo/pulls/7/comments": `[{"id": 2, "body": "Inline", "path": "a.go", "pull_request_review_id": 10, "user": {"login": "bob"}}]`,
+		"/repos/owner/repo/pulls/7/commits":  `[]`,
+	}}
+
+	pr, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt, Exclude: prview.Exclude{IssueComments: true, ReviewSummaries: true}})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+	for _, req := range rt.requests {
+		if strings.HasSuffix(req.URL.Path, "/issues/7/comments") {
+			t.Errorf("Expected issue comments not to be fetched")
+		}
+	}
+	if len(pr.Reviews) != 1 || len(pr.Reviews[0].Threads) != 1 {
+		t.Errorf("Expected the inline comment under its review, got %+v", pr.Reviews)
+	}
+}
@@ -0,0 +1,77 @@
+package prview
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/repository"
+)
+
+// closingRefPattern matches the keywords GitHub uses to close issues from a
+// PR body, capturing the optional owner/repo and the issue number
+var closingRefPattern = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?[ \t]+(?:([\w.-]+)/([\w.-]+))?#(\d+)\b`)
+
+// IssueRef identifies an issue referenced from a PR. Owner and Name are
+// empty when the issue is in the PR's own repository.
+type IssueRef struct {
+	Owner  string
+	Name   string
+	Number int
+}
+
+// String formats the reference the way it is written on GitHub, e.g. #12 or
+// owner/repo#12
+func (r IssueRef) String() string {
+	if r.Owner == "" {
+		return fmt.Sprintf("#%d", r.Number)
+	}
+	return fmt.Sprintf("%s/%s#%d", r.Owner, r.Name, r.Number)
+}
+
+// ParseClosingRefs returns the issues a PR body closes with keywords such as
+// "Closes #12" or "Fixes owner/repo#15", in order of first appearance
+func ParseClosingRefs(body string) []IssueRef {
+	var refs []IssueRef
+	seen := make(map[IssueRef]bool)
+
+	for _, match := range closingRefPattern.FindAllStringSubmatch(body, -1) {
+		number, err := strconv.Atoi(match[3])
+		if err != nil {
+			continue
+		}
+		ref := IssueRef{Owner: match[1], Name: match[2], Number: number}
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		refs = append(refs, ref)
+	}
+
+	return refs
+}
+
+// fetchClosedIssues looks up the title of each issue the PR body closes. An
+// issue the token can't see, e.g. in a private repository, is still listed
+// but without a title. Any other failure is returned.
+func fetchClosedIssues(ctx context.Context, client *api.RESTClient, repo repository.Repository, body string) ([]Issue, error) {
+	var issues []Issue
+	for _, ref := range ParseClosingRefs(body) {
+		target := repo
+		if ref.Owner != "" {
+			target = repository.Repository{Host: repo.Host, Owner: ref.Owner, Name: ref.Name}
+		}
+		issue, err := FetchIssue(ctx, client, target, ref.Number)
+		if err != nil {
+			if ctx.Err() != nil || !isInaccessible(err) {
+				return nil, fmt.Errorf("error fetching issue %s: %w", ref, err)
+			}
+			issue = Issue{}
+		}
+		issue.Ref = ref
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
@@ -0,0 +1,92 @@
+package prview_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestParseClosingRefs(t *testing.T) {
+	refs := prview.ParseClosingRefs("Closes #12, fixes other/repo#15.\nResolved: #12\nSee #99 and prefix#3")
+
+	want := []string{"#12", "other/repo#15"}
+	if len(refs) != len(want) {
+		t.Fatalf("Expected %d refs, got %+v", len(want), refs)
+	}
+	for i, ref := range refs {
+		if ref.String() != want[i] {
+			t.Errorf("Expected ref %d to be %s, got %s", i, want[i], ref)
+		}
+	}
+}
+
+func TestLoadPRClosedIssues(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7":           `{"number": 7, "title": "Loaded PR", "body": "Closes #12\nFixes other/repo#15", "user": {"login": "author"}}`,
+		"/repos/owner/repo/issues/7/comments": `[]`,
+		"/repos/owner/repo/pulls/7/reviews":   `[]`,
+		"/repos/owner/repo/pulls/7/comments":  `[]`,
+		"/repos/owner/repo/pulls/7/commits":   `[]`,
+		"/repos/owner/repo/issues/12":         `{"number": 12, "title": "Fix the bug"}`,
+		"/repos/other/repo/issues/15":         `{"number": 15, "title": "Another"}`,
+	}}
+
+	pr, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+	if len(pr.Closes) != 2 || pr.Closes[0].Title != "Fix the bug" || pr.Closes[1].Title != "Another" {
+		t.Fatalf("Expected both closed issues to be fetched, got %+v", pr.Closes)
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Closes: #12 Fix the bug, other/repo#15 Another\n") {
+		t.Errorf("Expected a Closes line in the header, got:\n%s", buf.String())
+	}
+}
+
+func TestLoadPRClosedIssuesErrors(t *testing.T) {
+	setTestAuth(t)
+	stub := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7":           `{"number": 7, "title": "Loaded PR", "body": "Closes #12\nFixes secret/repo#15", "user": {"login": "author"}}`,
+		"/repos/owner/repo/issues/7/comments": `[]`,
+		"/repos/owner/repo/pulls/7/reviews":   `[]`,
+		"/repos/owner/repo/pulls/7/comments":  `[]`,
+		"/repos/owner/repo/pulls/7/commits":   `[]`,
+		"/repos/owner/repo/issues/12":         `{"number": 12, "title": "Fix the bug"}`,
+	}}
+
+	// secret/repo#15 is a 404, so it is listed without a title
+	pr, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: stub})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+	if len(pr.Closes) != 2 || pr.Closes[1].Title != "" || pr.Closes[1].Ref.Number != 15 {
+		t.Fatalf("Expected the inaccessible issue without a title, got %+v", pr.Closes)
+	}
+
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/repos/secret/repo/issues/15" {
+			return &http.Response{
+				StatusCode: http.StatusBadGateway,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"message": "Bad Gateway"}`)),
+				Request:    req,
+			}, nil
+		}
+		return stub.RoundTrip(req)
+	})
+	_, err = prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt})
+	if err == nil || !strings.Contains(err.Error(), "secret/repo#15") {
+		t.Errorf("Expected the failure to fetch secret/repo#15 to be returned, got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package prview
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// jsonPR is the serialized form of a PullRequest written by RenderJSON.
+// Bodies are pointers so they can be omitted in favour of their length.
+type jsonPR struct {
+	Number     int           `json:"number"`
+	Title      string        `json:"title"`
+	Author     string        `json:"author"`
+	CreatedAt  time.Time     `json:"created_at"`
+	Body       *string       `json:"body,omitempty"`
+	BodyLength *int          `json:"body_length,omitempty"`
+	Comments   []jsonComment `json:"comments"`
+	Reviews    []jsonReview  `json:"reviews"`
+	Commits    []jsonCommit  `json:"commits"`
+}
+
+type jsonComment struct {
+	ID         int64     `json:"id"`
+	Author     string    `json:"author"`
+	CreatedAt  time.Time `json:"created_at"`
+	Body       *string   `json:"body,omitempty"`
+	BodyLength *int      `json:"body_length,omitempty"`
+	Path       string    `json:"path,omitempty"`
+	Line       *int      `json:"line,omitempty"`
+	DiffHunk   string    `json:"diff_hunk,omitempty"`
+}
+
+type jsonReview struct {
+	ID          int64           `json:"id"`
+	Author      string          `json:"author"`
+	State       string          `json:"state"`
+	SubmittedAt time.Time       `json:"submitted_at"`
+	Body        *string         `json:"body,omitempty"`
+	BodyLength  *int            `json:"body_length,omitempty"`
+	Threads     [][]jsonComment `json:"threads"`
+}
+
+type jsonCommit struct {
+	SHA       string      `json:"sha"`
+	Message   string      `json:"message"`
+	Author    string      `json:"author"`
+	CreatedAt time.Time   `json:"created_at"`
+	Checks    CheckCounts `json:"checks"`
+}
+
+// RenderJSON writes the PR as a JSON document, indented with two spaces
+// unless opts.JSONCompact is set. Either way the document ends in a newline.
+func RenderJSON(w io.Writer, pr PullRequest, opts RenderOptions) error {
+	out := jsonPR{
+		Number:    pr.Number,
+		Title:     pr.Title,
+		Author:    pr.User.Login,
+		CreatedAt: pr.CreatedAt,
+		Comments:  []jsonComment{},
+		Reviews:   []jsonReview{},
+		Commits:   []jsonCommit{},
+	}
+	out.Body, out.BodyLength = jsonBody(pr.Body, opts)
+
+	for _, c := range pr.Comments {
+		out.Comments = append(out.Comments, newJSONComment(c, opts))
+	}
+	for _, r := range pr.Reviews {
+		out.Reviews = append(out.Reviews, newJSONReview(r, opts))
+	}
+	for _, c := range pr.Commits {
+		out.Commits = append(out.Commits, newJSONCommit(c))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if !opts.JSONCompact {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(out)
+}
+
+// jsonBody returns a body for serializing, or only its length when
+// opts.NoBody is set
+func jsonBody(s string, opts RenderOptions) (*string, *int) {
+	if opts.NoBody {
+		n := len(s)
+		return nil, &n
+	}
+	return &s, nil
+}
+
+func newJSONComment(c Comment, opts RenderOptions) jsonComment {
+	jc := jsonComment{
+		ID:        c.ID,
+		Author:    c.User.Login,
+		CreatedAt: c.CreatedAt,
+		Path:      c.Path,
+		Line:      c.Line,
+		DiffHunk:  c.DiffHunk,
+	}
+	jc.Body, jc.BodyLength = jsonBody(c.Body, opts)
+	return jc
+}
+
+func newJSONReview(r Review, opts RenderOptions) jsonReview {
+	review := jsonReview{
+		ID:          r.ID,
+		Author:      r.User.Login,
+		State:       r.State,
+		SubmittedAt: r.SubmittedAt,
+		Threads:     [][]jsonComment{},
+	}
+	review.Body, review.BodyLength = jsonBody(r.Body, opts)
+	for _, thread := range r.Threads {
+		var comments []jsonComment
+		for _, c := range thread.Comments {
+			comments = append(comments, newJSONComment(c, opts))
+		}
+		review.Threads = append(review.Threads, comments)
+	}
+	return review
+}
+
+func newJSONCommit(c Commit) jsonCommit {
+	return jsonCommit{
+		SHA:       c.SHA,
+		Message:   c.Message,
+		Author:    c.Author.Login,
+		CreatedAt: c.CreatedAt,
+		Checks:    c.Checks,
+	}
+}
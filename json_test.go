@@ -0,0 +1,103 @@
+package prview_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestRenderJSON(t *testing.T) {
+	pr := createMockPR()
+
+	var buf bytes.Buffer
+	if err := prview.RenderJSON(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderJSON returned an error: %v", err)
+	}
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+
+	if out["body"] != "This is a test PR body" {
+		t.Errorf("Expected PR body, got %v", out["body"])
+	}
+	if _, ok := out["body_length"]; ok {
+		t.Errorf("Expected no body_length when bodies are included")
+	}
+	if n := len(out["comments"].([]interface{})); n != 2 {
+		t.Errorf("Expected 2 comments, got %d", n)
+	}
+	if n := len(out["reviews"].([]interface{})); n != 1 {
+		t.Errorf("Expected 1 review, got %d", n)
+	}
+}
+
+func TestRenderJSONNoBody(t *testing.T) {
+	pr := createMockPR()
+
+	var buf bytes.Buffer
+	if err := prview.RenderJSON(&buf, pr, prview.RenderOptions{NoBody: true}); err != nil {
+		t.Fatalf("RenderJSON returned an error: %v", err)
+	}
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+
+	comment := out["comments"].([]interface{})[0].(map[string]interface{})
+	review := out["reviews"].([]interface{})[0].(map[string]interface{})
+	threadComment := review["threads"].([]interface{})[0].([]interface{})[0].(map[string]interface{})
+
+	for name, obj := range map[string]map[string]interface{}{
+		"PR":             out,
+		"comment":        comment,
+		"review":         review,
+		"thread comment": threadComment,
+	} {
+		if _, ok := obj["body"]; ok {
+			t.Errorf("Expected %s body to be omitted", name)
+		}
+		if _, ok := obj["body_length"]; !ok {
+			t.Errorf("Expected %s body_length to be present", name)
+		}
+	}
+
+	if out["body_length"] != float64(len(pr.Body)) {
+		t.Errorf("Expected body_length %d, got %v", len(pr.Body), out["body_length"])
+	}
+}
+
+func TestRenderJSONCompact(t *testing.T) {
+	pr := createMockPR()
+
+	var pretty, compact bytes.Buffer
+	if err := prview.RenderJSON(&pretty, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderJSON returned an error: %v", err)
+	}
+	if err := prview.RenderJSON(&compact, pr, prview.RenderOptions{JSONCompact: true}); err != nil {
+		t.Fatalf("RenderJSON returned an error: %v", err)
+	}
+
+	if !strings.HasPrefix(pretty.String(), "{\n  \"number\": 123,\n") {
+		t.Errorf("Expected indented output, got:\n%s", pretty.String())
+	}
+	if !strings.HasSuffix(pretty.String(), "}\n") {
+		t.Errorf("Expected pretty output to end in a newline")
+	}
+
+	out := compact.String()
+	if !strings.HasSuffix(out, "}\n") {
+		t.Errorf("Expected compact output to end in a newline")
+	}
+	if strings.Contains(strings.TrimSuffix(out, "\n"), "\n") {
+		t.Errorf("Expected compact output on a single line, got:\n%s", out)
+	}
+	if !json.Valid(compact.Bytes()) {
+		t.Errorf("Expected compact output to be valid JSON")
+	}
+}
@@ -0,0 +1,74 @@
+package prview
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+type jsonReference struct {
+	Actor     string    `json:"actor"`
+	CreatedAt time.Time `json:"created_at"`
+	Number    int       `json:"number"`
+	Repo      string    `json:"repo"`
+	Title     string    `json:"title,omitempty"`
+}
+
+// RenderJSONLines writes each item of the PR's timeline as a JSON object on
+// a line of its own, in the order RenderPR shows them, for consumers that
+// read a line at a time. Every object starts with a "type" field naming the
+// kind of item, followed by the fields RenderJSON gives that kind: comment,
+// review, commit, review_comment with opts.FlatTimeline, or reference.
+func RenderJSONLines(w io.Writer, pr PullRequest, opts RenderOptions) error {
+	for _, item := range Timeline(pr, opts) {
+		var value interface{}
+		switch item.Type {
+		case "comment", "review_comment":
+			value = newJSONComment(*item.Comment, opts)
+		case "review":
+			value = newJSONReview(*item.Review, opts)
+		case "commit":
+			value = newJSONCommit(*item.Commit)
+		case "reference":
+			ref := item.Reference
+			value = jsonReference{
+				Actor:     ref.Actor.Login,
+				CreatedAt: ref.CreatedAt,
+				Number:    ref.Number,
+				Repo:      ref.Repo,
+				Title:     ref.Title,
+			}
+		default:
+			continue
+		}
+
+		line, err := typedJSON(item.Type, value)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// typedJSON encodes value, which must be a struct, as a single line JSON
+// object with a "type" field added at the front
+func typedJSON(typ string, value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	fields := bytes.TrimPrefix(buf.Bytes(), []byte("{"))
+
+	line := []byte(fmt.Sprintf(`{"type":%q`, typ))
+	if !bytes.HasPrefix(fields, []byte("}")) {
+		line = append(line, ',')
+	}
+	return append(line, fields...), nil
+}
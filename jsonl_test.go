@@ -0,0 +1,42 @@
+package prview_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestRenderJSONLines(t *testing.T) {
+	pr := createMockPR()
+	pr.Comments[0].Body = "Multi\nline \"quoted\" <b>body</b>"
+
+	var buf bytes.Buffer
+	if err := prview.Render(&buf, pr, prview.RenderOptions{Format: "jsonl"}); err != nil {
+		t.Fatalf("Render returned an error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	expectedTypes := []string{"comment", "review", "comment"}
+	if len(lines) != len(expectedTypes) {
+		t.Fatalf("Expected %d lines, got %d:\n%s", len(expectedTypes), len(lines), buf.String())
+	}
+
+	for i, line := range lines {
+		var item map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			t.Fatalf("Line %d isn't a JSON object: %v\n%s", i+1, err, line)
+		}
+		if item["type"] != expectedTypes[i] {
+			t.Errorf("Expected line %d to have type %q, got %v", i+1, expectedTypes[i], item["type"])
+		}
+		if _, ok := item["id"]; !ok {
+			t.Errorf("Expected line %d to keep the item's fields, got %s", i+1, line)
+		}
+	}
+	if !strings.Contains(lines[0], `"body":"Multi\nline \"quoted\" <b>body</b>"`) {
+		t.Errorf("Expected the body escaped onto one line, got %s", lines[0])
+	}
+}
@@ -0,0 +1,81 @@
+package prview
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReviewLatencies returns, for each reviewer, how long their first review
+// came after the PR was ready for it: after it was opened, or after the
+// last commit before that review if there was one. The author's own reviews
+// and pending ones don't count.
+func ReviewLatencies(pr PullRequest) map[string]time.Duration {
+	first := make(map[string]time.Time)
+	for _, review := range pr.Reviews {
+		login := review.User.Login
+		if review.State == pendingState || strings.EqualFold(login, pr.User.Login) || review.SubmittedAt.IsZero() {
+			continue
+		}
+		if at, ok := first[login]; !ok || review.SubmittedAt.Before(at) {
+			first[login] = review.SubmittedAt
+		}
+	}
+
+	latencies := make(map[string]time.Duration)
+	for login, at := range first {
+		start := pr.CreatedAt
+		for _, commit := range pr.Commits {
+			if commit.CreatedAt.After(start) && commit.CreatedAt.Before(at) {
+				start = commit.CreatedAt
+			}
+		}
+		latencies[login] = at.Sub(start)
+	}
+	return latencies
+}
+
+// formatLatency shows a duration in hours and minutes, e.g. "3h12m"
+func formatLatency(d time.Duration) string {
+	d = d.Truncate(time.Minute)
+	if d < time.Minute {
+		return "under a minute"
+	}
+	hours, minutes := int(d/time.Hour), int(d%time.Hour/time.Minute)
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dh%dm", hours, minutes)
+}
+
+// renderLatencies writes a line per reviewer with how long their first
+// review took, quickest first
+func renderLatencies(w io.Writer, pr PullRequest, opts RenderOptions) {
+	latencies := ReviewLatencies(pr)
+	if len(latencies) == 0 {
+		return
+	}
+
+	logins := make([]string, 0, len(latencies))
+	for login := range latencies {
+		logins = append(logins, login)
+	}
+	sort.Slice(logins, func(i, j int) bool {
+		a, b := latencies[logins[i]], latencies[logins[j]]
+		if a != b {
+			return a < b
+		}
+		return strings.ToLower(logins[i]) < strings.ToLower(logins[j])
+	})
+
+	fmt.Fprintln(w, "Review latency")
+	fmt.Fprintln(w, opts.rule("="))
+	for _, login := range logins {
+		fmt.Fprintf(w, "%s first reviewed after %s\n", sanitizeForTerminal(login), formatLatency(latencies[login]))
+	}
+}
@@ -0,0 +1,55 @@
+package prview_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func latencyPR() prview.PullRequest {
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	return prview.PullRequest{
+		Number:    1,
+		Title:     "Latency",
+		CreatedAt: at,
+		User:      prview.User{Login: "author"},
+		Commits: []prview.Commit{
+			{SHA: "a", CreatedAt: at.Add(-time.Hour)},
+			{SHA: "b", CreatedAt: at.Add(5 * time.Hour)},
+		},
+		Reviews: []prview.Review{
+			{ID: 1, State: "COMMENTED", SubmittedAt: at.Add(3*time.Hour + 12*time.Minute), User: prview.User{Login: "alice"}},
+			{ID: 2, State: "APPROVED", SubmittedAt: at.Add(8 * time.Hour), User: prview.User{Login: "alice"}},
+			{ID: 3, State: "APPROVED", SubmittedAt: at.Add(5*time.Hour + 45*time.Minute), User: prview.User{Login: "bob"}},
+			{ID: 4, State: "COMMENTED", SubmittedAt: at.Add(time.Minute), User: prview.User{Login: "author"}},
+			{ID: 5, State: "PENDING", User: prview.User{Login: "carol"}},
+		},
+	}
+}
+
+func TestReviewLatencies(t *testing.T) {
+	expected := map[string]time.Duration{
+		// From the PR being opened; the earlier commit doesn't count
+		"alice": 3*time.Hour + 12*time.Minute,
+		// From the commit pushed before bob's review
+		"bob": 45 * time.Minute,
+	}
+	if got := prview.ReviewLatencies(latencyPR()); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestRenderLatency(t *testing.T) {
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, latencyPR(), prview.RenderOptions{Latency: true}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	expected := "Review latency\n" + strings.Repeat("=", 80) + "\nbob first reviewed after 45m\nalice first reviewed after 3h12m\n"
+	if !strings.HasSuffix(buf.String(), expected) {
+		t.Errorf("Expected output to end with:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,136 @@
+package prview
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// defaultRuleWidth is the width of the rules between timeline items when no
+// maximum width is set
+const defaultRuleWidth = 80
+
+// ruleWidth returns the width available to text output after the margin
+func (opts RenderOptions) ruleWidth() int {
+	if opts.MaxWidth > 0 {
+		return max(opts.MaxWidth-opts.Margin, 1)
+	}
+	return defaultRuleWidth
+}
+
+// rule returns a separator line of ch spanning the output width
+func (opts RenderOptions) rule(ch string) string {
+	return strings.Repeat(ch, opts.ruleWidth())
+}
+
+// renderFitted calls render with w, first buffering the output to fit it
+// to opts.MaxWidth and opts.Margin when either is set
+func renderFitted(w io.Writer, opts RenderOptions, render func(io.Writer) error) error {
+	if opts.MaxWidth <= 0 && opts.Margin <= 0 {
+		return render(w)
+	}
+
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, fitLines(buf.String(), opts.MaxWidth, opts.Margin))
+	return err
+}
+
+// fitLines indents every non-empty line of text by margin spaces and, when
+// maxWidth is positive, wraps lines so none is wider than maxWidth including
+// the margin. Wrapped lines break at spaces where possible and continue at
+// the indentation of the line they came from.
+func fitLines(text string, maxWidth int, margin int) string {
+	pad := strings.Repeat(" ", margin)
+	width := 0
+	if maxWidth > 0 {
+		width = max(maxWidth-margin, 1)
+	}
+
+	var out strings.Builder
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		for j, part := range wrapLine(line, width) {
+			if j > 0 {
+				out.WriteString("\n")
+			}
+			if part != "" {
+				out.WriteString(pad)
+			}
+			out.WriteString(part)
+		}
+	}
+	return out.String()
+}
+
+// wrapLine splits line into pieces no wider than width terminal columns, or
+// returns it whole when width is zero. Wide characters, such as CJK and most
+// emoji, count as the two columns they take up.
+func wrapLine(line string, width int) []string {
+	if width <= 0 || runewidth.StringWidth(line) <= width {
+		return []string{line}
+	}
+
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	if runewidth.StringWidth(indent) >= width/2 {
+		// Deeply indented text would leave no room, so don't carry it over
+		indent = ""
+	}
+
+	var parts []string
+	rest := []rune(line)
+	prefix := ""
+	for {
+		avail := width - runewidth.StringWidth(prefix)
+		fit := fitRunes(rest, avail)
+		if fit == len(rest) {
+			parts = append(parts, prefix+string(rest))
+			return parts
+		}
+
+		cut := fit
+		for k := fit; k > 0; k-- {
+			if rest[k] == ' ' {
+				cut = k
+				break
+			}
+		}
+		piece := strings.TrimRight(string(rest[:cut]), " ")
+		if strings.TrimSpace(piece) == "" {
+			// Only leading spaces fit before the break, so split the word
+			cut = fit
+			piece = string(rest[:cut])
+		}
+		parts = append(parts, prefix+piece)
+
+		rest = rest[cut:]
+		for len(rest) > 0 && rest[0] == ' ' {
+			rest = rest[1:]
+		}
+		if len(rest) == 0 {
+			return parts
+		}
+		prefix = indent
+	}
+}
+
+// fitRunes returns how many of the leading runes fit in width columns,
+// which is at least one so that a character wider than width still makes
+// progress
+func fitRunes(runes []rune, width int) int {
+	used := 0
+	for i, r := range runes {
+		used += runewidth.RuneWidth(r)
+		if used > width {
+			return max(i, 1)
+		}
+	}
+	return len(runes)
+}
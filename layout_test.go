@@ -0,0 +1,84 @@
+package prview_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	prview "github.com/bmon/gh-prview"
+	"github.com/mattn/go-runewidth"
+)
+
+func TestRenderMaxWidth(t *testing.T) {
+	pr := createMockPR()
+	pr.Body = strings.Repeat("A rather long description of the change that goes on and on. ", 5) + strings.Repeat("x", 100)
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{MaxWidth: 40}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output := buf.String()
+
+	separators := 0
+	for _, line := range strings.Split(output, "\n") {
+		if n := utf8.RuneCountInString(line); n > 40 {
+			t.Errorf("Expected no line wider than 40, got %d: %q", n, line)
+		}
+		if strings.Trim(line, "-") == "" && line != "" {
+			separators++
+			if len(line) != 40 {
+				t.Errorf("Expected the separator to be 40 wide, got %d", len(line))
+			}
+		}
+	}
+	if separators == 0 {
+		t.Errorf("Expected separators in the output, got:\n%s", output)
+	}
+	if !strings.Contains(output, "\nA rather long description of the change\nthat goes on and on.") {
+		t.Errorf("Expected the body to wrap at a space, got:\n%s", output)
+	}
+}
+
+func TestRenderMaxWidthWideCharacters(t *testing.T) {
+	pr := createMockPR()
+	pr.Body = strings.Repeat("日本語の説明 ", 10)
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{MaxWidth: 30}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	// Each word is 12 columns wide, so two fit on a line but not three
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if n := runewidth.StringWidth(line); n > 30 {
+			t.Errorf("Expected no line wider than 30 columns, got %d: %q", n, line)
+		}
+	}
+	if !strings.Contains(buf.String(), "\n日本語の説明 日本語の説明\n日本語の説明 日本語の説明\n") {
+		t.Errorf("Expected the body to wrap by display width, got:\n%s", buf.String())
+	}
+}
+
+func TestRenderMargin(t *testing.T) {
+	pr := createMockPR()
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{MaxWidth: 60, Margin: 4}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "    ") {
+			t.Errorf("Expected every line to have the margin, got %q", line)
+		}
+		if n := utf8.RuneCountInString(line); n > 60 {
+			t.Errorf("Expected no line wider than 60 including the margin, got %d: %q", n, line)
+		}
+	}
+	if !strings.Contains(buf.String(), "\n    "+strings.Repeat("-", 56)+"\n") {
+		t.Errorf("Expected the separator to fill the width inside the margin, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,86 @@
+package prview
+
+import (
+	"regexp"
+	"strings"
+)
+
+// urlPattern matches http(s) URLs, whether bare or inside Markdown link and
+// image syntax, stopping at characters that delimit the Markdown around them
+var urlPattern = regexp.MustCompile("https?://[^\\s<>()\\[\\]\"'`]+")
+
+// ExtractLinks returns every URL referenced in the PR body, comments and
+// reviews, deduplicated and in order of first appearance
+func ExtractLinks(pr PullRequest) []string {
+	var links []string
+	seen := make(map[string]bool)
+
+	add := func(body string) {
+		for _, link := range urlPattern.FindAllString(body, -1) {
+			link = strings.TrimRight(link, ".,;:!?*_~")
+			if !seen[link] {
+				seen[link] = true
+				links = append(links, link)
+			}
+		}
+	}
+
+	add(pr.Body)
+	for _, item := range buildTimeline(pr) {
+		switch item.Type {
+		case "comment":
+			add(item.Comment.Body)
+		case "review":
+			add(item.Review.Body)
+			for _, thread := range item.Review.Threads {
+				for _, comment := range thread.Comments {
+					add(comment.Body)
+				}
+			}
+		}
+	}
+
+	return links
+}
+
+// commitSHAPattern matches runs of 7 to 40 lowercase hex digits that stand
+// alone as words, which linkCommitSHAs narrows down to likely commit SHAs
+var commitSHAPattern = regexp.MustCompile(`\b[0-9a-f]{7,40}\b`)
+
+// linkCommitSHAs rebuilds text with what look like commit SHAs replaced by
+// link(sha) and the text around them passed through plain. To keep out
+// words such as "defaced" and numbers such as dates, a SHA must mix digits
+// and letters, and to keep out paths and URLs, it can't be joined to the
+// text around it by a slash or similar.
+func linkCommitSHAs(text string, plain func(string) string, link func(sha string) string) string {
+	var b strings.Builder
+	pos := 0
+	for _, m := range commitSHAPattern.FindAllStringIndex(text, -1) {
+		sha := text[m[0]:m[1]]
+		if !strings.ContainsAny(sha, "0123456789") || !strings.ContainsAny(sha, "abcdef") {
+			continue
+		}
+		if m[0] > 0 && strings.ContainsRune("/.-#@", rune(text[m[0]-1])) {
+			continue
+		}
+		if m[1] < len(text) && strings.ContainsRune("/-", rune(text[m[1]])) {
+			continue
+		}
+		b.WriteString(plain(text[pos:m[0]]))
+		b.WriteString(link(sha))
+		pos = m[1]
+	}
+	b.WriteString(plain(text[pos:]))
+	return b.String()
+}
+
+// commitURLPrefix returns the URL commit SHAs are appended to to link to
+// them, e.g. "https://github.com/owner/repo/commit/", worked out from the
+// PR's own URL. It is empty when that isn't known.
+func commitURLPrefix(pr PullRequest) string {
+	repoURL, _, ok := strings.Cut(pr.HTMLURL, "/pull/")
+	if !ok {
+		return ""
+	}
+	return repoURL + "/commit/"
+}
@@ -0,0 +1,47 @@
+package prview_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestExtractLinks(t *testing.T) {
+	now := time.Now()
+	pr := prview.PullRequest{
+		Body: "See the [design doc](https://example.com/design) and ![screenshot](https://example.com/shot.png).",
+		Comments: []prview.Comment{
+			{
+				ID:        1,
+				Body:      "Related: https://github.com/bmon/gh-prview/issues/1.",
+				CreatedAt: now,
+			},
+			{
+				ID:        2,
+				Body:      "Duplicate of https://example.com/design",
+				CreatedAt: now.Add(time.Minute),
+			},
+		},
+		Reviews: []prview.Review{
+			{
+				ID:          101,
+				Body:        "Checked against <https://example.com/spec>",
+				SubmittedAt: now.Add(2 * time.Minute),
+			},
+		},
+	}
+
+	expected := []string{
+		"https://example.com/design",
+		"https://example.com/shot.png",
+		"https://github.com/bmon/gh-prview/issues/1",
+		"https://example.com/spec",
+	}
+
+	links := prview.ExtractLinks(pr)
+	if !reflect.DeepEqual(links, expected) {
+		t.Errorf("Expected links %v, got %v", expected, links)
+	}
+}
@@ -0,0 +1,50 @@
+package prview
+
+import (
+	"fmt"
+	"strings"
+)
+
+// replyDepths returns how many comments up the chain of replies each of a
+// thread's comments is from the first, keyed by ID. Replies to comments
+// outside the thread count as replies to the first.
+func replyDepths(comments []Comment) map[int64]int {
+	parents := make(map[int64]int64, len(comments))
+	for _, c := range comments {
+		if c.InReplyToID != nil {
+			parents[c.ID] = *c.InReplyToID
+		}
+	}
+
+	depths := make(map[int64]int, len(comments))
+	for _, c := range comments {
+		depth := 0
+		// Stop at the thread's length in case the replies loop back on
+		// themselves
+		for id := c.ID; depth < len(comments); depth++ {
+			parent, ok := parents[id]
+			if !ok {
+				break
+			}
+			id = parent
+		}
+		depths[c.ID] = depth
+	}
+	return depths
+}
+
+// replyNesting returns the indentation a comment at depth in its thread's
+// replies gets beyond the thread's own, and the marker it is shown with. As
+// on GitHub, direct replies line up with the comment they answer; replies to
+// replies are indented a level for each reply above them, up to
+// opts.MaxIndent levels deep, beyond which they're marked with their depth,
+// e.g. "[L5] ".
+func replyNesting(depth int, opts RenderOptions) (string, string) {
+	if depth <= 1 {
+		return "", ""
+	}
+	if opts.MaxIndent > 0 && depth > opts.MaxIndent {
+		return strings.Repeat(opts.indent(), opts.MaxIndent-1), fmt.Sprintf("[L%d] ", depth)
+	}
+	return strings.Repeat(opts.indent(), depth-1), ""
+}
@@ -0,0 +1,66 @@
+package prview_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+// replyChain returns a thread of n comments, each replying to the one before
+func replyChain(n int) prview.CommentThread {
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	var thread prview.CommentThread
+	for i := 1; i <= n; i++ {
+		c := prview.Comment{
+			ID:        int64(i),
+			Body:      fmt.Sprintf("Comment %d", i),
+			Path:      "main.go",
+			CreatedAt: at.Add(time.Duration(i) * time.Minute),
+			User:      prview.User{Login: fmt.Sprintf("user%d", i)},
+		}
+		if i > 1 {
+			c.InReplyToID = int64Ptr(int64(i - 1))
+		}
+		thread.Comments = append(thread.Comments, c)
+	}
+	return thread
+}
+
+func TestRenderNestedReplies(t *testing.T) {
+	var buf bytes.Buffer
+	if err := prview.RenderReview(&buf, prview.Review{State: "COMMENTED", User: prview.User{Login: "user1"}, Threads: []prview.CommentThread{replyChain(4)}}, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderReview returned an error: %v", err)
+	}
+	output := buf.String()
+
+	for _, want := range []string{"\n  @user1 at", "\n  @user2 at", "\n    @user3 at", "\n      @user4 at", "\n        Comment 4\n"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "[L") {
+		t.Errorf("Expected no depth markers without --max-indent, got:\n%s", output)
+	}
+}
+
+func TestRenderNestedRepliesMaxIndent(t *testing.T) {
+	var buf bytes.Buffer
+	if err := prview.RenderReview(&buf, prview.Review{State: "COMMENTED", User: prview.User{Login: "user1"}, Threads: []prview.CommentThread{replyChain(6)}}, prview.RenderOptions{MaxIndent: 3}); err != nil {
+		t.Fatalf("RenderReview returned an error: %v", err)
+	}
+	output := buf.String()
+
+	// Depth 3 is the deepest nesting; the replies below it stay there, marked
+	for _, want := range []string{"\n      @user4 at", "\n      [L4] @user5 at", "\n      [L5] @user6 at", "\n        Comment 6\n"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "[L3]") {
+		t.Errorf("Expected no marker on replies within the limit, got:\n%s", output)
+	}
+}
@@ -0,0 +1,88 @@
+package prview
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+)
+
+// Notifier alerts the user that a review requesting changes has appeared
+type Notifier interface {
+	Notify(pr PullRequest, review Review) error
+}
+
+// BellNotifier rings the terminal bell by writing BEL to W
+type BellNotifier struct {
+	W io.Writer
+}
+
+func (n BellNotifier) Notify(pr PullRequest, review Review) error {
+	_, err := io.WriteString(n.W, "\a")
+	return err
+}
+
+// DesktopNotifier shows a desktop notification with notify-send on Linux or
+// osascript on macOS
+type DesktopNotifier struct{}
+
+// notifyTimeout bounds how long the notification command may take
+const notifyTimeout = 5 * time.Second
+
+func (DesktopNotifier) Notify(pr PullRequest, review Review) error {
+	title := fmt.Sprintf("PR #%d: changes requested", pr.Number)
+	message := fmt.Sprintf("%s requested changes on %s", review.User.Login, pr.Title)
+
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		_, err = runCommand(notifyTimeout, "osascript", osascriptArgs(title, message)...)
+	case "linux", "freebsd", "openbsd", "netbsd":
+		_, err = runCommand(notifyTimeout, "notify-send", title, message)
+	default:
+		err = fmt.Errorf("desktop notifications aren't supported on %s", runtime.GOOS)
+	}
+	return err
+}
+
+// osascriptArgs are the arguments to osascript for a notification. The
+// title and message are passed as arguments to the script rather than
+// quoted inside it, since AppleScript strings don't escape like Go's.
+func osascriptArgs(title, message string) []string {
+	return []string{
+		"-e", "on run argv",
+		"-e", "display notification (item 1 of argv) with title (item 2 of argv)",
+		"-e", "end run",
+		message, title,
+	}
+}
+
+// ReviewTracker remembers the reviews seen across successive loads of a PR
+// so newly submitted ones can be picked out. The zero value is ready to use.
+type ReviewTracker struct {
+	seen   map[int64]bool
+	primed bool
+}
+
+// NewChangeRequests records the reviews on pr and returns those requesting
+// changes that weren't on any earlier snapshot. The first snapshot only
+// primes the tracker, since its reviews aren't news.
+func (t *ReviewTracker) NewChangeRequests(pr PullRequest) []Review {
+	if t.seen == nil {
+		t.seen = make(map[int64]bool)
+	}
+
+	var fresh []Review
+	for _, review := range pr.Reviews {
+		if t.seen[review.ID] {
+			continue
+		}
+		t.seen[review.ID] = true
+		if t.primed && review.State == "CHANGES_REQUESTED" {
+			fresh = append(fresh, review)
+		}
+	}
+	t.primed = true
+
+	return fresh
+}
@@ -0,0 +1,60 @@
+package prview_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestReviewTrackerNewChangeRequests(t *testing.T) {
+	first := prview.PullRequest{Reviews: []prview.Review{
+		{ID: 1, State: "CHANGES_REQUESTED"},
+		{ID: 2, State: "COMMENTED"},
+	}}
+	second := prview.PullRequest{Reviews: []prview.Review{
+		{ID: 1, State: "CHANGES_REQUESTED"},
+		{ID: 2, State: "COMMENTED"},
+		{ID: 3, State: "APPROVED"},
+		{ID: 4, State: "CHANGES_REQUESTED"},
+	}}
+
+	var tracker prview.ReviewTracker
+	if fresh := tracker.NewChangeRequests(first); len(fresh) != 0 {
+		t.Errorf("Expected the first snapshot to only prime the tracker, got %+v", fresh)
+	}
+	fresh := tracker.NewChangeRequests(second)
+	if len(fresh) != 1 || fresh[0].ID != 4 {
+		t.Errorf("Expected only review 4 to be reported, got %+v", fresh)
+	}
+	if fresh := tracker.NewChangeRequests(second); len(fresh) != 0 {
+		t.Errorf("Expected an unchanged snapshot to report nothing, got %+v", fresh)
+	}
+}
+
+func TestBellNotifier(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (prview.BellNotifier{W: &buf}).Notify(prview.PullRequest{}, prview.Review{}); err != nil {
+		t.Fatalf("Notify returned an error: %v", err)
+	}
+	if buf.String() != "\a" {
+		t.Errorf("Expected a BEL character, got %q", buf.String())
+	}
+}
+
+func TestOsascriptArgs(t *testing.T) {
+	message := `bob requested changes on Fix "quotes" and C:\path`
+	args := prview.OsascriptArgs("PR #1: changes requested", message)
+
+	want := []string{message, "PR #1: changes requested"}
+	if got := args[len(args)-2:]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the message and title as script arguments, got %q", got)
+	}
+	for _, arg := range args[:len(args)-2] {
+		if strings.Contains(arg, "quotes") {
+			t.Errorf("Expected the message to stay out of the script, got %q", arg)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package prview
+
+import (
+	"context"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/repository"
+)
+
+// defaultOutdatedLines is how many lines either side of an outdated
+// thread's old position are shown from the head commit when no context
+// size is given
+const defaultOutdatedLines = 3
+
+// addCurrentContext attaches to the root of each outdated thread up to n
+// lines either side of its original line number in the file at the head
+// commit, so the code as commented on can be compared with the code now.
+// Files that no longer exist at head are marked as removed. Each file is
+// fetched at most once.
+func addCurrentContext(ctx context.Context, client *api.RESTClient, repo repository.Repository, reviews []Review, headSHA string, n int) {
+	type file struct {
+		lines   []string
+		removed bool
+	}
+	files := make(map[string]file)
+
+	for i := range reviews {
+		for j := range reviews[i].Threads {
+			root := &reviews[i].Threads[j].Comments[0]
+			isOutdated := root.Line == nil && root.OriginalLine != nil
+			if !isOutdated || root.Path == "" || headSHA == "" {
+				continue
+			}
+
+			f, ok := files[root.Path]
+			if !ok {
+				lines, err := FetchFileLines(ctx, client, repo, root.Path, headSHA)
+				f = file{lines: lines, removed: isNotFound(err)}
+				files[root.Path] = f
+			}
+
+			if f.removed {
+				root.CurrentContext = &FileContext{Ref: headSHA, Removed: true}
+				continue
+			}
+			line := *root.OriginalLine
+			if line < 1 || line > len(f.lines) {
+				continue
+			}
+
+			start := max(line-n, 1)
+			end := min(line+n, len(f.lines))
+			root.CurrentContext = &FileContext{
+				Ref:       headSHA,
+				StartLine: start,
+				Line:      line,
+				Lines:     f.lines[start-1 : end],
+			}
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package prview_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestLoadPROutdatedCurrent(t *testing.T) {
+	setTestAuth(t)
+	current := base64.StdEncoding.EncodeToString([]byte("package main\n\nfunc renamed() {}\n\nfunc main() {}\n"))
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7":           `{"number": 7, "title": "Outdated", "user": {"login": "author"}, "head": {"ref": "feature", "sha": "headsha1234"}}`,
+		"/repos/owner/repo/issues/7/comments": `[]`,
+		"/repos/owner/repo/pulls/7/reviews":   `[{"id": 10, "state": "COMMENTED", "user": {"login": "bob"}}]`,
+		"/repos/owner/repo/pulls/7/comments": `[
+			{"id": 1, "body": "Rename this", "path": "main.go", "diff_hunk": "@@ -1,3 +1,3 @@\n+func old() {}", "original_line": 3, "pull_request_review_id": 10, "user": {"login": "bob"}},
+			{"id": 2, "body": "Why this file?", "path": "gone.go", "diff_hunk": "@@ -0,0 +1 @@\n+package gone", "original_line": 1, "pull_request_review_id": 10, "user": {"login": "bob"}}
+		]`,
+		"/repos/owner/repo/pulls/7/commits":  `[]`,
+		"/repos/owner/repo/contents/main.go": `{"encoding": "base64", "content": "` + current + `"}`,
+	}}
+
+	pr, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt, OutdatedCurrent: true, ContextLines: 1})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+	for _, req := range rt.requests {
+		if strings.HasPrefix(req.URL.Path, "/repos/owner/repo/contents/") && req.URL.Query().Get("ref") != "headsha1234" {
+			t.Errorf("Expected file contents to be fetched at head, got %s", req.URL)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output := buf.String()
+
+	expectedStrings := []string{
+		"  main.go [outdated]\n  Then:\n    @@ -1,3 +1,3 @@\n    +func old() {}\n  Now @ headsha:\n    2  \n  > 3  func renamed() {}\n    4  \n",
+		"  gone.go [outdated]\n  Then:\n    @@ -0,0 +1 @@\n    +package gone\n  Now: file removed\n",
+	}
+	for _, expected := range expectedStrings {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain:\n%s\ngot:\n%s", expected, output)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package prview
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RenderPatch writes the PR's commits as a series of patches in the style of
+// git format-patch, followed by the review discussion after the signature
+// separator of the last patch
+func RenderPatch(w io.Writer, pr PullRequest) error {
+	if len(pr.Commits) == 0 {
+		return fmt.Errorf("PR #%d has no commits to render as patches", pr.Number)
+	}
+
+	for i, commit := range pr.Commits {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		prefix := "[PATCH]"
+		if len(pr.Commits) > 1 {
+			prefix = fmt.Sprintf("[PATCH %d/%d]", i+1, len(pr.Commits))
+		}
+
+		author := commit.Author.Login
+		if author == "" {
+			author = "unknown"
+		}
+
+		// git uses this fixed date in the mbox separator line so patches can
+		// be told apart from real mail
+		fmt.Fprintf(w, "From %s Mon Sep 17 00:00:00 2001\n", commit.SHA)
+		fmt.Fprintf(w, "From: %s\n", author)
+		fmt.Fprintf(w, "Date: %s\n", commit.CreatedAt.Format("Mon, 2 Jan 2006 15:04:05 -0700"))
+		fmt.Fprintf(w, "Subject: %s %s\n\n", prefix, commit.Message)
+		if commit.Body != "" {
+			fmt.Fprintf(w, "%s\n\n", commit.Body)
+		}
+		fmt.Fprintln(w, "---")
+		fmt.Fprint(w, commit.Diff)
+	}
+
+	fmt.Fprintln(w, "-- ")
+	fmt.Fprintf(w, "PR #%d: %s\n", pr.Number, pr.Title)
+	for _, item := range buildTimeline(pr) {
+		switch item.Type {
+		case "comment":
+			fmt.Fprintf(w, "\n%s COMMENTED at %s:\n", item.Comment.User.Login, item.Comment.CreatedAt.Format("2006-01-02 15:04:05"))
+			writeQuoted(w, "  ", item.Comment.Body)
+		case "review":
+			review := item.Review
+			fmt.Fprintf(w, "\n%s %s at %s:\n", review.User.Login, review.State, review.SubmittedAt.Format("2006-01-02 15:04:05"))
+			writeQuoted(w, "  ", review.Body)
+			for _, thread := range review.Threads {
+				for _, comment := range thread.Comments {
+					location := comment.Path
+					if line := comment.Line; line != nil {
+						location = fmt.Sprintf("%s:%d", comment.Path, *line)
+					}
+					fmt.Fprintf(w, "  %s @%s:\n", location, comment.User.Login)
+					writeQuoted(w, "    ", comment.Body)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeQuoted writes each line of body with the given prefix
+func writeQuoted(w io.Writer, prefix string, body string) {
+	if body == "" {
+		return
+	}
+	for _, line := range strings.Split(sanitizeForTerminal(body), "\n") {
+		fmt.Fprintf(w, "%s%s\n", prefix, line)
+	}
+}
+
+// RenderSquashPreview writes the commit message GitHub proposes by default
+// when squash merging the PR: the PR title and number as the subject, and
+// each commit's message as a bullet in the body
+func RenderSquashPreview(w io.Writer, pr PullRequest) error {
+	fmt.Fprintf(w, "%s (#%d)\n", pr.Title, pr.Number)
+	for _, commit := range pr.Commits {
+		fmt.Fprintf(w, "\n* %s\n", commit.Message)
+		if commit.Body != "" {
+			fmt.Fprintf(w, "\n%s\n", commit.Body)
+		}
+	}
+	return nil
+}
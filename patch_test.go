@@ -0,0 +1,83 @@
+package prview_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestRenderPatch(t *testing.T) {
+	committed := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	pr := prview.PullRequest{
+		Number: 123,
+		Title:  "Test PR",
+		Commits: []prview.Commit{
+			{
+				SHA:       "0123456789abcdef0123456789abcdef01234567",
+				Message:   "Fix the widget",
+				Body:      "The widget was broken.",
+				Author:    prview.User{Login: "alice"},
+				CreatedAt: committed,
+				Diff:      "diff --git a/widget.go b/widget.go\n--- a/widget.go\n+++ b/widget.go\n@@ -1 +1 @@\n-broken\n+fixed\n",
+			},
+		},
+		Comments: []prview.Comment{
+			{
+				ID:        1,
+				Body:      "Thanks for fixing this",
+				CreatedAt: committed.Add(time.Hour),
+				User:      prview.User{Login: "bob"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPatch(&buf, pr); err != nil {
+		t.Fatalf("RenderPatch returned an error: %v", err)
+	}
+	output := buf.String()
+
+	if !strings.HasPrefix(output, "From 0123456789abcdef0123456789abcdef01234567 Mon Sep 17 00:00:00 2001\n") {
+		t.Errorf("Expected output to start with the mbox From line, got:\n%s", output)
+	}
+
+	expectedStrings := []string{
+		"From: alice\n",
+		"Date: Tue, 2 Jan 2024 15:04:05 +0000\n",
+		"Subject: [PATCH] Fix the widget\n\nThe widget was broken.\n\n---\n",
+		"-broken\n+fixed\n-- \n",
+		"bob COMMENTED at 2024-01-02 16:04:05:\n  Thanks for fixing this\n",
+	}
+	for _, expected := range expectedStrings {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestRenderSquashPreview(t *testing.T) {
+	pr := prview.PullRequest{
+		Number: 123,
+		Title:  "Add widgets",
+		Commits: []prview.Commit{
+			{SHA: "aaa", Message: "Add the widget type", Body: "Widgets hold the frobnicator state."},
+			{SHA: "bbb", Message: "Fix widget tests"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderSquashPreview(&buf, pr); err != nil {
+		t.Fatalf("RenderSquashPreview returned an error: %v", err)
+	}
+
+	expected := "Add widgets (#123)\n" +
+		"\n* Add the widget type\n" +
+		"\nWidgets hold the frobnicator state.\n" +
+		"\n* Fix widget tests\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,55 @@
+package prview
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/repository"
+)
+
+// pendingState is the state of a review that hasn't been submitted yet.
+// GitHub only ever lists the authenticated user's own pending review.
+const pendingState = "PENDING"
+
+// fetchPendingComments retrieves the comments of the pending review among
+// reviews, if there is one. They are missing from the PR's list of review
+// comments until the review is submitted.
+func fetchPendingComments(ctx context.Context, client *api.RESTClient, repo repository.Repository, prNumber int, reviews []Review) ([]Comment, error) {
+	for _, review := range reviews {
+		if review.State == pendingState {
+			return FetchReviewComments(ctx, client, repo, prNumber, review.ID)
+		}
+	}
+	return nil, nil
+}
+
+// takePendingReview removes the pending review from reviews, returning the
+// remaining reviews and the pending one, or nil if there isn't one
+func takePendingReview(reviews []Review) ([]Review, *Review) {
+	for i, review := range reviews {
+		if review.State == pendingState {
+			rest := append(reviews[:i:i], reviews[i+1:]...)
+			return rest, &review
+		}
+	}
+	return reviews, nil
+}
+
+// renderPendingReview writes the viewer's unsubmitted review as a section
+// of its own
+func renderPendingReview(w io.Writer, review Review, opts RenderOptions) {
+	comments := 0
+	for _, thread := range review.Threads {
+		comments += len(thread.Comments)
+	}
+	fmt.Fprintf(w, "Your pending review: %s\n", pluralize(comments, "comment"))
+	fmt.Fprintln(w, opts.rule("="))
+
+	if review.Body != "" {
+		fmt.Fprintln(w, bodyText(review.Body, opts))
+	}
+	renderThreads(w, review.Threads, opts)
+	fmt.Fprintln(w, opts.rule("-"))
+}
@@ -0,0 +1,64 @@
+package prview_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestLoadPRPendingReview(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7":           `{"number": 7, "title": "Loaded PR", "user": {"login": "author"}}`,
+		"/repos/owner/repo/issues/7/comments": `[]`,
+		"/repos/owner/repo/pulls/7/reviews": `[
+			{"id": 10, "state": "APPROVED", "body": "Ship it", "submitted_at": "2024-01-01T10:00:00Z", "user": {"login": "bob"}},
+			{"id": 11, "state": "PENDING", "user": {"login": "me"}}
+		]`,
+		"/repos/owner/repo/pulls/7/comments": `[]`,
+		"/repos/owner/repo/pulls/7/reviews/11/comments": `[
+			{"id": 5, "body": "Still thinking about this", "path": "a.go", "diff_hunk": "@@ -1 +1 @@\n+x", "pull_request_review_id": 11, "user": {"login": "me"}}
+		]`,
+		"/repos/owner/repo/pulls/7/commits": `[]`,
+	}}
+
+	pr, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+	if pr.PendingReview == nil || pr.PendingReview.ID != 11 {
+		t.Fatalf("Expected review 11 to be the pending review, got %+v", pr.PendingReview)
+	}
+	if len(pr.Reviews) != 1 || pr.Reviews[0].ID != 10 {
+		t.Errorf("Expected the pending review out of the timeline, got %+v", pr.Reviews)
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output := buf.String()
+	section := strings.Index(output, "Your pending review: 1 comment\n====")
+	if section < 0 {
+		t.Fatalf("Expected a pending review section, got:\n%s", output)
+	}
+	if comment := strings.Index(output, "Still thinking about this"); comment < section {
+		t.Errorf("Expected the pending comment in its section, got:\n%s", output)
+	}
+	if strings.Contains(output, "PENDING") {
+		t.Errorf("Didn't expect the pending review in the timeline, got:\n%s", output)
+	}
+}
+
+func TestRenderPRWithoutPendingReview(t *testing.T) {
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, createMockPR(), prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if strings.Contains(buf.String(), "pending review") {
+		t.Errorf("Didn't expect a pending review section, got:\n%s", buf.String())
+	}
+}
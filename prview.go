@@ -1,12 +1,19 @@
 package prview
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"net/http"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/repository"
 )
 
 type TimelineItem struct {
@@ -15,47 +22,302 @@ type TimelineItem struct {
 	Comment   *Comment
 	Review    *Review
 	Commit    *Commit
+	Reference *CrossReference
+}
+
+// LoadOptions controls where LoadPR looks for the pull request
+type LoadOptions struct {
+	// Repo overrides the repository detected from the current directory,
+	// in [HOST/]OWNER/REPO form
+	Repo string
+	// Branch selects the open PR for this branch instead of the one for the
+	// checked out branch when no PR number is given
+	Branch string
+	// SHA selects the PR that introduced this commit when no PR number is
+	// given
+	SHA string
+	// ContextLines is the number of lines of file content to fetch either
+	// side of each review thread's line, or zero to skip fetching
+	ContextLines int
+	// CommitDiffs fetches the diff introduced by each commit
+	CommitDiffs bool
+	// Files fetches the files the PR changes, with their diffs, even when
+	// there are no review threads to annotate with renames
+	Files bool
+	// Transport, when set, is used to make the API requests instead of
+	// the default cached client
+	Transport http.RoundTripper
+	// Client, when set, makes the REST requests instead of a client built
+	// from the other options. Reusing one keeps what is memoized per client,
+	// like CurrentUser, across calls.
+	Client *api.RESTClient
+	// Requests, when set, counts the API requests made
+	Requests *RequestCounter
+	// AuthToken, when set, is used instead of the token from the gh
+	// environment, e.g. a GitHub App installation token
+	AuthToken string
+	// APIURL overrides the base URL of the API, e.g. for a proxy that
+	// serves it under a path prefix. It defaults to $GH_API_URL.
+	APIURL string
+	// RequestTimeout limits how long each individual API request may take.
+	// The overall time allowed is controlled by the context.
+	RequestTimeout time.Duration
+	// PerPage is the page size of the listings fetched page by page: the
+	// changed files, and through GraphQL, reviews and review threads. It is
+	// clamped to between 1 and 100, GitHub's maximum, which zero defaults
+	// to. Smaller pages make smaller responses but more requests.
+	PerPage int
+	// ExpandHunk, when positive, widens each review thread's diff hunk with
+	// up to this many more lines either side from the PR's diff
+	ExpandHunk int
+	// OutdatedCurrent fetches, for each outdated review thread, the lines
+	// at the head commit where the thread's code used to be
+	OutdatedCurrent bool
+	// Progress, when set, is called with a short description of each phase
+	// of loading as it begins, e.g. "fetching reviews"
+	Progress func(phase string)
+	// Exclude skips fetching the kinds of discussion it leaves out
+	Exclude Exclude
+	// ReactionDetails fetches who left each reaction on every comment that
+	// has any, at the cost of a request per comment
+	ReactionDetails bool
+	// ResolveTeams loads the members of the teams the PR mentions, for
+	// text output to follow each @org/team mention with
+	ResolveTeams bool
+	// GraphQL loads the reviews and their comments with one GraphQL query
+	// rather than a REST request for each
+	GraphQL bool
+	// Limit, when positive, caps the number of issue comments and the number
+	// of reviews loaded, stopping partway through the API response
+	Limit int
+	// References loads the issues and PRs elsewhere that mention the PR
+	References bool
+}
+
+// progress returns opts.Progress, or a no-op when it isn't set
+func (opts LoadOptions) progress() func(string) {
+	if opts.Progress == nil {
+		return func(string) {}
+	}
+	return opts.Progress
+}
+
+// restClient returns opts.Client, or else the REST client described by opts
+// for host, the host of the repository being read, or the gh environment's
+// default when empty
+func (opts LoadOptions) restClient(host string) (*api.RESTClient, error) {
+	if opts.Client != nil {
+		return opts.Client, nil
+	}
+	rt, err := opts.transport()
+	if err != nil {
+		return nil, err
+	}
+	client, err := newRESTClient(rt, opts.RequestTimeout, opts.AuthToken, host)
+	if err != nil {
+		return nil, fmt.Errorf("error creating GitHub client: %w", err)
+	}
+	return client, nil
 }
 
-func LoadPR(prNumber int) (PullRequest, error) {
-	repo, err := GetCurrentRepo()
+// graphQLClient returns a GraphQL client configured like restClient's
+func (opts LoadOptions) graphQLClient(host string) (*api.GraphQLClient, error) {
+	rt, err := opts.transport()
 	if err != nil {
-		return PullRequest{}, fmt.Errorf("error getting repository information: %w", err)
+		return nil, err
 	}
+	return newGraphQLClient(rt, opts.RequestTimeout, opts.AuthToken, host)
+}
 
-	client, err := GetRESTClient()
+// connect resolves the repository and API client described by opts, and the
+// PR number for the current branch when prNumber is zero
+func connect(ctx context.Context, prNumber int, opts LoadOptions) (*api.RESTClient, repository.Repository, int, error) {
+	repo, err := ResolveRepo(opts.Repo)
 	if err != nil {
-		return PullRequest{}, fmt.Errorf("error creating GitHub client: %w", err)
+		return nil, repository.Repository{}, 0, err
+	}
+
+	client, err := opts.restClient(repo.Host)
+	if err != nil {
+		return nil, repository.Repository{}, 0, err
 	}
 
 	if prNumber == 0 {
-		prNumber, err = GetCurrentPR(client, repo)
+		if opts.SHA != "" {
+			prNumber, err = FindPRForCommit(ctx, client, repo, opts.SHA)
+			if err == nil && prNumber == 0 {
+				err = tagError(ErrPRNotFound, "no PR found for commit: %s", opts.SHA)
+			}
+		} else if opts.Branch != "" {
+			prNumber, err = FindPRForBranch(ctx, client, repo, opts.Branch)
+			if err == nil && prNumber == 0 {
+				err = tagError(ErrPRNotFound, "no open PR found for branch: %s", opts.Branch)
+			}
+		} else {
+			prNumber, err = GetCurrentPR(ctx, client, repo)
+		}
 		if err != nil {
-			return PullRequest{}, fmt.Errorf("error determining PR number: %w", err)
+			return nil, repository.Repository{}, 0, fmt.Errorf("error determining PR number: %w", err)
 		}
 	}
 
-	pr, err := FetchPR(client, repo, prNumber)
+	return client, repo, prNumber, nil
+}
+
+func LoadPR(ctx context.Context, prNumber int, opts LoadOptions) (PullRequest, error) {
+	client, repo, prNumber, err := connect(ctx, prNumber, opts)
+	if err != nil {
+		return PullRequest{}, err
+	}
+
+	progress := opts.progress()
+
+	progress(fmt.Sprintf("fetching PR #%d", prNumber))
+	pr, err := FetchPR(ctx, client, repo, prNumber)
 	if err != nil {
 		return PullRequest{}, fmt.Errorf("error fetching PR #%d: %w", prNumber, err)
 	}
+	if len(ParseClosingRefs(pr.Body)) > 0 {
+		progress("fetching linked issues")
+	}
+	if pr.Closes, err = fetchClosedIssues(ctx, client, repo, pr.Body); err != nil {
+		return PullRequest{}, err
+	}
+
+	if !opts.Exclude.IssueComments {
+		progress("fetching comments")
+		comments, err := FetchPRComments(ctx, client, repo, prNumber, opts.Limit)
+		if err != nil {
+			return PullRequest{}, fmt.Errorf("error fetching comments for PR #%d: %w", prNumber, err)
+		}
+		pr.Comments = comments
+	}
 
-	comments, err := FetchPRComments(client, repo, prNumber)
+	reviews, reviewComments, err := fetchReviews(ctx, client, repo, prNumber, opts)
 	if err != nil {
-		return PullRequest{}, fmt.Errorf("error fetching comments for PR #%d: %w", prNumber, err)
+		return PullRequest{}, err
 	}
-	pr.Comments = comments
 
-	reviews, err := FetchPRReviews(client, repo, prNumber)
+	attachThreads(reviews, reviewComments)
+	progress("fetching review thread status")
+	pr.ReviewDecision = addReviewStatus(ctx, repo, prNumber, reviews, opts)
+	if opts.Files || hasThreads(reviews) {
+		progress("fetching changed files")
+		files, err := FetchPRFiles(ctx, client, repo, prNumber, opts.PerPage)
+		if err != nil && opts.Files {
+			return PullRequest{}, fmt.Errorf("error fetching files for PR #%d: %w", prNumber, err)
+		}
+		// Otherwise the files only annotate renamed threads and widen their
+		// hunks, so carry on without them if they can't be fetched
+		if err == nil {
+			pr.Files = files
+			markRenamedFiles(reviews, files)
+			if opts.ExpandHunk > 0 {
+				expandHunks(reviews, files, opts.ExpandHunk)
+			}
+		}
+	}
+	if opts.ContextLines > 0 {
+		progress("fetching file context")
+		addFileContext(ctx, client, repo, reviews, pr.Head.SHA, opts.ContextLines)
+	}
+	if opts.OutdatedCurrent {
+		n := opts.ContextLines
+		if n <= 0 {
+			n = defaultOutdatedLines
+		}
+		progress("fetching current code for outdated threads")
+		addCurrentContext(ctx, client, repo, reviews, pr.Head.SHA, n)
+	}
+	pr.Reviews, pr.PendingReview = takePendingReview(reviews)
+
+	progress("fetching commits")
+	commits, err := FetchCommits(ctx, client, repo, prNumber)
 	if err != nil {
-		return PullRequest{}, fmt.Errorf("error fetching reviews for PR #%d: %w", prNumber, err)
+		return PullRequest{}, fmt.Errorf("error fetching commits for PR #%d: %w", prNumber, err)
+	}
+	for i := range commits {
+		progress(fmt.Sprintf("fetching checks for commit %d/%d", i+1, len(commits)))
+		commits[i].Checks = FetchCommitChecks(ctx, client, repo, commits[i].SHA)
+		if opts.CommitDiffs {
+			progress(fmt.Sprintf("fetching diff for commit %d/%d", i+1, len(commits)))
+			commits[i].Diff, err = FetchCommitDiff(ctx, client, repo, commits[i].SHA)
+			if err != nil {
+				return PullRequest{}, fmt.Errorf("error fetching diff for commit %s: %w", commits[i].SHA, err)
+			}
+		}
+	}
+	pr.Commits = commits
+
+	if opts.References {
+		progress("fetching cross-references")
+		pr.References, err = FetchCrossReferences(ctx, client, repo, prNumber)
+		if err != nil {
+			return PullRequest{}, fmt.Errorf("error fetching cross-references for PR #%d: %w", prNumber, err)
+		}
+	}
+	if opts.ReactionDetails {
+		progress("fetching reactions")
+		if err := addReactionUsers(ctx, client, repo, &pr); err != nil {
+			return PullRequest{}, err
+		}
+	}
+	if opts.ResolveTeams {
+		progress("fetching team members")
+		if err := resolveTeamMentions(ctx, client, &pr); err != nil {
+			return PullRequest{}, err
+		}
 	}
 
-	reviewComments, err := FetchAllReviewComments(client, repo, prNumber)
+	return pr, nil
+}
+
+// fetchReviews retrieves the reviews and review comments of a PR, either
+// through GraphQL or with REST requests for each depending on opts
+func fetchReviews(ctx context.Context, client *api.RESTClient, repo repository.Repository, prNumber int, opts LoadOptions) ([]Review, []Comment, error) {
+	progress := opts.progress()
+	if opts.Exclude.ReviewSummaries && opts.Exclude.InlineComments {
+		return nil, nil, nil
+	}
+
+	if opts.GraphQL {
+		progress("fetching reviews and review comments")
+		gql, err := opts.graphQLClient(repo.Host)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error creating GitHub client: %w", err)
+		}
+		reviews, comments, err := FetchReviewsWithComments(ctx, gql, repo, prNumber, opts.Limit, opts.PerPage)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error fetching reviews for PR #%d: %w", prNumber, err)
+		}
+		return reviews, comments, nil
+	}
+
+	progress("fetching reviews")
+	reviews, err := FetchPRReviews(ctx, client, repo, prNumber, opts.Limit)
 	if err != nil {
-		return PullRequest{}, fmt.Errorf("error fetching review comments for PR #%d: %w", prNumber, err)
+		return nil, nil, fmt.Errorf("error fetching reviews for PR #%d: %w", prNumber, err)
+	}
+
+	if opts.Exclude.InlineComments {
+		return reviews, nil, nil
 	}
 
+	progress("fetching review comments")
+	comments, err := FetchAllReviewComments(ctx, client, repo, prNumber)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching review comments for PR #%d: %w", prNumber, err)
+	}
+	pending, err := fetchPendingComments(ctx, client, repo, prNumber, reviews)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching your pending review comments for PR #%d: %w", prNumber, err)
+	}
+	return reviews, append(comments, pending...), nil
+}
+
+// attachThreads threads the review comments and files each thread, along
+// with a count of the replies, under the review it was posted in
+func attachThreads(reviews []Review, reviewComments []Comment) {
 	threads := groupIntoThreads(reviewComments)
 	threadsByReview := make(map[int64][]CommentThread)
 	for _, thread := range threads {
@@ -76,18 +338,43 @@ func LoadPR(prNumber int) (PullRequest, error) {
 		reviews[i].Threads = threadsByReview[reviews[i].ID]
 		reviews[i].ReplyCount = replyCountByReview[reviews[i].ID]
 	}
-	pr.Reviews = reviews
+}
 
-	commits, err := FetchCommits(client, repo, prNumber)
+// addReviewStatus flags the threads that have been resolved and returns
+// the PR's review decision, which come from the same query. Both only
+// decorate the output, so if they can't be fetched, e.g. from an older
+// GitHub Enterprise Server, threads are left unresolved and there is no
+// decision.
+func addReviewStatus(ctx context.Context, repo repository.Repository, prNumber int, reviews []Review, opts LoadOptions) string {
+	client, err := opts.graphQLClient(repo.Host)
 	if err != nil {
-		return PullRequest{}, fmt.Errorf("error fetching commits for PR #%d: %w", prNumber, err)
+		return ""
 	}
-	for i := range commits {
-		commits[i].Checks = FetchCommitChecks(client, repo, commits[i].SHA)
+	status, err := FetchReviewStatus(ctx, client, repo, prNumber, opts.PerPage)
+	if err != nil {
+		return ""
 	}
-	pr.Commits = commits
 
-	return pr, nil
+	for i := range reviews {
+		for j := range reviews[i].Threads {
+			thread := &reviews[i].Threads[j]
+			thread.Resolved = status.Resolved[thread.Comments[0].ID]
+		}
+	}
+	return status.Decision
+}
+
+// chronological returns a copy of comments ordered oldest first, with ties
+// broken by ID, which GitHub assigns in order
+func chronological(comments []Comment) []Comment {
+	sorted := append([]Comment(nil), comments...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted
 }
 
 func groupIntoThreads(comments []Comment) []CommentThread {
@@ -122,35 +409,92 @@ func groupIntoThreads(comments []Comment) []CommentThread {
 
 	var threads []CommentThread
 	for _, rootID := range rootIDs {
-		replies := rootToReplies[rootID]
-		sort.Slice(replies, func(i, j int) bool {
-			return replies[i].CreatedAt.Before(replies[j].CreatedAt)
-		})
-		threads = append(threads, CommentThread{Comments: replies})
+		threads = append(threads, CommentThread{Comments: chronological(rootToReplies[rootID])})
 	}
 
 	return threads
 }
 
-func RenderPR(w io.Writer, pr PullRequest) error {
-	headerTmpl := `PR #{{ .Number }}: {{ .Title }}
-Author: {{ .User.Login }}
-Created: {{ .CreatedAt.Format "2006-01-02 15:04:05" }}
+// PostReply replies to a review comment on the PR, locating the repository
+// and PR the same way as LoadPR
+func PostReply(ctx context.Context, prNumber int, commentID int64, body string, opts LoadOptions) (Comment, error) {
+	client, repo, prNumber, err := connect(ctx, prNumber, opts)
+	if err != nil {
+		return Comment{}, err
+	}
 
-{{ .Body }}
-`
-	tmpl, err := template.New("pr-header").Parse(headerTmpl)
+	reply, err := ReplyToReviewComment(ctx, client, repo, prNumber, commentID, body)
 	if err != nil {
-		return fmt.Errorf("error creating template: %w", err)
+		return Comment{}, fmt.Errorf("error replying to comment %d on PR #%d: %w", commentID, prNumber, err)
 	}
+	return reply, nil
+}
 
-	err = tmpl.Execute(w, pr)
+// CurrentLogin returns the login of the user opts authenticate as, on the
+// host of the repository opts name, or the default host when there is none.
+// It is only remembered across calls when opts.Client is set.
+func CurrentLogin(ctx context.Context, opts LoadOptions) (string, error) {
+	repo, _ := ResolveRepo(opts.Repo)
+	client, err := opts.restClient(repo.Host)
 	if err != nil {
-		return fmt.Errorf("error rendering PR header: %w", err)
+		return "", err
+	}
+	user, err := CurrentUser(ctx, client)
+	if err != nil {
+		return "", fmt.Errorf("error fetching the authenticated user: %w", err)
 	}
+	return user.Login, nil
+}
+
+// addFileContext attaches up to n lines either side of each thread's
+// position in the file at the head commit. Outdated threads no longer have a
+// line at head, so their context comes from the commit they were left on.
+// Each file is fetched at most once per ref.
+func addFileContext(ctx context.Context, client *api.RESTClient, repo repository.Repository, reviews []Review, headSHA string, n int) {
+	files := make(map[string][]string)
+
+	for i := range reviews {
+		for j := range reviews[i].Threads {
+			root := &reviews[i].Threads[j].Comments[0]
+			if root.Path == "" {
+				continue
+			}
 
-	fmt.Fprintln(w, strings.Repeat("-", 80))
+			ref, line := headSHA, root.Line
+			if line == nil {
+				ref, line = root.OriginalCommitID, root.OriginalLine
+			}
+			if line == nil || ref == "" {
+				continue
+			}
 
+			key := ref + ":" + root.Path
+			lines, ok := files[key]
+			if !ok {
+				// Files that can't be fetched are cached as empty so they
+				// aren't requested again
+				lines, _ = FetchFileLines(ctx, client, repo, root.Path, ref)
+				files[key] = lines
+			}
+			if *line < 1 || *line > len(lines) {
+				continue
+			}
+
+			start := max(*line-n, 1)
+			end := min(*line+n, len(lines))
+			root.FileContext = &FileContext{
+				Ref:       ref,
+				StartLine: start,
+				Line:      *line,
+				Lines:     lines[start-1 : end],
+			}
+		}
+	}
+}
+
+// buildTimeline merges the PR's comments, reviews and commits into a single
+// chronologically ordered list
+func buildTimeline(pr PullRequest) []TimelineItem {
 	var timeline []TimelineItem
 
 	for i := range pr.Comments {
@@ -180,31 +524,448 @@ Created: {{ .CreatedAt.Format "2006-01-02 15:04:05" }}
 		})
 	}
 
+	for i := range pr.References {
+		ref := pr.References[i]
+		timeline = append(timeline, TimelineItem{
+			Type:      "reference",
+			CreatedAt: ref.CreatedAt,
+			Reference: &ref,
+		})
+	}
+
 	sort.Slice(timeline, func(i, j int) bool {
 		return timeline[i].CreatedAt.Before(timeline[j].CreatedAt)
 	})
 
+	return timeline
+}
+
+// flattenTimeline lifts review comments out of their reviews into timeline
+// items of their own, ordered by when each comment was written. Reviews
+// that were only containers for inline comments are dropped.
+func flattenTimeline(timeline []TimelineItem) []TimelineItem {
+	var flat []TimelineItem
 	for _, item := range timeline {
-		if item.Type == "comment" {
-			renderIssueComment(w, *item.Comment)
-		} else if item.Type == "review" {
-			renderReview(w, *item.Review)
-		} else if item.Type == "commit" {
-			renderCommit(w, *item.Commit)
+		if item.Type != "review" {
+			flat = append(flat, item)
+			continue
+		}
+
+		review := *item.Review
+		for _, thread := range review.Threads {
+			for i := range thread.Comments {
+				comment := thread.Comments[i]
+				flat = append(flat, TimelineItem{
+					Type:      "review_comment",
+					CreatedAt: comment.CreatedAt,
+					Comment:   &comment,
+				})
+			}
+		}
+		if review.Body == "" && review.State == "COMMENTED" {
+			continue
+		}
+		review.Threads = nil
+		review.ReplyCount = 0
+		flat = append(flat, TimelineItem{
+			Type:      "review",
+			CreatedAt: review.SubmittedAt,
+			Review:    &review,
+		})
+	}
+
+	sort.SliceStable(flat, func(i, j int) bool {
+		return flat[i].CreatedAt.Before(flat[j].CreatedAt)
+	})
+
+	return flat
+}
+
+// RenderOptions controls how Render presents the PR
+type RenderOptions struct {
+	// Format selects the renderer: text (the default), json, jsonl, patch,
+	// html, csv or files, or auto to pick for the writer, as ResolveFormat
+	// does
+	Format string
+	// NoBody replaces bodies with their length in JSON output
+	NoBody bool
+	// JSONCompact writes JSON on a single line instead of indenting it
+	JSONCompact bool
+	// Indent is the number of spaces per level of nesting in text output,
+	// defaulting to two
+	Indent int
+	// CompactDiff drops unchanged context lines from diff hunks, leaving
+	// just the hunk headers and the added and removed lines
+	CompactDiff bool
+	// CommentTasks includes task list items in issue comments in the
+	// header's task progress, not just those in the PR body
+	CommentTasks bool
+	// GroupByAuthor renders a section per person, most active first, in
+	// place of the timeline
+	GroupByAuthor bool
+	// MaxWidth, when positive, wraps text output so no line is wider than
+	// this many characters, margin included
+	MaxWidth int
+	// Margin is the number of spaces to the left of every line of text
+	// output
+	Margin int
+	// ExpandAll renders the collapsible sections of HTML output, resolved
+	// threads and long diffs, open
+	ExpandAll bool
+	// PlainDiff renders diff hunks as bare code, without hunk headers or
+	// the +, - and space markers at the start of each line
+	PlainDiff bool
+	// Template, when set, renders the PR in place of Format, as
+	// RenderTemplate does
+	Template *template.Template
+	// Exclude leaves kinds of discussion out of the text timeline
+	Exclude Exclude
+	// Highlight, when set, marks the text it matches in the bodies, commit
+	// messages and reference titles of text output, as »match«
+	Highlight *regexp.Regexp
+	// Latency ends text output with how long each reviewer took to first
+	// review the PR
+	Latency bool
+	// Digest replaces text output with a line for each timeline item, as
+	// RenderDigest writes
+	Digest bool
+	// Recap ends text output with each reviewer's final review state and
+	// number of inline comments
+	Recap bool
+	// RelativeTimes shows times in text output relative to now, e.g.
+	// "2 hours ago", instead of as dates
+	RelativeTimes bool
+	// BodyLines, when positive, truncates the PR body in the text header
+	// to this many lines
+	BodyLines int
+	// DiffLineNumbers prefixes each line of a diff hunk with its line
+	// number in the old and new versions of the file
+	DiffLineNumbers bool
+	// FlatTimeline places each review comment in the timeline by its own
+	// creation time instead of nesting it under its review
+	FlatTimeline bool
+	// HeaderOneline shows the PR header as a single line, as headerLine
+	// writes it, in place of the full header and body
+	HeaderOneline bool
+	// NoHeader leaves the PR header out of text output, rendering just
+	// the timeline
+	NoHeader bool
+	// MaxIndent, when positive, stops nesting replies to replies in text
+	// output this many levels deep, marking deeper replies with their depth
+	MaxIndent int
+	// Columns, when above one, lays the timeline out across this many
+	// columns of text output, each column wrapped to its share of MaxWidth
+	Columns int
+	// Coalesce merges issue comments and reviews into one block when the
+	// same person posted them within a couple of minutes of each other
+	Coalesce bool
+	// RoundGap, when positive, splits the timeline into review rounds
+	// wherever reviews are more than this far apart, and marks where each
+	// round starts
+	RoundGap time.Duration
+	// Glyphs overrides the symbols shown before each review state, keyed by
+	// state, e.g. "APPROVED"
+	Glyphs map[string]string
+	// ASCII shows review states with ASCII symbols such as [+] instead
+	ASCII bool
+	// ShowEmails adds the name and email each commit was authored with
+	// under it in text output
+	ShowEmails bool
+	// ShowNoreply, with ShowEmails, includes GitHub's noreply addresses,
+	// which are otherwise left out
+	ShowNoreply bool
+	// StaleDays, when positive, flags the PR in the header as stale if
+	// nothing has happened on it for more than this many days
+	StaleDays int
+}
+
+// indent returns one level of text output indentation
+func (opts RenderOptions) indent() string {
+	if opts.Indent <= 0 {
+		return "  "
+	}
+	return strings.Repeat(" ", opts.Indent)
+}
+
+// Render writes the PR to w in the format selected by opts. Rendering only
+// reads the PR and keeps no state between calls, so the same PR may be
+// rendered to distinct writers from several goroutines at once.
+func Render(w io.Writer, pr PullRequest, opts RenderOptions) error {
+	if opts.Template != nil {
+		return RenderTemplate(w, pr, opts.Template, opts)
+	}
+	switch opts.Format {
+	case "", "text":
+		if opts.Digest {
+			return RenderDigest(w, pr, opts)
 		}
-		fmt.Fprintln(w, strings.Repeat("-", 80))
+		return RenderPR(w, pr, opts)
+	case "auto":
+		return Render(w, pr, ResolveFormat(w, opts))
+	case "json":
+		return RenderJSON(w, pr, opts)
+	case "jsonl":
+		return RenderJSONLines(w, pr, opts)
+	case "patch":
+		return RenderPatch(w, pr)
+	case "html":
+		return RenderHTML(w, pr, opts)
+	case "csv":
+		return RenderCSV(w, pr, opts)
+	case "files":
+		return RenderFiles(w, pr, opts)
+	default:
+		return fmt.Errorf("unknown format %q", opts.Format)
+	}
+}
+
+// mergeability describes whether the PR can be merged, for the header. It is
+// empty unless there is something to flag: conflicts, or GitHub still working
+// it out, which it reports as a null mergeable with an "unknown" state.
+func mergeability(pr PullRequest) string {
+	switch {
+	case pr.Mergeable == nil && pr.MergeableState == "unknown":
+		return "Mergeability: computing..."
+	case pr.Mergeable != nil && !*pr.Mergeable && pr.MergeableState == "dirty":
+		return "⚠ This PR has merge conflicts"
+	}
+	return ""
+}
+
+// headerLine sums the PR up in one line, e.g.
+// "#123 [OPEN] Fix the parser — alice, 2024-01-02"
+func headerLine(pr PullRequest) string {
+	return fmt.Sprintf("#%d [%s] %s — %s, %s", pr.Number, prState(pr), sanitizeForTerminal(pr.Title), sanitizeForTerminal(pr.User.Login), pr.CreatedAt.Format("2006-01-02"))
+}
+
+// prState is the PR's state as GitHub labels it: MERGED, CLOSED, DRAFT or
+// OPEN
+func prState(pr PullRequest) string {
+	switch {
+	case pr.MergedAt != nil:
+		return "MERGED"
+	case pr.State == "closed":
+		return "CLOSED"
+	case pr.Draft:
+		return "DRAFT"
+	}
+	return "OPEN"
+}
+
+// firstTimeContributor reports whether the PR is its author's first
+// contribution to the repository, or to GitHub as a whole
+func firstTimeContributor(pr PullRequest) bool {
+	return pr.AuthorAssociation == "FIRST_TIME_CONTRIBUTOR" || pr.AuthorAssociation == "FIRST_TIMER"
+}
+
+// merged describes who merged the PR, when and as what commit, or is empty
+// if it hasn't been merged
+func merged(pr PullRequest, opts RenderOptions) string {
+	if pr.MergedAt == nil {
+		return ""
+	}
+	line := "Merged"
+	if pr.MergedBy != nil {
+		line += " by " + sanitizeForTerminal(pr.MergedBy.Login)
+	}
+	line += " at " + opts.timestamp(*pr.MergedAt)
+	if pr.MergeCommitSHA != "" {
+		line += " as " + shortSHA(pr.MergeCommitSHA)
+	}
+	return line
+}
+
+// RenderPR writes the PR header followed by its timeline as text
+func RenderPR(w io.Writer, pr PullRequest, opts RenderOptions) error {
+	return renderFitted(w, opts, func(w io.Writer) error {
+		return renderPR(w, pr, opts)
+	})
+}
+
+func renderPR(w io.Writer, pr PullRequest, opts RenderOptions) error {
+	pr = withTeamMembers(pr)
+	if opts.HeaderOneline && !opts.NoHeader {
+		fmt.Fprintln(w, headerLine(pr))
+		fmt.Fprintln(w, opts.rule("-"))
+	} else if !opts.NoHeader {
+		if err := renderHeader(w, pr, opts); err != nil {
+			return err
+		}
+		fmt.Fprintln(w, opts.rule("-"))
+	}
+
+	if pr.PendingReview != nil {
+		renderPendingReview(w, *pr.PendingReview, opts)
+	}
+	if opts.GroupByAuthor {
+		renderByAuthor(w, excludeCategories(buildTimeline(pr), opts.Exclude), opts)
+	} else {
+		renderTimeline(w, Timeline(pr, opts), opts)
+	}
+	if opts.Recap {
+		renderRecap(w, pr, opts)
+	}
+	if opts.Latency {
+		if opts.Recap {
+			fmt.Fprintln(w)
+		}
+		renderLatencies(w, pr, opts)
 	}
 
 	return nil
 }
 
-func renderIssueComment(w io.Writer, comment Comment) {
-	fmt.Fprintf(w, "%s COMMENTED at %s\n\n", comment.User.Login, comment.CreatedAt.Format("2006-01-02 15:04:05"))
-	fmt.Fprintln(w, comment.Body)
+// renderHeader writes the PR title, metadata and body
+func renderHeader(w io.Writer, pr PullRequest, opts RenderOptions) error {
+	headerTmpl := `PR #{{ .Number }}: {{ .Title }}
+{{- with .Mergeability }}
+{{ . }}
+{{- end }}
+Author: {{ .User.Login }}
+{{- if .FirstTimer }}
+👋 First-time contributor
+{{- end }}
+{{- with .Merged }}
+{{ . }}
+{{- end }}
+{{- with .ReviewDecision }}
+Review decision: {{ . }}
+{{- end }}
+{{- with .CoAuthors }}
+Co-authors: {{ join . ", " }}
+{{- end }}
+{{- with .Closes }}
+Closes: {{ join . ", " }}
+{{- end }}
+{{- with .Range }}
+Commits: {{ . }}
+{{- end }}
+{{- with .FilesTruncated }}
+(file list truncated at {{ . }} by GitHub)
+{{- end }}
+{{- if .Tasks.Total }}
+Tasks: {{ .Tasks.Done }}/{{ .Tasks.Total }} complete
+{{- end }}
+Created: {{ .Created }}
+{{- with .LastActivity }}
+Last activity: {{ . }}
+{{- end }}
+
+{{ .Body }}
+`
+	tmpl, err := template.New("pr-header").Funcs(template.FuncMap{"join": strings.Join}).Parse(headerTmpl)
+	if err != nil {
+		return fmt.Errorf("error creating template: %w", err)
+	}
+
+	header := struct {
+		PullRequest
+		CoAuthors      []string
+		Closes         []string
+		Tasks          TaskProgress
+		Mergeability   string
+		Created        string
+		FilesTruncated int
+		Merged         string
+		LastActivity   string
+		FirstTimer     bool
+		Range          string
+	}{
+		PullRequest:    pr,
+		Tasks:          PRTasks(pr, opts.CommentTasks),
+		Mergeability:   mergeability(pr),
+		Created:        opts.timestamp(pr.CreatedAt),
+		FilesTruncated: filesTruncated(pr),
+		Merged:         merged(pr, opts),
+		LastActivity:   lastActivity(pr, opts),
+		FirstTimer:     firstTimeContributor(pr),
+		Range:          commitRange(pr),
+	}
+	header.Title = sanitizeForTerminal(pr.Title)
+	header.User.Login = sanitizeForTerminal(pr.User.Login)
+	header.ReviewDecision = sanitizeForTerminal(pr.ReviewDecision)
+	header.Body = truncateBody(bodyText(pr.Body, opts), opts.BodyLines)
+	for _, name := range CoAuthors(pr) {
+		header.CoAuthors = append(header.CoAuthors, sanitizeForTerminal(name))
+	}
+	for _, issue := range pr.Closes {
+		header.Closes = append(header.Closes, sanitizeForTerminal(strings.TrimSpace(issue.Ref.String()+" "+issue.Title)))
+	}
+	err = tmpl.Execute(w, header)
+	if err != nil {
+		return fmt.Errorf("error rendering PR header: %w", err)
+	}
+	return nil
+}
+
+// Timeline returns the PR's comments, reviews and commits in the order
+// RenderPR shows them, with review comments lifted out of their reviews when
+// opts.FlatTimeline is set
+func Timeline(pr PullRequest, opts RenderOptions) []TimelineItem {
+	timeline := excludeCategories(buildTimeline(pr), opts.Exclude)
+	if opts.FlatTimeline {
+		timeline = flattenTimeline(timeline)
+	}
+	return timeline
+}
+
+// RenderTimelineItem writes a single timeline item as text
+func RenderTimelineItem(w io.Writer, item TimelineItem, opts RenderOptions) error {
+	renderTimelineItem(w, item, opts)
+	return nil
 }
 
-func renderReview(w io.Writer, review Review) {
-	fmt.Fprintf(w, "%s %s at %s", review.User.Login, review.State, review.SubmittedAt.Format("2006-01-02 15:04:05"))
+func renderTimelineItem(w io.Writer, item TimelineItem, opts RenderOptions) {
+	if item.Type == "comment" {
+		renderIssueComment(w, *item.Comment, opts)
+	} else if item.Type == "review_comment" {
+		renderReviewComment(w, *item.Comment, opts)
+	} else if item.Type == "review" {
+		renderReview(w, *item.Review, opts)
+	} else if item.Type == "commit" {
+		renderCommit(w, *item.Commit, opts)
+	} else if item.Type == "reference" {
+		renderReference(w, *item.Reference, opts)
+	}
+}
+
+// RenderComment writes a single review comment along with the diff it
+// refers to
+func RenderComment(w io.Writer, comment Comment, opts RenderOptions) error {
+	renderThread(w, CommentThread{Comments: []Comment{comment}}, opts)
+	return nil
+}
+
+func renderIssueComment(w io.Writer, comment Comment, opts RenderOptions) {
+	fmt.Fprintf(w, "%s COMMENTED at %s\n\n", sanitizeForTerminal(comment.User.Login), opts.timestamp(comment.CreatedAt))
+	fmt.Fprintln(w, bodyText(comment.Body, opts))
+	if lines := reactionLines(comment.Reactions); len(lines) > 0 {
+		fmt.Fprintf(w, "\n%s\n", strings.Join(lines, "\n"))
+	}
+}
+
+// renderReviewComment writes a review comment as a timeline item of its own.
+// Replies leave out the diff, which their thread's first comment shows.
+func renderReviewComment(w io.Writer, comment Comment, opts RenderOptions) {
+	if comment.InReplyToID == nil {
+		renderThread(w, CommentThread{Comments: []Comment{comment}}, opts)
+		return
+	}
+	fmt.Fprintf(w, "%s REPLIED on %s at %s\n\n", sanitizeForTerminal(comment.User.Login), sanitizeForTerminal(comment.Path), opts.timestamp(comment.CreatedAt))
+	fmt.Fprintln(w, bodyText(comment.Body, opts))
+	if lines := reactionLines(comment.Reactions); len(lines) > 0 {
+		fmt.Fprintf(w, "\n%s\n", strings.Join(lines, "\n"))
+	}
+}
+
+// RenderReview writes a single review: its summary followed by its threads
+func RenderReview(w io.Writer, review Review, opts RenderOptions) error {
+	renderReview(w, review, opts)
+	return nil
+}
+
+func renderReview(w io.Writer, review Review, opts RenderOptions) {
+	fmt.Fprint(w, opts.withGlyph(review.State, fmt.Sprintf("%s %s at %s", sanitizeForTerminal(review.User.Login), review.State, opts.timestamp(review.SubmittedAt))))
 
 	if review.Body == "" && len(review.Threads) == 0 && review.ReplyCount > 0 {
 		noun := "comments"
@@ -218,16 +979,13 @@ func renderReview(w io.Writer, review Review) {
 
 	if review.Body != "" {
 		fmt.Fprintln(w)
-		fmt.Fprintln(w, review.Body)
+		fmt.Fprintln(w, bodyText(review.Body, opts))
 	}
 
-	for _, thread := range review.Threads {
-		fmt.Fprintln(w)
-		renderThread(w, thread)
-	}
+	renderThreads(w, review.Threads, opts)
 }
 
-func renderCommit(w io.Writer, commit Commit) {
+func renderCommit(w io.Writer, commit Commit, opts RenderOptions) {
 	shortSHA := commit.SHA
 	if len(shortSHA) > 7 {
 		shortSHA = shortSHA[:7]
@@ -238,7 +996,7 @@ func renderCommit(w io.Writer, commit Commit) {
 		author = "unknown"
 	}
 
-	fmt.Fprintf(w, "%s COMMITTED %s: %s", author, shortSHA, commit.Message)
+	fmt.Fprintf(w, "%s COMMITTED %s: %s", sanitizeForTerminal(author), shortSHA, markMatches(sanitizeForTerminal(commit.Message), opts.Highlight))
 
 	c := commit.Checks
 	total := c.Succeeded + c.Failed + c.Pending + c.Skipped
@@ -259,41 +1017,247 @@ func renderCommit(w io.Writer, commit Commit) {
 		fmt.Fprintf(w, " [%s]", strings.Join(parts, ", "))
 	}
 	fmt.Fprintln(w)
+
+	if opts.ShowEmails {
+		if author := commitAuthor(commit, opts.ShowNoreply); author != "" {
+			fmt.Fprintf(w, "%sAuthor: %s\n", opts.indent(), sanitizeForTerminal(author))
+		}
+	}
 }
 
-func renderThread(w io.Writer, thread CommentThread) {
+// renderThreads writes each of threads after a blank line. A thread on the
+// same hunk as the one before it shows a note in place of the hunk.
+func renderThreads(w io.Writer, threads []CommentThread, opts RenderOptions) {
+	var prev *Comment
+	for _, thread := range threads {
+		if len(thread.Comments) == 0 {
+			continue
+		}
+		fmt.Fprintln(w)
+		root := chronological(thread.Comments)[0]
+		renderThreadHunk(w, thread, prev != nil && sameHunk(*prev, root), opts)
+		prev = &root
+	}
+}
+
+func renderThread(w io.Writer, thread CommentThread, opts RenderOptions) {
+	renderThreadHunk(w, thread, false, opts)
+}
+
+// renderThreadHunk writes a thread, noting "(same hunk)" in place of its
+// diff hunk when repeated is set
+func renderThreadHunk(w io.Writer, thread CommentThread, repeated bool, opts RenderOptions) {
 	if len(thread.Comments) == 0 {
 		return
 	}
 
-	root := thread.Comments[0]
+	indent := opts.indent()
+
+	// Threads built elsewhere, e.g. read from stdin, may be in any order
+	comments := chronological(thread.Comments)
+	root := comments[0]
 
 	if root.DiffHunk != "" {
-		fmt.Fprintf(w, "  %s", root.Path)
+		fmt.Fprintf(w, "%s%s", indent, sanitizeForTerminal(displayPath(root)))
 		if root.CommitID != "" {
-			shortCommit := root.CommitID
-			if len(shortCommit) > 7 {
-				shortCommit = shortCommit[:7]
-			}
-			fmt.Fprintf(w, " @ %s", shortCommit)
+			fmt.Fprintf(w, " @ %s", shortSHA(root.CommitID))
 		}
 		isOutdated := root.Line == nil && root.OriginalLine != nil
 		if isOutdated {
 			fmt.Fprintf(w, " [outdated]")
 		}
 		fmt.Fprintln(w)
-		diffLines := strings.Split(root.DiffHunk, "\n")
-		for _, line := range diffLines {
-			fmt.Fprintf(w, "    %s\n", line)
+		if repeated {
+			fmt.Fprintf(w, "%s(same hunk)\n", indent)
+		} else {
+			renderHunk(w, root, opts)
 		}
 	}
 
-	for _, comment := range thread.Comments {
-		fmt.Fprintf(w, "  @%s at %s:\n", comment.User.Login, comment.CreatedAt.Format("2006-01-02 15:04:05"))
-		bodyLines := strings.Split(comment.Body, "\n")
-		for _, line := range bodyLines {
-			fmt.Fprintf(w, "    %s\n", line)
+	if ctx := root.FileContext; ctx != nil {
+		fmt.Fprintf(w, "%scontext @ %s:\n", indent, shortSHA(ctx.Ref))
+		renderFileLines(w, indent, ctx)
+	}
+
+	renderThreadComments(w, indent, comments, opts)
+}
+
+// renderHunk writes the diff hunk of a thread's first comment, with what is
+// at its lines now when that has been loaded
+func renderHunk(w io.Writer, root Comment, opts RenderOptions) {
+	indent := opts.indent()
+	if !hunkConsistent(root.DiffHunk) {
+		fmt.Fprintf(w, "%s(diff hunk may be truncated)\n", indent)
+	}
+	if root.CurrentContext != nil {
+		fmt.Fprintf(w, "%sThen:\n", indent)
+	}
+	diffLines := transformDiff(strings.Split(sanitizeForTerminal(root.DiffHunk), "\n"), opts)
+	for _, line := range diffLines {
+		fmt.Fprintf(w, "%s%s%s\n", indent, indent, line)
+	}
+	if now := root.CurrentContext; now != nil {
+		if now.Removed {
+			fmt.Fprintf(w, "%sNow: file removed\n", indent)
+		} else {
+			fmt.Fprintf(w, "%sNow @ %s:\n", indent, shortSHA(now.Ref))
+			renderFileLines(w, indent, now)
+		}
+	}
+}
+
+// renderThreadComments writes the comments of a thread, each introduced by
+// its author at prefix and with its body indented a level further. Replies
+// to replies are nested further still, as replyNesting describes.
+func renderThreadComments(w io.Writer, prefix string, comments []Comment, opts RenderOptions) {
+	depths := replyDepths(comments)
+	for _, comment := range comments {
+		nesting, marker := replyNesting(depths[comment.ID], opts)
+		at := prefix + nesting
+		fmt.Fprintf(w, "%s%s@%s at %s:\n", at, marker, sanitizeForTerminal(comment.User.Login), opts.timestamp(comment.CreatedAt))
+		for _, line := range strings.Split(bodyText(comment.Body, opts), "\n") {
+			fmt.Fprintf(w, "%s%s%s\n", at, opts.indent(), line)
+		}
+		for _, line := range reactionLines(comment.Reactions) {
+			fmt.Fprintf(w, "%s%s%s\n", at, opts.indent(), line)
 		}
 		fmt.Fprintln(w)
 	}
 }
+
+// transformDiff applies the diff hunk options in opts to the hunk's lines
+func transformDiff(lines []string, opts RenderOptions) []string {
+	// Number first, while every line is still there to count
+	if opts.DiffLineNumbers {
+		lines = numberDiff(lines)
+	}
+	if opts.CompactDiff {
+		lines = compactDiff(lines)
+	}
+	if opts.PlainDiff {
+		lines = plainDiff(lines)
+	}
+	return lines
+}
+
+// hunkHeaderPattern matches a hunk header, capturing the first old and new
+// line numbers, e.g. "@@ -10,4 +10,6 @@"
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// numberDiff inserts the old and new line numbers after the marker of each
+// line of a diff hunk, leaving the old number blank on added lines and the
+// new number blank on removed ones. The marker stays first so the other
+// transforms still recognise the line. Lines before a valid hunk header
+// can't be numbered and are left alone.
+func numberDiff(lines []string) []string {
+	type numbers struct{ old, new string }
+	nums := make([]*numbers, len(lines))
+	width := 0
+	oldLine, newLine := 0, 0
+	inHunk := false
+	for i, line := range lines {
+		if m := hunkHeaderPattern.FindStringSubmatch(line); m != nil {
+			oldLine, _ = strconv.Atoi(m[1])
+			newLine, _ = strconv.Atoi(m[2])
+			inHunk = true
+			continue
+		}
+		if !inHunk || strings.HasPrefix(line, "\\") {
+			continue
+		}
+
+		n := &numbers{}
+		switch {
+		case strings.HasPrefix(line, "+"):
+			n.new = strconv.Itoa(newLine)
+			newLine++
+		case strings.HasPrefix(line, "-"):
+			n.old = strconv.Itoa(oldLine)
+			oldLine++
+		default:
+			n.old, n.new = strconv.Itoa(oldLine), strconv.Itoa(newLine)
+			oldLine++
+			newLine++
+		}
+		width = max(width, len(n.old), len(n.new))
+		nums[i] = n
+	}
+
+	numbered := make([]string, len(lines))
+	for i, line := range lines {
+		n := nums[i]
+		if n == nil {
+			numbered[i] = line
+			continue
+		}
+		marker, rest := " ", line
+		if line != "" {
+			marker, rest = line[:1], line[1:]
+		}
+		numbered[i] = fmt.Sprintf("%s %*s %*s  %s", marker, width, n.old, width, n.new, rest)
+	}
+	return numbered
+}
+
+// compactDiff replaces each run of context lines in a diff hunk with a
+// single " ..." marker
+func compactDiff(lines []string) []string {
+	var compact []string
+	inContext := false
+	for _, line := range lines {
+		if line == "" || line[0] == ' ' {
+			if !inContext {
+				compact = append(compact, " ...")
+				inContext = true
+			}
+			continue
+		}
+		compact = append(compact, line)
+		inContext = false
+	}
+	return compact
+}
+
+// renderFileLines writes numbered file lines, marking the one a comment is on
+func renderFileLines(w io.Writer, indent string, ctx *FileContext) {
+	width := len(strconv.Itoa(ctx.StartLine + len(ctx.Lines) - 1))
+	for i, line := range ctx.Lines {
+		marker := " "
+		if ctx.StartLine+i == ctx.Line {
+			marker = ">"
+		}
+		fmt.Fprintf(w, "%s%s %*d  %s\n", indent, marker, width, ctx.StartLine+i, sanitizeForTerminal(line))
+	}
+}
+
+// shortSHA abbreviates a commit SHA to seven characters
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// plainDiff strips the hunk headers and line markers from a diff hunk,
+// leaving the code of the removed, added and unchanged lines
+func plainDiff(lines []string) []string {
+	var plain []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "@@") || strings.HasPrefix(line, "\\") {
+			continue
+		}
+		if line != "" && strings.ContainsRune("+- ", rune(line[0])) {
+			line = line[1:]
+		}
+		plain = append(plain, line)
+	}
+	return plain
+}
+
+var newlineReplacer = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// normalizeNewlines converts Windows and old Mac line endings to "\n" so
+// text authored on any platform splits into lines cleanly
+func normalizeNewlines(s string) string {
+	return newlineReplacer.Replace(s)
+}
@@ -2,16 +2,33 @@ package prview_test
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	prview "github.com/bmon/gh-prview"
+	"github.com/cli/go-gh/v2/pkg/repository"
 )
 
+func intPtr(n int) *int { return &n }
+
+func int64Ptr(n int64) *int64 { return &n }
+
+func boolPtr(b bool) *bool { return &b }
+
 // createMockPR creates a sample PR object for testing
 func createMockPR() prview.PullRequest {
-	now := time.Now()
+	return createMockPRAt(time.Now())
+}
+
+// createMockPRAt creates the sample PR with its latest comment made at now,
+// so its rendering can be compared exactly
+func createMockPRAt(now time.Time) prview.PullRequest {
 	earlier := now.Add(-1 * time.Hour)
 	evenEarlier := now.Add(-2 * time.Hour)
 
@@ -33,30 +50,36 @@ func createMockPR() prview.PullRequest {
 		},
 		{
 			ID:        2,
-			Body:      "This is a comment with a diff",
+			Body:      "This is a later comment",
 			CreatedAt: now,
 			User:      prview.User{Login: "commenter2"},
-			DiffHunk:  "@@ -1,5 +1,7 @@\n function test() {\n-  return false;\n+  // Added a comment\n+  return true;\n }",
 		},
 	}
 
 	// Add a review
 	review := prview.Review{
-		ID:        101,
-		Body:      "Here's my review",
-		State:     "APPROVED",
-		CreatedAt: earlier.Add(30 * time.Minute),
-		User:      prview.User{Login: "reviewer1"},
+		ID:          101,
+		Body:        "Here's my review",
+		State:       "APPROVED",
+		SubmittedAt: earlier.Add(30 * time.Minute),
+		User:        prview.User{Login: "reviewer1"},
 	}
 
 	// Add review comments
-	review.Comments = []prview.Comment{
+	review.Threads = []prview.CommentThread{
 		{
-			ID:        201,
-			Body:      "This looks good",
-			CreatedAt: earlier.Add(31 * time.Minute),
-			User:      prview.User{Login: "reviewer1"},
-			DiffHunk:  "@@ -10,4 +10,6 @@\n function another() {\n+  // New function\n+  return 42;\n }",
+			Comments: []prview.Comment{
+				{
+					ID:                  201,
+					Body:                "This looks good",
+					CreatedAt:           earlier.Add(31 * time.Minute),
+					User:                prview.User{Login: "reviewer1"},
+					Path:                "main.go",
+					Line:                intPtr(11),
+					DiffHunk:            "@@ -10,4 +10,6 @@\n function another() {\n+  // New function\n+  return 42;\n }",
+					PullRequestReviewID: 101,
+				},
+			},
 		},
 	}
 
@@ -71,7 +94,7 @@ func TestRenderPR(t *testing.T) {
 
 	// Render to a buffer
 	var buf bytes.Buffer
-	err := prview.RenderPR(&buf, pr)
+	err := prview.RenderPR(&buf, pr, prview.RenderOptions{})
 	if err != nil {
 		t.Fatalf("RenderPR returned an error: %v", err)
 	}
@@ -83,16 +106,15 @@ func TestRenderPR(t *testing.T) {
 		"PR #123: Test PR",
 		"Author: testuser",
 		"This is a test PR body",
-		"Comment by commenter1",
+		"commenter1 COMMENTED at",
 		"This is a regular comment",
-		"Comment by commenter2",
-		"This is a comment with a diff",
-		"Diff:",
-		"@@ -1,5 +1,7 @@",
-		"Review by reviewer1",
-		"State: APPROVED",
+		"commenter2 COMMENTED at",
+		"This is a later comment",
+		"reviewer1 APPROVED at",
 		"Here's my review",
-		"This looks good",
+		"  main.go",
+		"    @@ -10,4 +10,6 @@",
+		"    This looks good",
 	}
 
 	for _, expected := range expectedStrings {
@@ -100,38 +122,237 @@ func TestRenderPR(t *testing.T) {
 			t.Errorf("Expected output to contain: %s", expected)
 		}
 	}
+
+	// Timeline items should be rendered in chronological order
+	first := strings.Index(output, "This is a regular comment")
+	second := strings.Index(output, "Here's my review")
+	third := strings.Index(output, "This is a later comment")
+	if !(first < second && second < third) {
+		t.Errorf("Expected timeline items in chronological order, got:\n%s", output)
+	}
+}
+
+func TestRenderPRNoHeader(t *testing.T) {
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, createMockPR(), prview.RenderOptions{NoHeader: true}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output := buf.String()
+
+	for _, unexpected := range []string{"PR #123", "Author: testuser", "This is a test PR body"} {
+		if strings.Contains(output, unexpected) {
+			t.Errorf("Expected no header, found %q in:\n%s", unexpected, output)
+		}
+	}
+	if !strings.HasPrefix(output, "commenter1 COMMENTED at") {
+		t.Errorf("Expected output to start with the first timeline item, got:\n%s", output)
+	}
+	if !strings.Contains(output, "This is a later comment") {
+		t.Errorf("Expected comments to still render, got:\n%s", output)
+	}
+}
+
+func TestRenderPRHeaderOneline(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(*prview.PullRequest)
+		state string
+	}{
+		{"open", func(*prview.PullRequest) {}, "OPEN"},
+		{"draft", func(pr *prview.PullRequest) { pr.Draft = true }, "DRAFT"},
+		{"closed", func(pr *prview.PullRequest) { pr.State = "closed" }, "CLOSED"},
+		{"merged", func(pr *prview.PullRequest) { pr.State = "closed"; pr.MergedAt = &pr.CreatedAt }, "MERGED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := createMockPR()
+			tt.setup(&pr)
+
+			var buf bytes.Buffer
+			if err := prview.RenderPR(&buf, pr, prview.RenderOptions{HeaderOneline: true}); err != nil {
+				t.Fatalf("RenderPR returned an error: %v", err)
+			}
+			output := buf.String()
+
+			expected := fmt.Sprintf("#123 [%s] Test PR — testuser, %s\n%s\n", tt.state, pr.CreatedAt.Format("2006-01-02"), strings.Repeat("-", 80))
+			if !strings.HasPrefix(output, expected) {
+				t.Errorf("Expected output to start with:\n%s\ngot:\n%s", expected, output)
+			}
+			for _, unexpected := range []string{"PR #123:", "Author: testuser", "Created:", "This is a test PR body"} {
+				if strings.Contains(output, unexpected) {
+					t.Errorf("Expected no header block, found %q in:\n%s", unexpected, output)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderPRMergeConflicts(t *testing.T) {
+	pr := createMockPR()
+	pr.Mergeable = boolPtr(false)
+	pr.MergeableState = "dirty"
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "PR #123: Test PR\n⚠ This PR has merge conflicts\nAuthor: testuser\n") {
+		t.Errorf("Expected a merge conflict banner under the title, got:\n%s", buf.String())
+	}
+}
+
+func TestRenderPRCommitRange(t *testing.T) {
+	pr := createMockPR()
+	pr.Base = prview.GitRef{Ref: "main", SHA: "abc1234567890abcdef1234567890abcdef12345"}
+	pr.Head = prview.GitRef{Ref: "feature", SHA: "def5678901234567890abcdef1234567890abcde"}
+	pr.CommitCount = 7
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "\nCommits: abc1234..def5678 (7 commits)\n") {
+		t.Errorf("Expected the commit range in the header, got:\n%s", buf.String())
+	}
+
+	pr.Base.SHA = ""
+	buf.Reset()
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if strings.Contains(buf.String(), "Commits:") {
+		t.Errorf("Expected no commit range without a base SHA, got:\n%s", buf.String())
+	}
+}
+
+func TestLoadPRReviewDecision(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7":           `{"number": 7, "title": "Loaded PR", "user": {"login": "author"}}`,
+		"/repos/owner/repo/issues/7/comments": `[]`,
+		"/repos/owner/repo/pulls/7/reviews":   `[{"id": 10, "state": "CHANGES_REQUESTED", "user": {"login": "bob"}}]`,
+		"/repos/owner/repo/pulls/7/comments":  `[]`,
+		"/repos/owner/repo/pulls/7/commits":   `[]`,
+		"/graphql": `{"data": {"repository": {"pullRequest": {
+			"reviewDecision": "CHANGES_REQUESTED",
+			"reviewThreads": {"nodes": [], "pageInfo": {"hasNextPage": false}}
+		}}}}`,
+	}}
+
+	pr, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+	if pr.ReviewDecision != "CHANGES_REQUESTED" {
+		t.Fatalf("Expected the review decision to be loaded, got %q", pr.ReviewDecision)
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "\nReview decision: CHANGES_REQUESTED\n") {
+		t.Errorf("Expected the review decision in the header, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := prview.RenderPR(&buf, createMockPR(), prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if strings.Contains(buf.String(), "Review decision:") {
+		t.Errorf("Expected no review decision line without one, got:\n%s", buf.String())
+	}
+}
+
+func TestRenderPRFirstTimeContributor(t *testing.T) {
+	tests := []struct {
+		association string
+		want        bool
+	}{
+		{"FIRST_TIME_CONTRIBUTOR", true},
+		{"FIRST_TIMER", true},
+		{"MEMBER", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.association, func(t *testing.T) {
+			pr := createMockPR()
+			pr.AuthorAssociation = tt.association
+
+			var buf bytes.Buffer
+			if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+				t.Fatalf("RenderPR returned an error: %v", err)
+			}
+			banner := strings.Contains(buf.String(), "Author: testuser\n👋 First-time contributor\n")
+			if banner != tt.want {
+				t.Errorf("Expected banner %v, got output:\n%s", tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestRenderPRMergeability(t *testing.T) {
+	tests := []struct {
+		name      string
+		mergeable *bool
+		state     string
+		want      string
+	}{
+		{"computing", nil, "unknown", "Mergeability: computing..."},
+		{"clean", boolPtr(true), "clean", ""},
+		{"blocked", boolPtr(true), "blocked", ""},
+		{"not loaded", nil, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := createMockPR()
+			pr.Mergeable = tt.mergeable
+			pr.MergeableState = tt.state
+
+			var buf bytes.Buffer
+			if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+				t.Fatalf("RenderPR returned an error: %v", err)
+			}
+			output := buf.String()
+			if strings.Contains(output, "merge conflicts") {
+				t.Errorf("Didn't expect a merge conflict banner, got:\n%s", output)
+			}
+			if tt.want != "" && !strings.Contains(output, "PR #123: Test PR\n"+tt.want+"\n") {
+				t.Errorf("Expected %q under the title, got:\n%s", tt.want, output)
+			}
+			if tt.want == "" && strings.Contains(output, "Mergeability") {
+				t.Errorf("Didn't expect a mergeability line, got:\n%s", output)
+			}
+		})
+	}
 }
 
 func TestRenderComment(t *testing.T) {
 	// Create a test comment
-	comment := prview.Comment{
-		ID:        42,
-		Body:      "Test comment\nwith multiple lines",
-		CreatedAt: time.Now(),
-		User:      prview.User{Login: "test-user"},
-		DiffHunk:  "@@ -1,3 +1,4 @@\n line1\n+added line\n line2\n line3",
+	pr := prview.PullRequest{
+		Number: 1,
+		Comments: []prview.Comment{
+			{
+				ID:        42,
+				Body:      "Test comment\nwith multiple lines",
+				CreatedAt: time.Now(),
+				User:      prview.User{Login: "test-user"},
+			},
+		},
 	}
 
 	// Render to a buffer
 	var buf bytes.Buffer
-	err := prview.RenderComment(&buf, comment, 2)
+	err := prview.RenderPR(&buf, pr, prview.RenderOptions{})
 	if err != nil {
-		t.Fatalf("renderComment returned an error: %v", err)
+		t.Fatalf("RenderPR returned an error: %v", err)
 	}
 
 	output := buf.String()
 
 	// Verify content
 	expectedStrings := []string{
-		"  Comment by test-user",
-		"  Test comment",
-		"  with multiple lines",
-		"  Diff:",
-		"    @@ -1,3 +1,4 @@",
-		"    line1",
-		"    +added line",
-		"    line2",
-		"    line3",
+		"test-user COMMENTED at",
+		"Test comment\nwith multiple lines",
 	}
 
 	for _, expected := range expectedStrings {
@@ -144,44 +365,48 @@ func TestRenderComment(t *testing.T) {
 func TestRenderReview(t *testing.T) {
 	// Create a test review
 	review := prview.Review{
-		ID:        101,
-		Body:      "Review comment",
-		State:     "CHANGES_REQUESTED",
-		CreatedAt: time.Now(),
-		User:      prview.User{Login: "reviewer"},
-		Comments: []prview.Comment{
+		ID:          101,
+		Body:        "Review comment",
+		State:       "CHANGES_REQUESTED",
+		SubmittedAt: time.Now(),
+		User:        prview.User{Login: "reviewer"},
+		Threads: []prview.CommentThread{
 			{
-				ID:        201,
-				Body:      "Comment in review",
-				CreatedAt: time.Now(),
-				User:      prview.User{Login: "reviewer"},
-				DiffHunk:  "@@ -5,7 +5,8 @@\n context\n+added\n context",
+				Comments: []prview.Comment{
+					{
+						ID:           201,
+						Body:         "Comment in review",
+						CreatedAt:    time.Now(),
+						User:         prview.User{Login: "reviewer"},
+						Path:         "pkg/file.go",
+						CommitID:     "abcdef1234567890",
+						OriginalLine: intPtr(6),
+						DiffHunk:     "@@ -5,7 +5,8 @@\n context\n+added\n context",
+					},
+				},
 			},
 		},
 	}
 
 	// Render to a buffer
 	var buf bytes.Buffer
-	err := prview.RenderReview(&buf, review)
+	err := prview.RenderReview(&buf, review, prview.RenderOptions{})
 	if err != nil {
-		t.Fatalf("renderReview returned an error: %v", err)
+		t.Fatalf("RenderReview returned an error: %v", err)
 	}
 
 	output := buf.String()
 
 	// Verify content
 	expectedStrings := []string{
-		"Review by reviewer",
-		"State: CHANGES_REQUESTED",
+		"reviewer CHANGES_REQUESTED at",
 		"Review comment",
-		"Review comments:",
-		"  Comment by reviewer",
-		"  Comment in review",
-		"  Diff:",
+		"  pkg/file.go @ abcdef1 [outdated]",
 		"    @@ -5,7 +5,8 @@",
-		"    context",
+		"     context",
 		"    +added",
-		"    context",
+		"  @reviewer at",
+		"    Comment in review",
 	}
 
 	for _, expected := range expectedStrings {
@@ -190,3 +415,591 @@ func TestRenderReview(t *testing.T) {
 		}
 	}
 }
+
+func TestRenderReviewRepliesOnly(t *testing.T) {
+	review := prview.Review{
+		ID:          102,
+		State:       "COMMENTED",
+		SubmittedAt: time.Now(),
+		User:        prview.User{Login: "reviewer"},
+		ReplyCount:  2,
+	}
+
+	var buf bytes.Buffer
+	err := prview.RenderPR(&buf, prview.PullRequest{Reviews: []prview.Review{review}}, prview.RenderOptions{})
+	if err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "(2 comments under existing threads)") {
+		t.Errorf("Expected reply count summary, got:\n%s", buf.String())
+	}
+}
+
+func TestLoadPROutsideRepo(t *testing.T) {
+	tests := []struct {
+		name        string
+		resolverErr error
+		expected    string
+	}{
+		{
+			name:        "not a git repository",
+			resolverErr: errors.New("failed to run git: fatal: not a git repository (or any of the parent directories): .git"),
+			expected:    "not inside a GitHub repository; pass --repo owner/name",
+		},
+		{
+			name:        "no GitHub remote",
+			resolverErr: errors.New("unable to determine current repository, none of the git remotes configured for this repository point to a known GitHub host"),
+			expected:    "no GitHub remote found for this git repository; pass --repo owner/name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prview.SetRepoResolver(t, func() (repository.Repository, error) {
+				return repository.Repository{}, tt.resolverErr
+			})
+
+			_, err := prview.LoadPR(context.Background(), 1, prview.LoadOptions{})
+			if err == nil {
+				t.Fatal("Expected an error, got nil")
+			}
+			if err.Error() != tt.expected {
+				t.Errorf("Expected error %q, got %q", tt.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestRenderFileContext(t *testing.T) {
+	var file strings.Builder
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(&file, "line %d\n", i)
+	}
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/contents/src/app.go": `{"encoding": "base64", "content": "` +
+			base64.StdEncoding.EncodeToString([]byte(file.String())) + `"}`,
+	}}
+
+	comment := func(id int64, line int) prview.Comment {
+		return prview.Comment{
+			ID:        id,
+			Body:      "Check this",
+			CreatedAt: time.Now(),
+			User:      prview.User{Login: "reviewer"},
+			Path:      "src/app.go",
+			Line:      intPtr(line),
+			DiffHunk:  "@@ -1,1 +1,1 @@\n+line",
+		}
+	}
+	reviews := []prview.Review{
+		{
+			ID:          101,
+			State:       "COMMENTED",
+			SubmittedAt: time.Now(),
+			User:        prview.User{Login: "reviewer"},
+			Threads: []prview.CommentThread{
+				{Comments: []prview.Comment{comment(1, 10)}},
+				{Comments: []prview.Comment{comment(2, 19)}},
+			},
+		},
+	}
+
+	prview.AddFileContext(context.Background(), newTestClient(t, rt), testRepo, reviews, "headsha", 2)
+
+	if len(rt.requests) != 1 {
+		t.Errorf("Expected the file to be fetched once, got %d requests", len(rt.requests))
+	}
+
+	var buf bytes.Buffer
+	err := prview.RenderPR(&buf, prview.PullRequest{Reviews: reviews}, prview.RenderOptions{})
+	if err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output := buf.String()
+
+	expectedStrings := []string{
+		"  context @ headsha:\n     8  line 8\n     9  line 9\n  > 10  line 10\n    11  line 11\n    12  line 12\n",
+		"    17  line 17\n    18  line 18\n  > 19  line 19\n    20  line 20\n",
+	}
+	for _, expected := range expectedStrings {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain:\n%s\ngot:\n%s", expected, output)
+		}
+	}
+}
+
+func TestLoadPRWithTransport(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7":                `{"number": 7, "title": "Loaded PR", "user": {"login": "author"}}`,
+		"/repos/owner/repo/issues/7/comments":      `[{"id": 1, "body": "Issue comment", "user": {"login": "alice"}}]`,
+		"/repos/owner/repo/pulls/7/reviews":        `[{"id": 10, "state": "COMMENTED", "user": {"login": "bob"}}]`,
+		"/repos/owner/repo/pulls/7/comments":       `[{"id": 2, "body": "Inline", "path": "a.go", "pull_request_review_id": 10, "user": {"login": "bob"}}]`,
+		"/repos/owner/repo/pulls/7/commits":        `[{"sha": "abc", "commit": {"message": "Initial commit"}, "author": {"login": "author"}}]`,
+		"/repos/owner/repo/commits/abc/check-runs": `{"check_runs": [{"status": "completed", "conclusion": "success"}]}`,
+	}}
+
+	pr, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+
+	if pr.Title != "Loaded PR" || len(pr.Comments) != 1 || len(pr.Commits) != 1 {
+		t.Errorf("Unexpected PR: %+v", pr)
+	}
+	if len(pr.Reviews) != 1 || len(pr.Reviews[0].Threads) != 1 {
+		t.Fatalf("Expected the inline comment to be threaded under its review, got %+v", pr.Reviews)
+	}
+	if pr.Commits[0].Checks.Succeeded != 1 {
+		t.Errorf("Expected one successful check, got %+v", pr.Commits[0].Checks)
+	}
+}
+
+func TestLoadPRGroupsReviewComments(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7":           `{"number": 7, "title": "Loaded PR", "user": {"login": "author"}}`,
+		"/repos/owner/repo/issues/7/comments": `[]`,
+		"/repos/owner/repo/pulls/7/reviews": `[
+			{"id": 10, "state": "CHANGES_REQUESTED", "user": {"login": "alice"}},
+			{"id": 11, "state": "COMMENTED", "user": {"login": "bob"}},
+			{"id": 12, "state": "APPROVED", "user": {"login": "alice"}}
+		]`,
+		"/repos/owner/repo/pulls/7/comments": `[
+			{"id": 1, "body": "Rename this", "path": "a.go", "pull_request_review_id": 10, "user": {"login": "alice"}},
+			{"id": 2, "body": "And this", "path": "b.go", "pull_request_review_id": 10, "user": {"login": "alice"}},
+			{"id": 3, "body": "Why?", "path": "a.go", "pull_request_review_id": 11, "user": {"login": "bob"}},
+			{"id": 4, "body": "Done", "path": "a.go", "in_reply_to_id": 1, "pull_request_review_id": 12, "user": {"login": "author"}}
+		]`,
+		"/repos/owner/repo/pulls/7/commits": `[]`,
+	}}
+
+	pr, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+
+	threadIDs := func(review prview.Review) []int64 {
+		var ids []int64
+		for _, thread := range review.Threads {
+			ids = append(ids, thread.Comments[0].ID)
+		}
+		return ids
+	}
+	if len(pr.Reviews) != 3 {
+		t.Fatalf("Expected 3 reviews, got %d", len(pr.Reviews))
+	}
+	if ids := threadIDs(pr.Reviews[0]); !reflect.DeepEqual(ids, []int64{1, 2}) {
+		t.Errorf("Expected threads 1 and 2 under review 10, got %v", ids)
+	}
+	if ids := threadIDs(pr.Reviews[1]); !reflect.DeepEqual(ids, []int64{3}) {
+		t.Errorf("Expected thread 3 under review 11, got %v", ids)
+	}
+	if review := pr.Reviews[2]; len(review.Threads) != 0 || review.ReplyCount != 1 {
+		t.Errorf("Expected review 12 to hold just a reply, got %+v", review)
+	}
+
+	for _, req := range rt.requests {
+		if strings.Contains(req.URL.Path, "/reviews/") {
+			t.Errorf("Expected review comments from a single request, got a request for %s", req.URL.Path)
+		}
+	}
+}
+
+func TestRenderNormalizesCRLF(t *testing.T) {
+	pr := prview.PullRequest{
+		Body: "PR line one\r\nPR line two",
+		Comments: []prview.Comment{
+			{ID: 1, Body: "Issue line one\r\nIssue line two", CreatedAt: time.Now()},
+		},
+		Reviews: []prview.Review{
+			{
+				ID:          101,
+				State:       "COMMENTED",
+				SubmittedAt: time.Now(),
+				Threads: []prview.CommentThread{
+					{Comments: []prview.Comment{
+						{
+							ID:        2,
+							Body:      "Inline one\r\nInline two\rInline three",
+							CreatedAt: time.Now(),
+							Path:      "main.go",
+							DiffHunk:  "@@ -1,2 +1,2 @@\r\n-old\r\n+new",
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output := buf.String()
+
+	if strings.Contains(output, "\r") {
+		t.Errorf("Expected no carriage returns in output, got %q", output)
+	}
+
+	expectedStrings := []string{
+		"PR line one\nPR line two\n",
+		"Issue line one\nIssue line two\n",
+		"    @@ -1,2 +1,2 @@\n    -old\n    +new\n",
+		"    Inline one\n    Inline two\n    Inline three\n",
+	}
+	for _, expected := range expectedStrings {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+	if pr.Comments[0].Body != "Issue line one\r\nIssue line two" {
+		t.Errorf("Expected the stored body to be left untouched")
+	}
+}
+
+func TestLoadPRRequestTimeout(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{
+		responses: map[string]string{
+			"/repos/owner/repo/pulls/7":                 `{"number": 7, "title": "Loaded PR"}`,
+			"/repos/owner/repo/issues/7/comments":       `[]`,
+			"/repos/owner/repo/pulls/7/reviews":         `[]`,
+			"/repos/owner/repo/pulls/7/comments":        `[]`,
+			"/repos/owner/repo/pulls/7/commits":         `[{"sha": "slow"}, {"sha": "fast"}]`,
+			"/repos/owner/repo/commits/slow/check-runs": `{"check_runs": [{"status": "completed", "conclusion": "success"}]}`,
+			"/repos/owner/repo/commits/fast/check-runs": `{"check_runs": [{"status": "completed", "conclusion": "success"}]}`,
+		},
+		delays: map[string]time.Duration{
+			"/repos/owner/repo/commits/slow/check-runs": 5 * time.Second,
+		},
+	}
+
+	pr, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{
+		Repo:           "owner/repo",
+		Transport:      rt,
+		RequestTimeout: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+
+	if pr.Commits[0].Checks.Succeeded != 0 {
+		t.Errorf("Expected the slow checks request to time out, got %+v", pr.Commits[0].Checks)
+	}
+	if pr.Commits[1].Checks.Succeeded != 1 {
+		t.Errorf("Expected the fast checks request to complete, got %+v", pr.Commits[1].Checks)
+	}
+}
+
+func TestLoadPROverallTimeout(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{
+		responses: map[string]string{"/repos/owner/repo/pulls/7": `{"number": 7}`},
+		delays:    map[string]time.Duration{"/repos/owner/repo/pulls/7": 5 * time.Second},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := prview.LoadPR(ctx, 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline exceeded error, got %v", err)
+	}
+}
+
+func TestRenderReviewIndent(t *testing.T) {
+	review := prview.Review{
+		ID:          101,
+		State:       "COMMENTED",
+		SubmittedAt: time.Now(),
+		User:        prview.User{Login: "reviewer"},
+		Threads: []prview.CommentThread{
+			{Comments: []prview.Comment{
+				{
+					ID:        1,
+					Body:      "Inline comment",
+					CreatedAt: time.Now(),
+					User:      prview.User{Login: "reviewer"},
+					Path:      "main.go",
+					DiffHunk:  "@@ -1 +1 @@\n+added",
+				},
+			}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderReview(&buf, review, prview.RenderOptions{Indent: 4}); err != nil {
+		t.Fatalf("RenderReview returned an error: %v", err)
+	}
+	output := buf.String()
+
+	expectedStrings := []string{
+		"\n    main.go\n",
+		"\n        @@ -1 +1 @@\n        +added\n",
+		"\n    @reviewer at ",
+		"\n        Inline comment\n",
+	}
+	for _, expected := range expectedStrings {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestRenderCompactDiff(t *testing.T) {
+	comment := prview.Comment{
+		ID:        1,
+		Body:      "Inline comment",
+		CreatedAt: time.Now(),
+		User:      prview.User{Login: "reviewer"},
+		Path:      "main.go",
+		DiffHunk:  "@@ -1,6 +1,6 @@\n package main\n \n-func old() {}\n+func new() {}\n \n func main() {}",
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderComment(&buf, comment, prview.RenderOptions{CompactDiff: true}); err != nil {
+		t.Fatalf("RenderComment returned an error: %v", err)
+	}
+	output := buf.String()
+
+	expected := "    @@ -1,6 +1,6 @@\n     ...\n    -func old() {}\n    +func new() {}\n     ...\n"
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected context lines to be collapsed, got:\n%s", output)
+	}
+	if strings.Contains(output, "package main") || strings.Contains(output, "func main()") {
+		t.Errorf("Expected context lines to be omitted, got:\n%s", output)
+	}
+}
+
+func TestRenderFlatTimeline(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	pr := prview.PullRequest{
+		Number: 1,
+		Title:  "Flat",
+		User:   prview.User{Login: "author"},
+		Reviews: []prview.Review{
+			{
+				ID:          10,
+				State:       "COMMENTED",
+				SubmittedAt: base,
+				User:        prview.User{Login: "alice"},
+				Threads: []prview.CommentThread{{Comments: []prview.Comment{
+					{ID: 1, Body: "Early inline", Path: "a.go", DiffHunk: "@@ -1 +1 @@\n+x", CreatedAt: base, User: prview.User{Login: "alice"}},
+					{ID: 2, Body: "Late reply", Path: "a.go", DiffHunk: "@@ -1 +1 @@\n+x", CreatedAt: base.Add(2 * time.Hour), InReplyToID: int64Ptr(1), User: prview.User{Login: "alice"}},
+				}}},
+			},
+			{
+				ID:          11,
+				State:       "APPROVED",
+				Body:        "Newer review",
+				SubmittedAt: base.Add(time.Hour),
+				User:        prview.User{Login: "bob"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{FlatTimeline: true}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output := buf.String()
+
+	early, newer, late := strings.Index(output, "Early inline"), strings.Index(output, "Newer review"), strings.Index(output, "Late reply")
+	if early == -1 || newer == -1 || late == -1 {
+		t.Fatalf("Expected all comments to be rendered, got:\n%s", output)
+	}
+	if !(early < newer && newer < late) {
+		t.Errorf("Expected the late reply to sort after the newer review, got:\n%s", output)
+	}
+	if strings.Contains(output, "alice COMMENTED") {
+		t.Errorf("Expected the bodiless review container to be dropped, got:\n%s", output)
+	}
+	if !strings.Contains(output, "alice REPLIED on a.go at ") {
+		t.Errorf("Expected the reply to name its file, got:\n%s", output)
+	}
+
+	buf.Reset()
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output = buf.String()
+	if strings.Index(output, "Late reply") > strings.Index(output, "Newer review") {
+		t.Errorf("Expected review comments to stay nested by default, got:\n%s", output)
+	}
+}
+
+func TestRenderPlainDiff(t *testing.T) {
+	comment := prview.Comment{
+		ID:        1,
+		Body:      "Inline comment",
+		CreatedAt: time.Now(),
+		User:      prview.User{Login: "reviewer"},
+		Path:      "main.go",
+		DiffHunk:  "@@ -1,4 +1,4 @@\n package main\n \n-func old() {}\n+func new() {}\n\\ No newline at end of file",
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderComment(&buf, comment, prview.RenderOptions{PlainDiff: true}); err != nil {
+		t.Fatalf("RenderComment returned an error: %v", err)
+	}
+	output := buf.String()
+
+	if !strings.Contains(output, "    package main\n    \n    func old() {}\n    func new() {}\n") {
+		t.Errorf("Expected the code lines without markers, got:\n%s", output)
+	}
+	for _, unexpected := range []string{"@@", "+func", "-func", "No newline"} {
+		if strings.Contains(output, unexpected) {
+			t.Errorf("Expected %q to be stripped, got:\n%s", unexpected, output)
+		}
+	}
+}
+
+func TestRenderDiffLineNumbers(t *testing.T) {
+	comment := prview.Comment{
+		ID:        1,
+		Body:      "Inline comment",
+		CreatedAt: time.Now(),
+		User:      prview.User{Login: "reviewer"},
+		Path:      "main.go",
+		DiffHunk:  "@@ -8,5 +8,5 @@ func main() {\n a := 1\n-b := 2\n+b := 3\n+c := 4\n d := a + b\n-e := d\n f := 5",
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderComment(&buf, comment, prview.RenderOptions{DiffLineNumbers: true}); err != nil {
+		t.Fatalf("RenderComment returned an error: %v", err)
+	}
+
+	want := "    @@ -8,5 +8,5 @@ func main() {\n" +
+		"       8  8  a := 1\n" +
+		"    -  9     b := 2\n" +
+		"    +     9  b := 3\n" +
+		"    +    10  c := 4\n" +
+		"      10 11  d := a + b\n" +
+		"    - 11     e := d\n" +
+		"      12 12  f := 5\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("Expected numbered diff lines:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
+
+func TestRenderDiffLineNumbersCompact(t *testing.T) {
+	comment := prview.Comment{
+		ID:        1,
+		CreatedAt: time.Now(),
+		User:      prview.User{Login: "reviewer"},
+		Path:      "main.go",
+		DiffHunk:  "@@ -1,3 +1,3 @@\n a\n b\n-c\n+C",
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderComment(&buf, comment, prview.RenderOptions{DiffLineNumbers: true, CompactDiff: true}); err != nil {
+		t.Fatalf("RenderComment returned an error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "     ...\n    - 3    c\n    +   3  C\n") {
+		t.Errorf("Expected line numbers to survive compacting, got:\n%s", buf.String())
+	}
+}
+
+func TestLoadPRProgress(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7":                `{"number": 7, "title": "Loaded PR", "user": {"login": "author"}}`,
+		"/repos/owner/repo/issues/7/comments":      `[]`,
+		"/repos/owner/repo/pulls/7/reviews":        `[]`,
+		"/repos/owner/repo/pulls/7/comments":       `[]`,
+		"/repos/owner/repo/pulls/7/commits":        `[{"sha": "abc", "commit": {"message": "One"}}, {"sha": "def", "commit": {"message": "Two"}}]`,
+		"/repos/owner/repo/commits/abc/check-runs": `{"check_runs": []}`,
+		"/repos/owner/repo/commits/def/check-runs": `{"check_runs": []}`,
+	}}
+
+	var phases []string
+	_, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{
+		Repo:      "owner/repo",
+		Transport: rt,
+		Progress:  func(phase string) { phases = append(phases, phase) },
+	})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+
+	expected := []string{
+		"fetching PR #7",
+		"fetching comments",
+		"fetching reviews",
+		"fetching review comments",
+		"fetching review thread status",
+		"fetching commits",
+		"fetching checks for commit 1/2",
+		"fetching checks for commit 2/2",
+	}
+	if strings.Join(phases, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected phases:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(phases, "\n"))
+	}
+}
+
+func TestRenderPRMergedBy(t *testing.T) {
+	pr := createMockPRAt(goldenTime)
+	mergedAt := goldenTime.Add(time.Hour)
+	pr.MergedBy = &prview.User{Login: "bob"}
+	pr.MergedAt = &mergedAt
+	pr.MergeCommitSHA = "abc1234def5678"
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Author: testuser\nMerged by bob at 2024-03-01 13:00:00 as abc1234\n") {
+		t.Errorf("Expected a merged by line, got:\n%s", buf.String())
+	}
+}
+
+func TestRenderPRNotMerged(t *testing.T) {
+	pr := createMockPR()
+	pr.MergeCommitSHA = "abc1234def5678"
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if strings.Contains(buf.String(), "Merged") {
+		t.Errorf("Didn't expect a merged line for an open PR, got:\n%s", buf.String())
+	}
+}
+
+func TestRenderThreadChronological(t *testing.T) {
+	at := func(minutes int) time.Time { return goldenTime.Add(time.Duration(minutes) * time.Minute) }
+	review := prview.Review{
+		ID:          1,
+		State:       "COMMENTED",
+		SubmittedAt: at(0),
+		User:        prview.User{Login: "alice"},
+		Threads: []prview.CommentThread{{Comments: []prview.Comment{
+			{ID: 1, Body: "Why?", Path: "a.go", DiffHunk: "@@ -1 +1 @@", CreatedAt: at(0), User: prview.User{Login: "alice"}},
+			{ID: 4, Body: "Fourth", CreatedAt: at(10), User: prview.User{Login: "bob"}},
+			{ID: 3, Body: "Third", CreatedAt: at(5), User: prview.User{Login: "alice"}},
+			{ID: 2, Body: "Second", CreatedAt: at(5), User: prview.User{Login: "bob"}},
+		}}},
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderReview(&buf, review, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderReview returned an error: %v", err)
+	}
+	output := buf.String()
+	positions := []int{
+		strings.Index(output, "Why?"),
+		strings.Index(output, "Second"),
+		strings.Index(output, "Third"),
+		strings.Index(output, "Fourth"),
+	}
+	for i := 1; i < len(positions); i++ {
+		if positions[i-1] < 0 || positions[i-1] > positions[i] {
+			t.Fatalf("Expected thread comments oldest first, ties by ID, got:\n%s", output)
+		}
+	}
+	if review.Threads[0].Comments[1].ID != 4 {
+		t.Errorf("Expected the review's thread to be left in its original order")
+	}
+}
@@ -0,0 +1,38 @@
+package prview
+
+import (
+	"regexp"
+	"strings"
+)
+
+// quotePrefix is drawn in place of each level of a Markdown blockquote
+const quotePrefix = "│ "
+
+// quoteLine matches a Markdown blockquote line, capturing its run of quote
+// markers, e.g. "> >" for a quote of a quote, and the quoted text
+var quoteLine = regexp.MustCompile(`^ {0,3}((?:>[ \t]?)+)(.*)$`)
+
+// markQuotes replaces the > markers of quoted lines in body with a bar per
+// level of nesting, so replies stand apart from the text they quote. Lines
+// in fenced code blocks are left alone.
+func markQuotes(body string) string {
+	lines := strings.Split(normalizeNewlines(body), "\n")
+	inFence := false
+	for i, line := range lines {
+		if isFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		match := quoteLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		depth := strings.Count(match[1], ">")
+		lines[i] = strings.TrimRight(strings.Repeat(quotePrefix, depth)+match[2], " ")
+	}
+	return strings.Join(lines, "\n")
+}
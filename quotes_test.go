@@ -0,0 +1,37 @@
+package prview_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestRenderQuotedReply(t *testing.T) {
+	pr := prview.PullRequest{
+		Number: 1,
+		Title:  "Quotes",
+		Comments: []prview.Comment{{
+			ID:        1,
+			Body:      "> Should this be configurable?\n>> It was in v1\n> > Still is\n>\nYes, I'll add a flag.\n```\n> not a quote\n```",
+			CreatedAt: time.Now(),
+			User:      prview.User{Login: "alice"},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	expected := "│ Should this be configurable?\n" +
+		"│ │ It was in v1\n" +
+		"│ │ Still is\n" +
+		"│\n" +
+		"Yes, I'll add a flag.\n" +
+		"```\n> not a quote\n```\n"
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("Expected quoted lines to be marked, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,105 @@
+package prview
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/repository"
+)
+
+// reactionFetchers is how many comments' reactions are fetched at once
+const reactionFetchers = 4
+
+// reactionEmoji are the reactions GitHub offers, by the content name the API
+// gives them, in the order GitHub shows them
+var reactionEmoji = []struct{ content, emoji string }{
+	{"+1", "👍"},
+	{"-1", "👎"},
+	{"laugh", "😄"},
+	{"hooray", "🎉"},
+	{"confused", "😕"},
+	{"heart", "❤️"},
+	{"rocket", "🚀"},
+	{"eyes", "👀"},
+}
+
+// Reaction is one person's emoji reaction to a comment
+type Reaction struct {
+	Content string `json:"content"`
+	User    User   `json:"user"`
+}
+
+// FetchReactions retrieves the reactions to a comment from path, such as
+// repos/o/r/issues/comments/1/reactions
+func FetchReactions(ctx context.Context, client *api.RESTClient, path string) ([]Reaction, error) {
+	return streamPages[Reaction](ctx, client, path)
+}
+
+// addReactionUsers fetches who left each reaction on the comments that have
+// any, a handful of comments at a time. Comments whose reactions can't be
+// fetched keep just their counts, unless ctx is done and the load as a whole
+// has to fail.
+func addReactionUsers(ctx context.Context, client *api.RESTClient, repo repository.Repository, pr *PullRequest) error {
+	type target struct {
+		comment *Comment
+		path    string
+	}
+	var targets []target
+	for i := range pr.Comments {
+		c := &pr.Comments[i]
+		targets = append(targets, target{c, fmt.Sprintf("repos/%s/%s/issues/comments/%d/reactions", repo.Owner, repo.Name, c.ID)})
+	}
+	for i := range pr.Reviews {
+		for j := range pr.Reviews[i].Threads {
+			for k := range pr.Reviews[i].Threads[j].Comments {
+				c := &pr.Reviews[i].Threads[j].Comments[k]
+				targets = append(targets, target{c, fmt.Sprintf("repos/%s/%s/pulls/comments/%d/reactions", repo.Owner, repo.Name, c.ID)})
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, reactionFetchers)
+	for _, t := range targets {
+		if t.comment.Reactions.TotalCount == 0 {
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer func() { <-slots; wg.Done() }()
+			reactions, err := FetchReactions(ctx, client, t.path)
+			if err != nil {
+				return
+			}
+			users := make(map[string][]string)
+			for _, r := range reactions {
+				users[r.Content] = append(users[r.Content], r.User.Login)
+			}
+			t.comment.Reactions.Users = users
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("error fetching reactions: %w", err)
+	}
+	return nil
+}
+
+// reactionLines describes who left each kind of reaction, e.g.
+// "👍 alice, bob", when that has been loaded
+func reactionLines(r Reactions) []string {
+	var lines []string
+	for _, kind := range reactionEmoji {
+		if users := r.Users[kind.content]; len(users) > 0 {
+			lines = append(lines, kind.emoji+" "+sanitizeForTerminal(strings.Join(users, ", ")))
+		}
+	}
+	return lines
+}
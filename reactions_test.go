@@ -0,0 +1,105 @@
+package prview_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestLoadPRReactionDetails(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7": `{"number": 7, "title": "Reactions", "user": {"login": "author"}}`,
+		"/repos/owner/repo/issues/7/comments": `[
+			{"id": 1, "body": "Shipping it", "user": {"login": "author"}, "reactions": {"total_count": 3}},
+			{"id": 2, "body": "No one cares", "user": {"login": "author"}, "reactions": {"total_count": 0}}
+		]`,
+		"/repos/owner/repo/pulls/7/reviews":  `[]`,
+		"/repos/owner/repo/pulls/7/comments": `[]`,
+		"/repos/owner/repo/pulls/7/commits":  `[]`,
+		"/repos/owner/repo/issues/comments/1/reactions": `[
+			{"content": "heart", "user": {"login": "carol"}},
+			{"content": "+1", "user": {"login": "alice"}},
+			{"content": "+1", "user": {"login": "bob"}}
+		]`,
+	}}
+
+	pr, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt, ReactionDetails: true})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+	for _, req := range rt.requests {
+		if strings.Contains(req.URL.Path, "/comments/2/reactions") {
+			t.Errorf("Expected no request for a comment without reactions")
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{NoHeader: true}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Shipping it\n\n👍 alice, bob\n❤️ carol\n") {
+		t.Errorf("Expected who reacted under the comment, got:\n%s", buf.String())
+	}
+}
+
+func TestFetchReactionsPages(t *testing.T) {
+	reactions := func(from, to int) string {
+		var list []string
+		for i := from; i < to; i++ {
+			list = append(list, fmt.Sprintf(`{"content": "+1", "user": {"login": "user%d"}}`, i))
+		}
+		return "[" + strings.Join(list, ",") + "]"
+	}
+	pages := map[string]string{"1": reactions(0, 100), "2": reactions(100, 105)}
+	client := newTestClient(t, roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(pages[req.URL.Query().Get("page")])),
+			Request:    req,
+		}, nil
+	}))
+
+	got, err := prview.FetchReactions(context.Background(), client, "repos/owner/repo/issues/comments/1/reactions")
+	if err != nil {
+		t.Fatalf("FetchReactions returned an error: %v", err)
+	}
+	if len(got) != 105 || got[104].User.Login != "user104" {
+		t.Errorf("Expected all 105 reactions across both pages, got %d", len(got))
+	}
+}
+
+func TestLoadPRReactionDetailsCancelled(t *testing.T) {
+	setTestAuth(t)
+	stub := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7": `{"number": 7, "title": "Reactions", "user": {"login": "author"}}`,
+		"/repos/owner/repo/issues/7/comments": `[
+			{"id": 1, "body": "Shipping it", "user": {"login": "author"}, "reactions": {"total_count": 3}}
+		]`,
+		"/repos/owner/repo/pulls/7/reviews":  `[]`,
+		"/repos/owner/repo/pulls/7/comments": `[]`,
+		"/repos/owner/repo/pulls/7/commits":  `[]`,
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/reactions") {
+			cancel()
+			return nil, ctx.Err()
+		}
+		return stub.RoundTrip(req)
+	})
+
+	_, err := prview.LoadPR(ctx, 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt, ReactionDetails: true})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancellation to fail the load, got %v", err)
+	}
+}
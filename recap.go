@@ -0,0 +1,84 @@
+package prview
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// recapStateOrder ranks review states for the recap, the ones most in need
+// of attention first
+var recapStateOrder = map[string]int{
+	"CHANGES_REQUESTED": 0,
+	"COMMENTED":         1,
+	"DISMISSED":         2,
+	"APPROVED":          3,
+}
+
+// LatestReviewStates returns each reviewer's final review state, keyed by
+// login. As on GitHub, a later COMMENTED review doesn't replace an earlier
+// approval or change request.
+func LatestReviewStates(pr PullRequest) map[string]string {
+	reviews := append([]Review(nil), pr.Reviews...)
+	sort.SliceStable(reviews, func(i, j int) bool {
+		return reviews[i].SubmittedAt.Before(reviews[j].SubmittedAt)
+	})
+
+	states := make(map[string]string)
+	for _, review := range reviews {
+		login := review.User.Login
+		if review.State == pendingState {
+			continue
+		}
+		if review.State == "COMMENTED" && states[login] != "" {
+			continue
+		}
+		states[login] = review.State
+	}
+	return states
+}
+
+// renderRecap writes a line per reviewer with their final state and how
+// many inline comments they left, most pressing state first
+func renderRecap(w io.Writer, pr PullRequest, opts RenderOptions) {
+	states := LatestReviewStates(pr)
+	if len(states) == 0 {
+		return
+	}
+
+	inline := make(map[string]int)
+	for _, review := range pr.Reviews {
+		for _, thread := range review.Threads {
+			for _, comment := range thread.Comments {
+				inline[comment.User.Login]++
+			}
+		}
+	}
+
+	logins := make([]string, 0, len(states))
+	for login := range states {
+		logins = append(logins, login)
+	}
+	sort.Slice(logins, func(i, j int) bool {
+		a, b := states[logins[i]], states[logins[j]]
+		if a != b {
+			return recapRank(a) < recapRank(b)
+		}
+		return strings.ToLower(logins[i]) < strings.ToLower(logins[j])
+	})
+
+	fmt.Fprintln(w, "Recap")
+	fmt.Fprintln(w, opts.rule("="))
+	for _, login := range logins {
+		fmt.Fprintln(w, opts.withGlyph(states[login], fmt.Sprintf("%s — %s (%s)", sanitizeForTerminal(login), states[login], pluralize(inline[login], "comment"))))
+	}
+}
+
+// recapRank orders a state in the recap, putting unknown states last
+func recapRank(state string) int {
+	if rank, ok := recapStateOrder[state]; ok {
+		return rank
+	}
+	return len(recapStateOrder)
+}
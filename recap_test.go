@@ -0,0 +1,64 @@
+package prview_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestRenderRecap(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return start.Add(time.Duration(hours) * time.Hour) }
+	inline := func(login string, n int) []prview.CommentThread {
+		var threads []prview.CommentThread
+		for i := 0; i < n; i++ {
+			threads = append(threads, prview.CommentThread{Comments: []prview.Comment{{Path: "a.go", User: prview.User{Login: login}}}})
+		}
+		return threads
+	}
+	pr := prview.PullRequest{
+		Number: 1,
+		Reviews: []prview.Review{
+			{ID: 1, State: "CHANGES_REQUESTED", SubmittedAt: at(0), User: prview.User{Login: "alice"}, Threads: inline("alice", 2)},
+			{ID: 2, State: "APPROVED", SubmittedAt: at(3), User: prview.User{Login: "alice"}},
+			{ID: 3, State: "COMMENTED", SubmittedAt: at(4), User: prview.User{Login: "alice"}, Threads: inline("alice", 1)},
+			{ID: 4, State: "CHANGES_REQUESTED", SubmittedAt: at(1), User: prview.User{Login: "carol"}, Threads: inline("carol", 1)},
+			{ID: 5, State: "COMMENTED", SubmittedAt: at(2), User: prview.User{Login: "bob"}},
+			{ID: 6, State: "APPROVED", SubmittedAt: at(2), User: prview.User{Login: "dave"}},
+		},
+	}
+
+	states := prview.LatestReviewStates(pr)
+	if states["alice"] != "APPROVED" || states["bob"] != "COMMENTED" || states["carol"] != "CHANGES_REQUESTED" {
+		t.Errorf("Unexpected final states: %v", states)
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{Recap: true}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	_, recap, ok := strings.Cut(buf.String(), "Recap\n"+strings.Repeat("=", 80)+"\n")
+	if !ok {
+		t.Fatalf("Expected a recap section, got:\n%s", buf.String())
+	}
+	expected := "✗ carol — CHANGES_REQUESTED (1 comment)\n" +
+		"💬 bob — COMMENTED (0 comments)\n" +
+		"✓ alice — APPROVED (3 comments)\n" +
+		"✓ dave — APPROVED (0 comments)\n"
+	if recap != expected {
+		t.Errorf("Expected recap:\n%s\ngot:\n%s", expected, recap)
+	}
+}
+
+func TestRenderWithoutRecap(t *testing.T) {
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, createMockPR(), prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if strings.Contains(buf.String(), "Recap") {
+		t.Errorf("Didn't expect a recap without the option, got:\n%s", buf.String())
+	}
+}
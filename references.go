@@ -0,0 +1,80 @@
+package prview
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/repository"
+)
+
+// crossReferencedEvent is the issues timeline event GitHub records when the
+// PR is mentioned from another issue or PR
+const crossReferencedEvent = "cross-referenced"
+
+// CrossReference is an issue or PR that mentions this PR
+type CrossReference struct {
+	CreatedAt time.Time
+	Actor     User
+	// Number and Repo identify the referencing issue, Repo in owner/name form
+	Number int
+	Repo   string
+	Title  string
+}
+
+// timelineEvent is an entry from the issues timeline API, with only the
+// fields a cross-reference needs
+type timelineEvent struct {
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Actor     User      `json:"actor"`
+	Source    struct {
+		Issue *struct {
+			Number     int    `json:"number"`
+			Title      string `json:"title"`
+			Repository struct {
+				FullName string `json:"full_name"`
+			} `json:"repository"`
+		} `json:"issue"`
+	} `json:"source"`
+}
+
+// FetchCrossReferences retrieves the issues and PRs that mention a PR, from
+// the cross-referenced events of its issues timeline. The timeline is read
+// page by page, as commits, comments and labels fill the first page of any
+// active PR.
+func FetchCrossReferences(ctx context.Context, client *api.RESTClient, repo repository.Repository, prNumber int) ([]CrossReference, error) {
+	events, err := streamPages[timelineEvent](ctx, client, fmt.Sprintf("repos/%s/%s/issues/%d/timeline", repo.Owner, repo.Name, prNumber))
+	if err != nil {
+		return nil, err
+	}
+	return crossReferences(events), nil
+}
+
+// crossReferences picks the cross-referenced events out of a timeline
+func crossReferences(events []timelineEvent) []CrossReference {
+	var refs []CrossReference
+	for _, event := range events {
+		if event.Event != crossReferencedEvent || event.Source.Issue == nil {
+			continue
+		}
+		issue := event.Source.Issue
+		refs = append(refs, CrossReference{
+			CreatedAt: event.CreatedAt,
+			Actor:     event.Actor,
+			Number:    issue.Number,
+			Repo:      issue.Repository.FullName,
+			Title:     issue.Title,
+		})
+	}
+	return refs
+}
+
+func renderReference(w io.Writer, ref CrossReference, opts RenderOptions) {
+	fmt.Fprintf(w, "referenced by #%d in %s at %s\n", ref.Number, sanitizeForTerminal(ref.Repo), opts.timestamp(ref.CreatedAt))
+	if ref.Title != "" {
+		fmt.Fprintf(w, "\n%s\n", markMatches(sanitizeForTerminal(ref.Title), opts.Highlight))
+	}
+}
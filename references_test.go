@@ -0,0 +1,113 @@
+package prview_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestLoadPRReferences(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7":           `{"number": 7, "title": "Loaded PR", "user": {"login": "author"}}`,
+		"/repos/owner/repo/issues/7/comments": `[]`,
+		"/repos/owner/repo/pulls/7/reviews":   `[]`,
+		"/repos/owner/repo/pulls/7/comments":  `[]`,
+		"/repos/owner/repo/pulls/7/commits":   `[]`,
+		"/repos/owner/repo/issues/7/timeline": `[
+			{"event": "labeled", "created_at": "2024-01-01T09:00:00Z", "actor": {"login": "bob"}},
+			{"event": "cross-referenced", "created_at": "2024-01-02T10:00:00Z", "actor": {"login": "carol"},
+			 "source": {"type": "issue", "issue": {"number": 42, "title": "Crash on startup", "repository": {"full_name": "other/project"}}}}
+		]`,
+	}}
+
+	pr, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt, References: true})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+	if len(pr.References) != 1 {
+		t.Fatalf("Expected 1 cross-reference, got %+v", pr.References)
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "referenced by #42 in other/project at 2024-01-02 10:00:00\n\nCrash on startup\n") {
+		t.Errorf("Expected the cross-reference in the timeline, got:\n%s", buf.String())
+	}
+}
+
+func TestFetchCrossReferencesPages(t *testing.T) {
+	// A full first page of other events pushes the reference onto the second
+	first := make([]string, 100)
+	for i := range first {
+		first[i] = `{"event": "committed"}`
+	}
+	pages := map[string]string{
+		"1": "[" + strings.Join(first, ",") + "]",
+		"2": `[{"event": "cross-referenced", "created_at": "2024-01-02T10:00:00Z", "actor": {"login": "carol"},
+			"source": {"type": "issue", "issue": {"number": 42, "title": "Crash on startup", "repository": {"full_name": "other/project"}}}}]`,
+	}
+	var requested []string
+	client := newTestClient(t, roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		q := req.URL.Query()
+		requested = append(requested, q.Get("page"))
+		if q.Get("per_page") != "100" {
+			t.Errorf("Expected per_page=100, got %q", q.Get("per_page"))
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(pages[q.Get("page")])),
+			Request:    req,
+		}, nil
+	}))
+
+	refs, err := prview.FetchCrossReferences(context.Background(), client, testRepo, 7)
+	if err != nil {
+		t.Fatalf("FetchCrossReferences returned an error: %v", err)
+	}
+	if len(refs) != 1 || refs[0].Number != 42 {
+		t.Errorf("Expected the reference from the second page, got %+v", refs)
+	}
+	if strings.Join(requested, ",") != "1,2" {
+		t.Errorf("Expected pages 1 and 2 to be fetched, got %v", requested)
+	}
+}
+
+func TestFilterGrepReferences(t *testing.T) {
+	pr := prview.PullRequest{References: []prview.CrossReference{
+		{Number: 41, Repo: "other/project", Title: "Crash on startup"},
+		{Number: 42, Repo: "other/project", Title: "Update docs"},
+	}}
+	filtered := prview.FilterGrep(pr, regexp.MustCompile("crash|Crash"))
+	if len(filtered.References) != 1 || filtered.References[0].Number != 41 {
+		t.Errorf("Expected only the matching reference, got %+v", filtered.References)
+	}
+}
+
+func TestLoadPRWithoutReferences(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7":           `{"number": 7, "title": "Loaded PR", "user": {"login": "author"}}`,
+		"/repos/owner/repo/issues/7/comments": `[]`,
+		"/repos/owner/repo/pulls/7/reviews":   `[]`,
+		"/repos/owner/repo/pulls/7/comments":  `[]`,
+		"/repos/owner/repo/pulls/7/commits":   `[]`,
+	}}
+
+	pr, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+	if pr.References != nil {
+		t.Errorf("Expected no cross-references without the option, got %+v", pr.References)
+	}
+}
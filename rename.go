@@ -0,0 +1,109 @@
+package prview
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/repository"
+)
+
+// ChangedFile is a file changed by a pull request, as listed by the files API
+type ChangedFile struct {
+	Filename string `json:"filename"`
+	// PreviousFilename is the file's name before the PR renamed it
+	PreviousFilename string `json:"previous_filename,omitempty"`
+	Status           string `json:"status"`
+	// Patch is the file's diff, which GitHub leaves out for binary and very
+	// large files
+	Patch string `json:"patch,omitempty"`
+}
+
+// Rename records that a file moved from one path to another
+type Rename struct {
+	From string
+	To   string
+}
+
+// prFilesPageSize is the most files the files API returns per page, and
+// maxPRFiles the most it returns for a PR at all
+const (
+	prFilesPageSize = 100
+	maxPRFiles      = 3000
+)
+
+// FetchPRFiles retrieves the files a PR changes, page by page, perPage at a
+// time as pageSize clamps it. GitHub lists at most 3000; compare with
+// PullRequest.ChangedFiles to tell if the list was cut short.
+func FetchPRFiles(ctx context.Context, client *api.RESTClient, repo repository.Repository, prNumber int, perPage int) ([]ChangedFile, error) {
+	perPage = pageSize(perPage, prFilesPageSize)
+	var files []ChangedFile
+	for page := 1; (page-1)*perPage < maxPRFiles; page++ {
+		var batch []ChangedFile
+		err := doRequest(ctx, client, http.MethodGet, fmt.Sprintf("repos/%s/%s/pulls/%d/files?per_page=%d&page=%d",
+			repo.Owner, repo.Name, prNumber, perPage, page), nil, &batch)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, batch...)
+		if len(batch) < perPage {
+			break
+		}
+	}
+	return files, nil
+}
+
+// filesTruncated returns how many files GitHub lists for the PR when that
+// is fewer than it changes, or zero when the list is complete. It goes by
+// the PR's file count, so it holds whether or not the files were loaded.
+func filesTruncated(pr PullRequest) int {
+	if pr.ChangedFiles > maxPRFiles {
+		return maxPRFiles
+	}
+	return 0
+}
+
+// markRenamedFiles records on the first comment of each thread whether its
+// file was renamed by the PR, matching the comment's path against both the
+// old and new names
+func markRenamedFiles(reviews []Review, files []ChangedFile) {
+	renames := make(map[string]*Rename)
+	for _, file := range files {
+		if file.PreviousFilename == "" {
+			continue
+		}
+		rename := &Rename{From: file.PreviousFilename, To: file.Filename}
+		renames[file.PreviousFilename] = rename
+		renames[file.Filename] = rename
+	}
+	if len(renames) == 0 {
+		return
+	}
+
+	for i := range reviews {
+		for j := range reviews[i].Threads {
+			root := &reviews[i].Threads[j].Comments[0]
+			root.Renamed = renames[root.Path]
+		}
+	}
+}
+
+// hasThreads reports whether any of the reviews has a comment thread
+func hasThreads(reviews []Review) bool {
+	for _, review := range reviews {
+		if len(review.Threads) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// displayPath is how a comment's file is named in output: its path, or
+// "old → new" when the PR renamed it
+func displayPath(c Comment) string {
+	if c.Renamed != nil {
+		return c.Renamed.From + " → " + c.Renamed.To
+	}
+	return c.Path
+}
@@ -0,0 +1,170 @@
+package prview_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestLoadPRRenamedFiles(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7":           `{"number": 7, "title": "Loaded PR", "user": {"login": "author"}}`,
+		"/repos/owner/repo/issues/7/comments": `[]`,
+		"/repos/owner/repo/pulls/7/reviews":   `[{"id": 10, "state": "COMMENTED", "user": {"login": "bob"}}]`,
+		"/repos/owner/repo/pulls/7/comments": `[
+			{"id": 1, "body": "Old name", "path": "util.go", "diff_hunk": "@@ -1 +1 @@", "pull_request_review_id": 10, "user": {"login": "bob"}},
+			{"id": 2, "body": "Untouched", "path": "main.go", "diff_hunk": "@@ -1 +1 @@", "pull_request_review_id": 10, "user": {"login": "bob"}}
+		]`,
+		"/repos/owner/repo/pulls/7/files": `[
+			{"filename": "strutil/strings.go", "previous_filename": "util.go", "status": "renamed"},
+			{"filename": "main.go", "status": "modified"}
+		]`,
+		"/repos/owner/repo/pulls/7/commits": `[]`,
+	}}
+
+	pr, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+	threads := pr.Reviews[0].Threads
+	if r := threads[0].Comments[0].Renamed; r == nil || r.From != "util.go" || r.To != "strutil/strings.go" {
+		t.Errorf("Expected util.go to be marked as renamed, got %+v", r)
+	}
+	if threads[1].Comments[0].Renamed != nil {
+		t.Errorf("Didn't expect main.go to be marked as renamed")
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "  util.go → strutil/strings.go\n") {
+		t.Errorf("Expected the rename annotation, got:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "  main.go\n") {
+		t.Errorf("Expected main.go without annotation, got:\n%s", buf.String())
+	}
+}
+
+// filesTransport serves total files for PR 7, in pages as the files API does
+func filesTransport(total int, requests *int) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		*requests++
+		page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(req.URL.Query().Get("per_page"))
+		var files []string
+		for i := (page - 1) * perPage; i < min(page*perPage, total); i++ {
+			files = append(files, fmt.Sprintf(`{"filename": "file%d.go", "status": "modified"}`, i))
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader("[" + strings.Join(files, ",") + "]")),
+			Request:    req,
+		}, nil
+	})
+}
+
+func TestFetchPRFilesPaginates(t *testing.T) {
+	var requests int
+	client := newTestClient(t, filesTransport(250, &requests))
+
+	files, err := prview.FetchPRFiles(context.Background(), client, testRepo, 7, 0)
+	if err != nil {
+		t.Fatalf("FetchPRFiles returned an error: %v", err)
+	}
+	if len(files) != 250 || files[249].Filename != "file249.go" {
+		t.Errorf("Expected all 250 files, got %d", len(files))
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 pages to be requested, got %d", requests)
+	}
+}
+
+func TestFetchPRFilesCap(t *testing.T) {
+	var requests int
+	client := newTestClient(t, filesTransport(5000, &requests))
+
+	files, err := prview.FetchPRFiles(context.Background(), client, testRepo, 7, 0)
+	if err != nil {
+		t.Fatalf("FetchPRFiles returned an error: %v", err)
+	}
+	if len(files) != 3000 || requests != 30 {
+		t.Errorf("Expected to stop at GitHub's 3000 file cap, got %d files in %d requests", len(files), requests)
+	}
+}
+
+func TestFetchPRFilesPerPage(t *testing.T) {
+	tests := []struct {
+		name    string
+		perPage int
+		want    string
+	}{
+		{"default", 0, "100"},
+		{"within range", 25, "25"},
+		{"above maximum", 250, "100"},
+		{"negative", -3, "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			client := newTestClient(t, roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				got = append(got, req.URL.Query().Get("per_page"))
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{"Content-Type": []string{"application/json"}},
+					Body:       io.NopCloser(strings.NewReader("[]")),
+					Request:    req,
+				}, nil
+			}))
+
+			if _, err := prview.FetchPRFiles(context.Background(), client, testRepo, 7, tt.perPage); err != nil {
+				t.Fatalf("FetchPRFiles returned an error: %v", err)
+			}
+			if len(got) != 1 || got[0] != tt.want {
+				t.Errorf("Expected per_page=%s for %d, got %v", tt.want, tt.perPage, got)
+			}
+		})
+	}
+}
+
+func TestRenderFilesTruncated(t *testing.T) {
+	pr := createMockPR()
+	pr.ChangedFiles = 3500
+	pr.Files = make([]prview.ChangedFile, 3000)
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "\n(file list truncated at 3000 by GitHub)\n") {
+		t.Errorf("Expected a truncation notice, got:\n%s", buf.String())
+	}
+
+	// The notice doesn't depend on the files having been fetched
+	pr.Files = nil
+	buf.Reset()
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "\n(file list truncated at 3000 by GitHub)\n") {
+		t.Errorf("Expected a truncation notice without the files, got:\n%s", buf.String())
+	}
+
+	pr.ChangedFiles = 3000
+	buf.Reset()
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if strings.Contains(buf.String(), "truncated") {
+		t.Errorf("Didn't expect a notice for a complete list, got:\n%s", buf.String())
+	}
+}
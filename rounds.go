@@ -0,0 +1,57 @@
+package prview
+
+import (
+	"fmt"
+	"time"
+)
+
+// roundStarts splits the timeline's blocks into review rounds and returns the
+// index of the block each round starts at. A new round starts with a review
+// submitted more than gap after the one before it, and takes in everything
+// after that review's round went quiet for gap, so comments stay with the
+// round they were part of. It returns nil when there is only one round.
+func roundStarts(blocks [][]TimelineItem, gap time.Duration) []int {
+	starts := []int{0}
+	var last time.Time
+	pending := -1
+	for i, block := range blocks {
+		if !last.IsZero() && pending < 0 && block[0].CreatedAt.Sub(last) > gap {
+			pending = i
+		}
+		for _, item := range block {
+			if item.Type != "review" {
+				continue
+			}
+			if pending >= 0 {
+				starts = append(starts, pending)
+				pending = -1
+			}
+			last = item.CreatedAt
+		}
+	}
+	if len(starts) < 2 {
+		return nil
+	}
+	return starts
+}
+
+// rounds maps the index of each block that starts a review round, with
+// opts.RoundGap set, to the number of that round
+func rounds(blocks [][]TimelineItem, opts RenderOptions) map[int]int {
+	if opts.RoundGap <= 0 {
+		return nil
+	}
+	numbers := make(map[int]int)
+	for n, start := range roundStarts(blocks, opts.RoundGap) {
+		numbers[start] = n + 1
+	}
+	return numbers
+}
+
+// roundSeparator is the line shown before review round n
+func roundSeparator(n int, opts RenderOptions) string {
+	if opts.ASCII {
+		return fmt.Sprintf("-- Round %d --", n)
+	}
+	return fmt.Sprintf("── Round %d ──", n)
+}
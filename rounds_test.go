@@ -0,0 +1,61 @@
+package prview_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestRenderRounds(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	pr := prview.PullRequest{
+		Number: 1,
+		Title:  "Rounds",
+		Comments: []prview.Comment{
+			{ID: 1, Body: "Fixed, thanks", CreatedAt: at.Add(2 * time.Hour), User: prview.User{Login: "author"}},
+			{ID: 2, Body: "Pushed the rework", CreatedAt: at.Add(72 * time.Hour), User: prview.User{Login: "author"}},
+		},
+		Reviews: []prview.Review{
+			{ID: 10, State: "CHANGES_REQUESTED", Body: "First pass", SubmittedAt: at, User: prview.User{Login: "alice"}},
+			{ID: 11, State: "COMMENTED", Body: "Also this", SubmittedAt: at.Add(time.Hour), User: prview.User{Login: "bob"}},
+			{ID: 12, State: "APPROVED", Body: "Second pass", SubmittedAt: at.Add(73 * time.Hour), User: prview.User{Login: "alice"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{NoHeader: true, RoundGap: 24 * time.Hour}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output := buf.String()
+
+	if n := strings.Count(output, "── Round "); n != 2 {
+		t.Fatalf("Expected 2 round separators, got %d:\n%s", n, output)
+	}
+	first, second := strings.Index(output, "── Round 1 ──"), strings.Index(output, "── Round 2 ──")
+	if first != 0 || second < 0 {
+		t.Fatalf("Expected rounds 1 and 2 with round 1 first, got:\n%s", output)
+	}
+	for _, body := range []string{"First pass", "Also this", "Fixed, thanks"} {
+		if i := strings.Index(output, body); i > second {
+			t.Errorf("Expected %q in round 1, got:\n%s", body, output)
+		}
+	}
+	for _, body := range []string{"Pushed the rework", "Second pass"} {
+		if i := strings.Index(output, body); i < second {
+			t.Errorf("Expected %q in round 2, got:\n%s", body, output)
+		}
+	}
+}
+
+func TestRenderRoundsSingleRound(t *testing.T) {
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, createMockPR(), prview.RenderOptions{RoundGap: 24 * time.Hour}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if strings.Contains(buf.String(), "Round") {
+		t.Errorf("Expected no separators for a single round, got:\n%s", buf.String())
+	}
+}
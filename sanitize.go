@@ -0,0 +1,41 @@
+package prview
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sanitizeForTerminal makes user-supplied text safe to write to a terminal.
+// Line endings are normalized, and control characters other than newline and
+// tab are replaced with a visible escape so embedded ANSI sequences can't
+// move the cursor, recolor output or retitle the window. Unicode
+// bidirectional overrides are escaped too, as they can make text display in
+// a different order than it reads. The tool's own coloring is applied after
+// sanitizing and is unaffected.
+func sanitizeForTerminal(s string) string {
+	s = normalizeNewlines(s)
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r == '\n' || r == '\t':
+			b.WriteRune(r)
+		case r < 0x20:
+			// Caret notation, e.g. ESC becomes ^[
+			b.WriteByte('^')
+			b.WriteRune(r + 0x40)
+		case r == 0x7f:
+			b.WriteString("^?")
+		case r >= 0x80 && r <= 0x9f, isBidiControl(r):
+			fmt.Fprintf(&b, "\\u%04x", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func isBidiControl(r rune) bool {
+	return (r >= 0x202a && r <= 0x202e) || (r >= 0x2066 && r <= 0x2069)
+}
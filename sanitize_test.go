@@ -0,0 +1,54 @@
+package prview_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestSanitizeForTerminal(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"plain text\n\twith tab", "plain text\n\twith tab"},
+		{"red \x1b[31malert\x1b[0m", "red ^[[31malert^[[0m"},
+		{"title \x1b]0;pwned\x07", "title ^[]0;pwned^G"},
+		{"crlf\r\nline", "crlf\nline"},
+		{"c1 \u009b31m", "c1 \\u009b31m"},
+		{"bidi \u202eevil", "bidi \\u202eevil"},
+		{"del\x7f", "del^?"},
+		{"emoji 👋 and ünïcödé", "emoji 👋 and ünïcödé"},
+	}
+
+	for _, tt := range tests {
+		if got := prview.SanitizeForTerminal(tt.input); got != tt.expected {
+			t.Errorf("SanitizeForTerminal(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestRenderPRSanitizesEscapes(t *testing.T) {
+	pr := prview.PullRequest{
+		Title: "Innocent \x1b[2J title",
+		Comments: []prview.Comment{
+			{ID: 1, Body: "Look \x1b[31mhere\x1b[0m", CreatedAt: time.Now()},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output := buf.String()
+
+	if strings.Contains(output, "\x1b") {
+		t.Errorf("Expected no raw escape characters in output, got %q", output)
+	}
+	if !strings.Contains(output, "Look ^[[31mhere^[[0m") {
+		t.Errorf("Expected the escape sequence to be shown neutralized, got %q", output)
+	}
+}
@@ -0,0 +1,81 @@
+package prview
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultSeverityKeywords ranks the prefixes reviewers use to tag comments,
+// lower values sorting first. Comments without a known prefix sort last.
+var DefaultSeverityKeywords = map[string]int{
+	"blocker":  0,
+	"question": 1,
+	"nit":      2,
+}
+
+// SeverityRanks ranks keywords in the order given, most severe first, for
+// use with SortBySeverity
+func SeverityRanks(keywords []string) map[string]int {
+	ranks := make(map[string]int, len(keywords))
+	for i, keyword := range keywords {
+		keyword = strings.ToLower(keyword)
+		if _, ok := ranks[keyword]; !ok {
+			ranks[keyword] = i
+		}
+	}
+	return ranks
+}
+
+// severityPrefix matches a leading "keyword:" tag, allowing for Markdown
+// emphasis or brackets around the keyword such as "**nit**:" or "[blocker]:"
+var severityPrefix = regexp.MustCompile(`^[\s*_\[(]*([A-Za-z-]+)[\s*_\])]*:`)
+
+// SortBySeverity returns a copy of the PR with each review's threads ordered
+// by the severity of their opening comment, according to keywords. Threads
+// of equal severity are ordered by file and line.
+func SortBySeverity(pr PullRequest, keywords map[string]int) PullRequest {
+	reviews := make([]Review, len(pr.Reviews))
+	for i, review := range pr.Reviews {
+		threads := append([]CommentThread(nil), review.Threads...)
+		sort.SliceStable(threads, func(a, b int) bool {
+			ra, rb := threads[a].Comments[0], threads[b].Comments[0]
+			if sa, sb := severity(ra.Body, keywords), severity(rb.Body, keywords); sa != sb {
+				return sa < sb
+			}
+			if ra.Path != rb.Path {
+				return ra.Path < rb.Path
+			}
+			return commentLine(ra) < commentLine(rb)
+		})
+		review.Threads = threads
+		reviews[i] = review
+	}
+	pr.Reviews = reviews
+
+	return pr
+}
+
+func severity(body string, keywords map[string]int) int {
+	match := severityPrefix.FindStringSubmatch(body)
+	if match == nil {
+		return math.MaxInt
+	}
+	if rank, ok := keywords[strings.ToLower(match[1])]; ok {
+		return rank
+	}
+	return math.MaxInt
+}
+
+// commentLine returns the line a review comment refers to, falling back to
+// its original line for outdated comments
+func commentLine(c Comment) int {
+	if c.Line != nil {
+		return *c.Line
+	}
+	if c.OriginalLine != nil {
+		return *c.OriginalLine
+	}
+	return 0
+}
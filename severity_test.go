@@ -0,0 +1,49 @@
+package prview_test
+
+import (
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestSortBySeverity(t *testing.T) {
+	now := time.Now()
+	thread := func(id int64, line int, body string) prview.CommentThread {
+		return prview.CommentThread{Comments: []prview.Comment{
+			{ID: id, Body: body, CreatedAt: now, Path: "main.go", Line: intPtr(line)},
+		}}
+	}
+
+	pr := prview.PullRequest{
+		Reviews: []prview.Review{
+			{
+				ID: 101,
+				Threads: []prview.CommentThread{
+					thread(1, 5, "nit: trailing whitespace"),
+					thread(2, 30, "This could be simpler"),
+					thread(3, 20, "**Blocker**: this panics on nil input"),
+					thread(4, 10, "Maybe rename this"),
+					thread(5, 40, "question: why not reuse the helper?"),
+				},
+			},
+		},
+	}
+
+	sorted := prview.SortBySeverity(pr, prview.DefaultSeverityKeywords)
+
+	var ids []int64
+	for _, thread := range sorted.Reviews[0].Threads {
+		ids = append(ids, thread.Comments[0].ID)
+	}
+
+	expected := []int64{3, 5, 1, 4, 2}
+	for i := range expected {
+		if i >= len(ids) || ids[i] != expected[i] {
+			t.Fatalf("Expected thread order %v, got %v", expected, ids)
+		}
+	}
+	if pr.Reviews[0].Threads[0].Comments[0].ID != 1 {
+		t.Errorf("Expected the original PR to be left untouched")
+	}
+}
@@ -0,0 +1,61 @@
+package prview
+
+import (
+	"fmt"
+	"time"
+)
+
+// LastActivity returns when anything last happened on the PR: the latest of
+// when it was created, updated or merged and when each of its comments,
+// reviews, commits and cross-references was made
+func LastActivity(pr PullRequest) time.Time {
+	last := pr.CreatedAt
+	seen := func(t time.Time) {
+		if t.After(last) {
+			last = t
+		}
+	}
+
+	seen(pr.UpdatedAt)
+	if pr.MergedAt != nil {
+		seen(*pr.MergedAt)
+	}
+	for _, c := range pr.Comments {
+		seen(c.CreatedAt)
+	}
+	for _, r := range pr.Reviews {
+		seen(r.SubmittedAt)
+		for _, thread := range r.Threads {
+			for _, c := range thread.Comments {
+				seen(c.CreatedAt)
+			}
+		}
+	}
+	for _, c := range pr.Commits {
+		seen(c.CreatedAt)
+	}
+	for _, ref := range pr.References {
+		seen(ref.CreatedAt)
+	}
+	return last
+}
+
+// IsStale reports whether there has been no activity on the PR for more than
+// days days. It is always false when days isn't positive.
+func IsStale(pr PullRequest, days int) bool {
+	return days > 0 && now().Sub(LastActivity(pr)) > time.Duration(days)*24*time.Hour
+}
+
+// lastActivity describes how long ago the PR last saw activity for the
+// header, flagging it as stale past opts.StaleDays
+func lastActivity(pr PullRequest, opts RenderOptions) string {
+	last := LastActivity(pr)
+	if last.IsZero() {
+		return ""
+	}
+	line := relativeTime(last, now())
+	if IsStale(pr, opts.StaleDays) {
+		line += fmt.Sprintf(" (stale: over %s)", pluralize(opts.StaleDays, "day"))
+	}
+	return line
+}
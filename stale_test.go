@@ -0,0 +1,49 @@
+package prview_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestLastActivity(t *testing.T) {
+	created := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	newest := created.Add(72 * time.Hour)
+	pr := prview.PullRequest{
+		Number:    1,
+		Title:     "Stale",
+		CreatedAt: created,
+		UpdatedAt: created.Add(time.Hour),
+		Comments:  []prview.Comment{{ID: 1, CreatedAt: created.Add(2 * time.Hour)}},
+		Reviews: []prview.Review{{ID: 10, State: "COMMENTED", SubmittedAt: created.Add(3 * time.Hour), Threads: []prview.CommentThread{
+			{Comments: []prview.Comment{{ID: 2, CreatedAt: created.Add(3 * time.Hour)}, {ID: 3, CreatedAt: newest}}},
+		}}},
+		Commits: []prview.Commit{{SHA: "abc", CreatedAt: created.Add(24 * time.Hour)}},
+	}
+
+	if got := prview.LastActivity(pr); !got.Equal(newest) {
+		t.Errorf("Expected the last activity at %s, got %s", newest, got)
+	}
+
+	prview.SetClock(t, newest.Add(12*24*time.Hour))
+	if prview.IsStale(pr, 14) {
+		t.Errorf("Didn't expect a PR idle for 12 days to be stale after 14")
+	}
+	if !prview.IsStale(pr, 10) {
+		t.Errorf("Expected a PR idle for 12 days to be stale after 10")
+	}
+	if prview.IsStale(pr, 0) {
+		t.Errorf("Didn't expect staleness to be checked without a threshold")
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{StaleDays: 10}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "\nLast activity: 12 days ago (stale: over 10 days)\n") {
+		t.Errorf("Expected the last activity in the header, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,65 @@
+package prview
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Stats summarizes where a PR stands
+type Stats struct {
+	// State is open, closed or merged
+	State string
+	// Approvals and ChangesRequested count the reviewers whose latest
+	// verdict approves or requests changes
+	Approvals        int
+	ChangesRequested int
+}
+
+// ComputeStats summarizes the PR's state and its reviewers' verdicts
+func ComputeStats(pr PullRequest) Stats {
+	stats := Stats{State: pr.State}
+	if pr.MergedAt != nil {
+		stats.State = "merged"
+	}
+	for _, state := range LatestReviewStates(pr) {
+		switch state {
+		case "APPROVED":
+			stats.Approvals++
+		case "CHANGES_REQUESTED":
+			stats.ChangesRequested++
+		}
+	}
+	return stats
+}
+
+// shellSafe matches values that need no quoting in a shell assignment
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_.,:/@%+-]+$`)
+
+// shellQuote quotes s for use as a shell word, leaving it bare if it is safe
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// RenderEval writes the PR's stats as shell variable assignments, one per
+// line, for scripts to eval
+func RenderEval(w io.Writer, pr PullRequest) error {
+	stats := ComputeStats(pr)
+	vars := []struct {
+		name, value string
+	}{
+		{"PRVIEW_STATE", stats.State},
+		{"PRVIEW_APPROVALS", fmt.Sprint(stats.Approvals)},
+		{"PRVIEW_CHANGES", fmt.Sprint(stats.ChangesRequested)},
+	}
+	for _, v := range vars {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", v.name, shellQuote(v.value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package prview_test
+
+import (
+	"bytes"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestRenderEval(t *testing.T) {
+	pr := prview.PullRequest{
+		Number: 1,
+		State:  "open",
+		Reviews: []prview.Review{
+			{ID: 1, State: "APPROVED", User: prview.User{Login: "alice"}},
+			{ID: 2, State: "APPROVED", User: prview.User{Login: "bob"}},
+			{ID: 3, State: "COMMENTED", User: prview.User{Login: "carol"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderEval(&buf, pr); err != nil {
+		t.Fatalf("RenderEval returned an error: %v", err)
+	}
+	expected := "PRVIEW_STATE=open\nPRVIEW_APPROVALS=2\nPRVIEW_CHANGES=0\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestRenderEvalQuotes(t *testing.T) {
+	for state, want := range map[string]string{
+		"in review":  "PRVIEW_STATE='in review'\n",
+		"it's $HOME": `PRVIEW_STATE='it'\''s $HOME'` + "\n",
+		"":           "PRVIEW_STATE=''\n",
+	} {
+		var buf bytes.Buffer
+		if err := prview.RenderEval(&buf, prview.PullRequest{State: state}); err != nil {
+			t.Fatalf("RenderEval returned an error: %v", err)
+		}
+		if line, _, _ := bytes.Cut(buf.Bytes(), []byte("PRVIEW_APPROVALS")); string(line) != want {
+			t.Errorf("Expected state %q to be quoted as %q, got %q", state, want, line)
+		}
+	}
+}
+
+func TestComputeStatsMerged(t *testing.T) {
+	pr := createMockPR()
+	pr.State = "closed"
+	pr.MergedAt = &pr.CreatedAt
+	pr.Reviews = append(pr.Reviews, prview.Review{ID: 2, State: "CHANGES_REQUESTED", User: prview.User{Login: "carol"}})
+
+	stats := prview.ComputeStats(pr)
+	if stats.State != "merged" || stats.Approvals != 1 || stats.ChangesRequested != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
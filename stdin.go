@@ -0,0 +1,76 @@
+package prview
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// prDocument is the schema ReadPR accepts. Each list holds the API's own
+// representation, as returned by the corresponding endpoint:
+//
+//	pull_request     repos/{owner}/{repo}/pulls/{number}
+//	comments         repos/{owner}/{repo}/issues/{number}/comments
+//	reviews          repos/{owner}/{repo}/pulls/{number}/reviews
+//	review_comments  repos/{owner}/{repo}/pulls/{number}/comments
+//	commits          repos/{owner}/{repo}/pulls/{number}/commits
+type prDocument struct {
+	PullRequest    *PullRequest     `json:"pull_request"`
+	Comments       []Comment        `json:"comments"`
+	Reviews        []Review         `json:"reviews"`
+	ReviewComments []Comment        `json:"review_comments"`
+	Commits        []commitResponse `json:"commits"`
+}
+
+// ReadPR reads a PR from r instead of the API. The input is either the JSON
+// of a single pull request, e.g. piped from gh api, or an object with the PR
+// under "pull_request" alongside any of "comments", "reviews",
+// "review_comments" and "commits".
+func ReadPR(r io.Reader) (PullRequest, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return PullRequest{}, fmt.Errorf("error reading PR data: %w", err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return PullRequest{}, fmt.Errorf("invalid PR data: %w", err)
+	}
+
+	var doc prDocument
+	if _, ok := fields["pull_request"]; ok {
+		err = json.Unmarshal(data, &doc)
+	} else {
+		doc.PullRequest = &PullRequest{}
+		err = json.Unmarshal(data, doc.PullRequest)
+	}
+	if err != nil {
+		return PullRequest{}, fmt.Errorf("invalid PR data: %w", err)
+	}
+	if err := doc.validate(); err != nil {
+		return PullRequest{}, fmt.Errorf("invalid PR data: %w", err)
+	}
+
+	pr := *doc.PullRequest
+	pr.Comments = doc.Comments
+	attachThreads(doc.Reviews, doc.ReviewComments)
+	pr.Reviews = doc.Reviews
+	pr.Commits = commitsFromResponses(doc.Commits)
+	return pr, nil
+}
+
+func (doc prDocument) validate() error {
+	if doc.PullRequest == nil {
+		return errors.New("pull_request must be an object")
+	}
+	if doc.PullRequest.Number <= 0 {
+		return errors.New("the pull request has no number")
+	}
+	for _, c := range doc.ReviewComments {
+		if c.PullRequestReviewID == 0 {
+			return fmt.Errorf("review comment %d has no pull_request_review_id", c.ID)
+		}
+	}
+	return nil
+}
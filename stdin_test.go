@@ -0,0 +1,63 @@
+package prview_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestReadPR(t *testing.T) {
+	input := `{
+		"pull_request": {"number": 42, "title": "Piped PR", "body": "From stdin", "user": {"login": "author"}},
+		"comments": [{"id": 1, "body": "Issue comment", "created_at": "2024-01-01T10:00:00Z", "user": {"login": "alice"}}],
+		"reviews": [{"id": 10, "state": "APPROVED", "submitted_at": "2024-01-01T11:00:00Z", "user": {"login": "bob"}}],
+		"review_comments": [{"id": 2, "body": "Inline", "path": "a.go", "diff_hunk": "@@ -1 +1 @@\n+x", "pull_request_review_id": 10, "user": {"login": "bob"}}],
+		"commits": [{"sha": "abc1234", "commit": {"message": "Initial commit", "committer": {"date": "2024-01-01T09:00:00Z"}}, "author": {"login": "author"}}]
+	}`
+
+	pr, err := prview.ReadPR(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadPR returned an error: %v", err)
+	}
+	if len(pr.Reviews) != 1 || len(pr.Reviews[0].Threads) != 1 {
+		t.Fatalf("Expected the review comment to be threaded under its review, got %+v", pr.Reviews)
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output := buf.String()
+	for _, expected := range []string{"PR #42: Piped PR", "From stdin", "alice COMMENTED", "bob APPROVED", "Inline", "Initial commit"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestReadPRRaw(t *testing.T) {
+	pr, err := prview.ReadPR(strings.NewReader(`{"number": 7, "title": "Raw PR", "user": {"login": "author"}}`))
+	if err != nil {
+		t.Fatalf("ReadPR returned an error: %v", err)
+	}
+	if pr.Number != 7 || pr.Title != "Raw PR" {
+		t.Errorf("Unexpected PR: %+v", pr)
+	}
+}
+
+func TestReadPRInvalid(t *testing.T) {
+	tests := []string{
+		`not json`,
+		`[]`,
+		`{"title": "No number"}`,
+		`{"pull_request": null}`,
+		`{"pull_request": {"number": 1}, "review_comments": [{"id": 2}]}`,
+	}
+	for _, input := range tests {
+		if _, err := prview.ReadPR(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected an error for %s", input)
+		}
+	}
+}
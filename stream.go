@@ -0,0 +1,80 @@
+package prview
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// pageSize returns the page size to request of an API listing at most most
+// items per page: perPage clamped to between 1 and most, or most when
+// perPage is zero
+func pageSize(perPage, most int) int {
+	if perPage == 0 {
+		return most
+	}
+	return min(max(perPage, 1), most)
+}
+
+// restPageSize is the most items GitHub's REST listings return per page
+const restPageSize = 100
+
+// streamPages GETs every page of a REST listing at path, restPageSize items
+// at a time, decoding each page like streamArray. It stops at the first
+// page that isn't full.
+func streamPages[T any](ctx context.Context, client *api.RESTClient, path string) ([]T, error) {
+	var items []T
+	for page := 1; ; page++ {
+		batch, err := streamArray[T](ctx, client, fmt.Sprintf("%s?per_page=%d&page=%d", path, restPageSize, page), 0)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, batch...)
+		if len(batch) < restPageSize {
+			return items, nil
+		}
+	}
+}
+
+// streamArray GETs a JSON array from the API and decodes it one element at
+// a time, so the raw response is never held in memory all at once. When
+// limit is positive it stops reading after that many elements.
+func streamArray[T any](ctx context.Context, client *api.RESTClient, path string, limit int) ([]T, error) {
+	resp, err := client.RequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, tagAPIError(err)
+	}
+	defer resp.Body.Close()
+
+	return decodeArray[T](resp.Body, limit)
+}
+
+// decodeArray decodes the elements of a JSON array from r, stopping after
+// limit elements when limit is positive
+func decodeArray[T any](r io.Reader, limit int) ([]T, error) {
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("error decoding response: expected a JSON array, got %v", tok)
+	}
+
+	var items []T
+	for dec.More() {
+		if limit > 0 && len(items) >= limit {
+			break
+		}
+		var item T
+		if err := dec.Decode(&item); err != nil {
+			return nil, fmt.Errorf("error decoding response: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -0,0 +1,84 @@
+package prview_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+// largeCommentArray builds the JSON for n issue comments
+func largeCommentArray(n int) string {
+	var b strings.Builder
+	b.WriteString("[")
+	for i := 1; i <= n; i++ {
+		if i > 1 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `{"id": %d, "body": "Comment %d", "user": {"login": "user%d"}}`, i, i, i%7)
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// countingReader records how many bytes have been read from it
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestFetchPRCommentsLarge(t *testing.T) {
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/issues/7/comments": largeCommentArray(5000),
+	}}
+
+	comments, err := prview.FetchPRComments(context.Background(), newTestClient(t, rt), testRepo, 7, 0)
+	if err != nil {
+		t.Fatalf("FetchPRComments returned an error: %v", err)
+	}
+	if len(comments) != 5000 {
+		t.Fatalf("Expected 5000 comments, got %d", len(comments))
+	}
+	if comments[4999].ID != 5000 || comments[4999].Body != "Comment 5000" || comments[4999].User.Login != "user2" {
+		t.Errorf("Unexpected last comment: %+v", comments[4999])
+	}
+
+	limited, err := prview.FetchPRComments(context.Background(), newTestClient(t, rt), testRepo, 7, 10)
+	if err != nil {
+		t.Fatalf("FetchPRComments returned an error: %v", err)
+	}
+	if len(limited) != 10 || limited[9].ID != 10 {
+		t.Errorf("Expected the first 10 comments, got %d", len(limited))
+	}
+}
+
+func TestDecodeArrayLimitStopsReading(t *testing.T) {
+	data := largeCommentArray(5000)
+	r := &countingReader{r: strings.NewReader(data)}
+
+	comments, err := prview.DecodeComments(r, 3)
+	if err != nil {
+		t.Fatalf("DecodeComments returned an error: %v", err)
+	}
+	if len(comments) != 3 || comments[2].ID != 3 {
+		t.Fatalf("Expected the first 3 comments, got %+v", comments)
+	}
+	if r.n >= len(data)/2 {
+		t.Errorf("Expected decoding to stop early, read %d of %d bytes", r.n, len(data))
+	}
+}
+
+func TestDecodeArrayNotArray(t *testing.T) {
+	if _, err := prview.DecodeComments(strings.NewReader(`{"message": "oops"}`), 0); err == nil {
+		t.Error("Expected an error for a non-array response")
+	}
+}
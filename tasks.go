@@ -0,0 +1,110 @@
+package prview
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// taskItem matches a Markdown task list item at any level of nesting,
+// capturing the box's contents
+var taskItem = regexp.MustCompile(`^[ \t]*(?:>[ \t]*)*(?:[-*+]|\d+[.)])[ \t]+\[([ xX])\](?:[ \t]|$)`)
+
+// TaskProgress counts the task list items in some Markdown
+type TaskProgress struct {
+	Done  int
+	Total int
+}
+
+// task is a single task list item
+type task struct {
+	Text string
+	Done bool
+}
+
+// CountTasks counts the checked and total task list items in body, including
+// nested and indented ones. Items inside fenced code blocks are ignored.
+func CountTasks(body string) TaskProgress {
+	var progress TaskProgress
+	for _, t := range tasks(body) {
+		progress.Total++
+		if t.Done {
+			progress.Done++
+		}
+	}
+	return progress
+}
+
+// UncheckedTasks returns the text of each task list item in body that
+// hasn't been checked off, in order
+func UncheckedTasks(body string) []string {
+	var unchecked []string
+	for _, t := range tasks(body) {
+		if !t.Done {
+			unchecked = append(unchecked, t.Text)
+		}
+	}
+	return unchecked
+}
+
+// WarnUnchecked writes a warning listing the unchecked task list items in
+// the PR body, often left over from a PR template, or nothing when they are
+// all checked
+func WarnUnchecked(w io.Writer, pr PullRequest) {
+	unchecked := UncheckedTasks(pr.Body)
+	if len(unchecked) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "Warning: PR #%d has %s:\n", pr.Number, pluralize(len(unchecked), "unchecked task"))
+	for _, text := range unchecked {
+		fmt.Fprintf(w, "  - [ ] %s\n", sanitizeForTerminal(text))
+	}
+}
+
+// tasks returns the task list items in body, skipping fenced code blocks
+func tasks(body string) []task {
+	var items []task
+	inFence := false
+
+	for _, line := range strings.Split(normalizeNewlines(body), "\n") {
+		if isFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		match := taskItem.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		items = append(items, task{
+			Text: strings.TrimSpace(line[len(match[0]):]),
+			Done: match[1] != " ",
+		})
+	}
+
+	return items
+}
+
+// isFence reports whether line opens or closes a fenced code block
+func isFence(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+}
+
+// PRTasks counts the task list items in the PR body, and in its issue
+// comments too when includeComments is set
+func PRTasks(pr PullRequest, includeComments bool) TaskProgress {
+	progress := CountTasks(pr.Body)
+	if includeComments {
+		for _, c := range pr.Comments {
+			p := CountTasks(c.Body)
+			progress.Done += p.Done
+			progress.Total += p.Total
+		}
+	}
+	return progress
+}
@@ -0,0 +1,88 @@
+package prview_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestCountTasks(t *testing.T) {
+	body := "## Checklist\n" +
+		"- [x] Write the code\n" +
+		"- [ ] Write the tests\n" +
+		"  - [X] Unit tests\n" +
+		"    * [ ] Integration tests\n" +
+		"1. [x] Update the docs\r\n" +
+		"- [] Not a task\n" +
+		"- [x]Not a task either\n" +
+		"```\n- [ ] Example in a code block\n```\n"
+
+	progress := prview.CountTasks(body)
+	if progress.Done != 3 || progress.Total != 5 {
+		t.Errorf("Expected 3/5 tasks, got %d/%d", progress.Done, progress.Total)
+	}
+}
+
+func TestRenderTaskProgress(t *testing.T) {
+	pr := prview.PullRequest{
+		Number:   1,
+		Title:    "Tasks",
+		Body:     "- [x] One\n- [ ] Two",
+		User:     prview.User{Login: "author"},
+		Comments: []prview.Comment{{ID: 1, Body: "- [x] Three", User: prview.User{Login: "alice"}}},
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "\nTasks: 1/2 complete\n") {
+		t.Errorf("Expected the body's task progress in the header, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{CommentTasks: true}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "\nTasks: 2/3 complete\n") {
+		t.Errorf("Expected comment tasks to be counted, got:\n%s", buf.String())
+	}
+
+	pr.Body = "No tasks here"
+	pr.Comments = nil
+	buf.Reset()
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	if strings.Contains(buf.String(), "Tasks:") {
+		t.Errorf("Expected no task line without tasks, got:\n%s", buf.String())
+	}
+}
+
+func TestWarnUnchecked(t *testing.T) {
+	pr := prview.PullRequest{
+		Number: 7,
+		Body: "## Checklist\n" +
+			"- [x] Tests pass\n" +
+			"- [ ] Updated the changelog\n" +
+			"  - [ ] Bumped the version\n" +
+			"```\n- [ ] Not a real task\n```\n",
+	}
+
+	var buf bytes.Buffer
+	prview.WarnUnchecked(&buf, pr)
+	expected := "Warning: PR #7 has 2 unchecked tasks:\n" +
+		"  - [ ] Updated the changelog\n" +
+		"  - [ ] Bumped the version\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, buf.String())
+	}
+
+	buf.Reset()
+	prview.WarnUnchecked(&buf, prview.PullRequest{Number: 7, Body: "- [x] Done"})
+	if buf.Len() != 0 {
+		t.Errorf("Expected no warning when every task is checked, got %q", buf.String())
+	}
+}
@@ -0,0 +1,178 @@
+package prview
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// teamMentionPattern matches an @org/team mention, capturing what precedes
+// it, the organization and the team's slug
+var teamMentionPattern = regexp.MustCompile(`(^|[^\w@/])@([A-Za-z0-9][A-Za-z0-9-]*)/([A-Za-z0-9][\w.-]*[\w-]|[A-Za-z0-9])`)
+
+// maxListedMembers is the most team members named after a mention; larger
+// teams get a count instead
+const maxListedMembers = 5
+
+// FetchTeamMembers retrieves all the members of an organization's team,
+// given by its slug
+func FetchTeamMembers(ctx context.Context, client *api.RESTClient, org, team string) ([]User, error) {
+	return streamPages[User](ctx, client, fmt.Sprintf("orgs/%s/teams/%s/members", org, team))
+}
+
+// resolveTeamMentions fetches the members of each team an @org/team mention
+// in the PR's bodies names, recording their logins in pr.Teams for the text
+// output to show beside the mentions. Each team is fetched once. Teams the
+// token may not see are left out; any other failure is returned.
+func resolveTeamMentions(ctx context.Context, client *api.RESTClient, pr *PullRequest) error {
+	teams := make(map[string][]string)
+	tried := make(map[string]bool)
+	var firstErr error
+	eachBody(*pr, func(body string) {
+		forTeamMentions(body, func(mention, org, team string) string {
+			key := strings.ToLower(org + "/" + team)
+			if tried[key] || firstErr != nil {
+				return mention
+			}
+			tried[key] = true
+			members, err := FetchTeamMembers(ctx, client, org, team)
+			if err != nil {
+				if !isInaccessible(err) {
+					firstErr = fmt.Errorf("error fetching the members of %s/%s: %w", org, team, err)
+				}
+				return mention
+			}
+			logins := make([]string, len(members))
+			for i, member := range members {
+				logins[i] = member.Login
+			}
+			teams[key] = logins
+			return mention
+		})
+	})
+	if firstErr != nil {
+		return firstErr
+	}
+	if len(teams) > 0 {
+		pr.Teams = teams
+	}
+	return nil
+}
+
+// eachBody calls fn with the PR's body and those of its comments and
+// reviews, including the pending one
+func eachBody(pr PullRequest, fn func(body string)) {
+	fn(pr.Body)
+	for _, c := range pr.Comments {
+		fn(c.Body)
+	}
+	review := func(r Review) {
+		fn(r.Body)
+		for _, thread := range r.Threads {
+			for _, c := range thread.Comments {
+				fn(c.Body)
+			}
+		}
+	}
+	for _, r := range pr.Reviews {
+		review(r)
+	}
+	if pr.PendingReview != nil {
+		review(*pr.PendingReview)
+	}
+}
+
+// forTeamMentions replaces each @org/team mention in body with what fn
+// returns for it. Mentions in code, fenced or inline, aren't mentions and
+// are left alone.
+func forTeamMentions(body string, fn func(mention, org, team string) string) string {
+	lines := strings.Split(body, "\n")
+	inFence := false
+	for i, line := range lines {
+		if isFence(line) {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		// Between backticks is inline code, bar a trailing unmatched one
+		spans := strings.Split(line, "`")
+		for j := 0; j < len(spans); j += 2 {
+			if j > 0 && j == len(spans)-1 && len(spans)%2 == 0 {
+				break
+			}
+			spans[j] = teamMentionPattern.ReplaceAllStringFunc(spans[j], func(mention string) string {
+				m := teamMentionPattern.FindStringSubmatch(mention)
+				return fn(mention, m[2], m[3])
+			})
+		}
+		lines[i] = strings.Join(spans, "`")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// withTeamMembers returns a copy of the PR with each mention of a team in
+// pr.Teams followed by who is on it, e.g. "@acme/core (alice, bob)"
+func withTeamMembers(pr PullRequest) PullRequest {
+	if len(pr.Teams) == 0 {
+		return pr
+	}
+	annotate := func(body string) string {
+		return forTeamMentions(body, func(mention, org, team string) string {
+			members, ok := pr.Teams[strings.ToLower(org+"/"+team)]
+			if !ok {
+				return mention
+			}
+			return mention + " (" + teamNote(members) + ")"
+		})
+	}
+	review := func(r Review) Review {
+		r.Body = annotate(r.Body)
+		threads := make([]CommentThread, len(r.Threads))
+		for i, thread := range r.Threads {
+			threads[i] = thread
+			threads[i].Comments = make([]Comment, len(thread.Comments))
+			for j, c := range thread.Comments {
+				c.Body = annotate(c.Body)
+				threads[i].Comments[j] = c
+			}
+		}
+		r.Threads = threads
+		return r
+	}
+
+	pr.Body = annotate(pr.Body)
+	comments := make([]Comment, len(pr.Comments))
+	for i, c := range pr.Comments {
+		c.Body = annotate(c.Body)
+		comments[i] = c
+	}
+	pr.Comments = comments
+	reviews := make([]Review, len(pr.Reviews))
+	for i, r := range pr.Reviews {
+		reviews[i] = review(r)
+	}
+	pr.Reviews = reviews
+	if pr.PendingReview != nil {
+		pending := review(*pr.PendingReview)
+		pr.PendingReview = &pending
+	}
+	return pr
+}
+
+// teamNote describes a team's members: their logins if there are few, or
+// how many there are
+func teamNote(members []string) string {
+	if len(members) == 0 {
+		return "no members"
+	}
+	if len(members) > maxListedMembers {
+		return pluralize(len(members), "member")
+	}
+	return strings.Join(members, ", ")
+}
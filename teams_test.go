@@ -0,0 +1,127 @@
+package prview_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestLoadPRResolveTeams(t *testing.T) {
+	setTestAuth(t)
+	rt := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7":           `{"number": 7, "title": "Teams", "body": "cc @acme/core and @acme/everyone, but not @acme/secret.", "user": {"login": "author"}}`,
+		"/repos/owner/repo/issues/7/comments": `[{"id": 1, "body": "@acme/core, could you look at ` + "`@acme/core`" + `?\n` + "```\\n@acme/core\\n```" + `", "user": {"login": "author"}}]`,
+		"/repos/owner/repo/pulls/7/reviews":   `[]`,
+		"/repos/owner/repo/pulls/7/comments":  `[]`,
+		"/repos/owner/repo/pulls/7/commits":   `[]`,
+		"/orgs/acme/teams/core/members":       `[{"login": "alice"}, {"login": "bob"}]`,
+		"/orgs/acme/teams/everyone/members":   `[{"login": "a"}, {"login": "b"}, {"login": "c"}, {"login": "d"}, {"login": "e"}, {"login": "f"}]`,
+	}}
+
+	pr, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt, ResolveTeams: true})
+	if err != nil {
+		t.Fatalf("LoadPR returned an error: %v", err)
+	}
+
+	if pr.Body != "cc @acme/core and @acme/everyone, but not @acme/secret." {
+		t.Errorf("Expected the body to be left as written, got %q", pr.Body)
+	}
+	expectedTeams := map[string][]string{"acme/core": {"alice", "bob"}, "acme/everyone": {"a", "b", "c", "d", "e", "f"}}
+	if !reflect.DeepEqual(pr.Teams, expectedTeams) {
+		t.Errorf("Expected teams %v, got %v", expectedTeams, pr.Teams)
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	output := buf.String()
+	if expected := "cc @acme/core (alice, bob) and @acme/everyone (6 members), but not @acme/secret."; !strings.Contains(output, expected) {
+		t.Errorf("Expected %q in the output, got:\n%s", expected, output)
+	}
+	if expected := "@acme/core (alice, bob), could you look at `@acme/core`?\n"; !strings.Contains(output, expected) {
+		t.Errorf("Expected mentions in inline code to be left alone, got:\n%s", output)
+	}
+	if !strings.Contains(output, "\n@acme/core\n```") {
+		t.Errorf("Expected mentions in code blocks to be left alone, got:\n%s", output)
+	}
+
+	buf.Reset()
+	if err := prview.Render(&buf, pr, prview.RenderOptions{Format: "json"}); err != nil {
+		t.Fatalf("Render returned an error: %v", err)
+	}
+	if strings.Contains(buf.String(), "alice") {
+		t.Errorf("Expected JSON output not to name team members, got:\n%s", buf.String())
+	}
+
+	fetches := 0
+	for _, req := range rt.requests {
+		if strings.HasSuffix(req.URL.Path, "/teams/core/members") {
+			fetches++
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Expected the core team to be fetched once, got %d", fetches)
+	}
+}
+
+func TestFetchTeamMembersPages(t *testing.T) {
+	logins := func(from, to int) string {
+		var users []string
+		for i := from; i < to; i++ {
+			users = append(users, fmt.Sprintf(`{"login": "user%d"}`, i))
+		}
+		return "[" + strings.Join(users, ",") + "]"
+	}
+	pages := map[string]string{"1": logins(0, 100), "2": logins(100, 130)}
+	client := newTestClient(t, roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(pages[req.URL.Query().Get("page")])),
+			Request:    req,
+		}, nil
+	}))
+
+	members, err := prview.FetchTeamMembers(context.Background(), client, "acme", "big")
+	if err != nil {
+		t.Fatalf("FetchTeamMembers returned an error: %v", err)
+	}
+	if len(members) != 130 || members[129].Login != "user129" {
+		t.Errorf("Expected all 130 members across both pages, got %d", len(members))
+	}
+}
+
+func TestLoadPRResolveTeamsError(t *testing.T) {
+	setTestAuth(t)
+	stub := &stubTransport{responses: map[string]string{
+		"/repos/owner/repo/pulls/7":           `{"number": 7, "title": "Teams", "body": "cc @acme/core", "user": {"login": "author"}}`,
+		"/repos/owner/repo/issues/7/comments": `[]`,
+		"/repos/owner/repo/pulls/7/reviews":   `[]`,
+		"/repos/owner/repo/pulls/7/comments":  `[]`,
+		"/repos/owner/repo/pulls/7/commits":   `[]`,
+	}}
+	rt := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasPrefix(req.URL.Path, "/orgs/") {
+			return &http.Response{
+				StatusCode: http.StatusBadGateway,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"message": "Server Error"}`)),
+				Request:    req,
+			}, nil
+		}
+		return stub.RoundTrip(req)
+	})
+
+	_, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{Repo: "owner/repo", Transport: rt, ResolveTeams: true})
+	if err == nil || !strings.Contains(err.Error(), "acme/core") {
+		t.Errorf("Expected the failure to fetch the team to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package prview
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateExt is the extension of the files in a template directory
+const templateExt = ".tmpl"
+
+// templateFuncs are the helpers available to named templates, matching
+// what the built-in text layout does:
+//
+//	indent n s        prefixes every line of s with n spaces
+//	wrap width s      wraps the lines of s to at most width columns
+//	formatTime l t    formats t with the time layout l, or "" for the layout
+//	                  text output uses, which follows --relative-times
+//	short sha         abbreviates a commit SHA to seven characters
+//
+// They are bound to the render options when a template is executed.
+func templateFuncs(opts RenderOptions) template.FuncMap {
+	return template.FuncMap{
+		"indent": func(n int, s string) string {
+			pad := strings.Repeat(" ", n)
+			return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+		},
+		"wrap": func(width int, s string) string { return fitLines(s, width, 0) },
+		"formatTime": func(layout string, t time.Time) string {
+			if layout == "" {
+				return opts.timestamp(t)
+			}
+			return t.Format(layout)
+		},
+		"short": shortSHA,
+	}
+}
+
+// LoadTemplate reads the template called name from dir, in the file
+// name.tmpl. The other .tmpl files in dir are parsed along with it, so the
+// templates they define can be shared between layouts.
+func LoadTemplate(dir, name string) (*template.Template, error) {
+	path := filepath.Join(dir, name+templateExt)
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no template named %q in %s", name, dir)
+	}
+	tmpl, err := template.New(name + templateExt).Funcs(templateFuncs(RenderOptions{})).ParseGlob(filepath.Join(dir, "*"+templateExt))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing templates: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderTemplate executes a template from LoadTemplate with the PR. Its
+// fields, such as .Comments and .Reviews, are available directly, along with
+// .Timeline, the items in the order text output shows them. The output is
+// sanitized for the terminal like text output, as most of it comes from
+// the PR's authors.
+func RenderTemplate(w io.Writer, pr PullRequest, tmpl *template.Template, opts RenderOptions) error {
+	tmpl, err := tmpl.Clone()
+	if err != nil {
+		return fmt.Errorf("error rendering template: %w", err)
+	}
+	data := struct {
+		PullRequest
+		Timeline []TimelineItem
+	}{PullRequest: pr, Timeline: Timeline(pr, opts)}
+
+	var buf strings.Builder
+	if err := tmpl.Funcs(templateFuncs(opts)).Execute(&buf, data); err != nil {
+		return fmt.Errorf("error rendering template %s: %w", strings.TrimSuffix(tmpl.Name(), templateExt), err)
+	}
+	_, err = io.WriteString(w, sanitizeForTerminal(buf.String()))
+	return err
+}
@@ -0,0 +1,103 @@
+package prview_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestRenderNamedTemplate(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"helpers.tmpl": `{{ define "comment" }}{{ .User.Login }} at {{ formatTime "" .CreatedAt }}:
+{{ indent 4 .Body }}
+{{ end }}`,
+		"summary.tmpl": `#{{ .Number }} {{ .Title }}
+{{ range .Comments }}{{ template "comment" . }}{{ end -}}
+{{ range .Reviews }}{{ .User.Login }}: {{ .State }}
+{{ end -}}`,
+		"other.tmpl": `not this one`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tmpl, err := prview.LoadTemplate(dir, "summary")
+	if err != nil {
+		t.Fatalf("LoadTemplate returned an error: %v", err)
+	}
+	pr := createMockPR()
+	pr.Comments[0].Body = "First line\nsecond line"
+
+	var buf bytes.Buffer
+	if err := prview.Render(&buf, pr, prview.RenderOptions{Template: tmpl}); err != nil {
+		t.Fatalf("Render returned an error: %v", err)
+	}
+
+	expected := "#123 Test PR\n" +
+		"commenter1 at " + pr.Comments[0].CreatedAt.Format("2006-01-02 15:04:05") + ":\n    First line\n    second line\n" +
+		"commenter2 at " + pr.Comments[1].CreatedAt.Format("2006-01-02 15:04:05") + ":\n    This is a later comment\n" +
+		"reviewer1: APPROVED\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestRenderTemplateSanitizes(t *testing.T) {
+	tmpl := template.Must(template.New("t").Parse("{{ range .Comments }}{{ .Body }}\n{{ end }}"))
+	pr := createMockPR()
+	pr.Comments[0].Body = "\x1b]0;pwned\x07retitled"
+
+	var buf bytes.Buffer
+	if err := prview.RenderTemplate(&buf, pr, tmpl, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderTemplate returned an error: %v", err)
+	}
+	if strings.ContainsAny(buf.String(), "\x1b\x07") {
+		t.Errorf("Expected control characters to be escaped, got %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "^[]0;pwned^Gretitled\n") {
+		t.Errorf("Expected the escape sequence to be shown in caret notation, got %q", buf.String())
+	}
+}
+
+func TestLoadTemplateMissing(t *testing.T) {
+	_, err := prview.LoadTemplate(t.TempDir(), "nope")
+	if err == nil || !strings.Contains(err.Error(), `no template named "nope"`) {
+		t.Errorf("Expected a missing template error, got %v", err)
+	}
+}
+
+func TestTemplateHelpers(t *testing.T) {
+	dir := t.TempDir()
+	content := `{{ range .Commits }}{{ short .SHA }} on {{ formatTime "Jan 2" $.CreatedAt }}
+{{ indent 2 (wrap 12 .Message) }}
+{{ end }}`
+	if err := os.WriteFile(filepath.Join(dir, "commits.tmpl"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := prview.LoadTemplate(dir, "commits")
+	if err != nil {
+		t.Fatalf("LoadTemplate returned an error: %v", err)
+	}
+	pr := prview.PullRequest{
+		CreatedAt: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Commits:   []prview.Commit{{SHA: "0123456789abcdef", Message: "Split the parser in two"}},
+	}
+
+	var buf bytes.Buffer
+	if err := prview.Render(&buf, pr, prview.RenderOptions{Template: tmpl}); err != nil {
+		t.Fatalf("Render returned an error: %v", err)
+	}
+	expected := "0123456 on Mar 1\n  Split the\n  parser in\n  two\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%q\ngot:\n%q", expected, buf.String())
+	}
+}
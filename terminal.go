@@ -0,0 +1,35 @@
+package prview
+
+import (
+	"io"
+	"os"
+
+	"github.com/cli/go-gh/v2/pkg/term"
+)
+
+// terminalWidth returns the width of w when it is a terminal. Only standard
+// output's width can be found; other terminals report a width of zero.
+var terminalWidth = func(w io.Writer) (width int, ok bool) {
+	f, isFile := w.(*os.File)
+	if !isFile || !term.IsTerminal(f) {
+		return 0, false
+	}
+	if f == os.Stdout {
+		width, _, _ = term.FromEnv().Size()
+	}
+	return width, true
+}
+
+// ResolveFormat settles the "auto" format for output to w: text fitted to
+// the window when w is a terminal, or plain, unwrapped text when it's piped
+// or a file. Options for other formats are returned unchanged.
+func ResolveFormat(w io.Writer, opts RenderOptions) RenderOptions {
+	if opts.Format != "auto" {
+		return opts
+	}
+	opts.Format = "text"
+	if width, ok := terminalWidth(w); ok && opts.MaxWidth == 0 && width > 0 {
+		opts.MaxWidth = width
+	}
+	return opts
+}
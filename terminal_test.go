@@ -0,0 +1,41 @@
+package prview_test
+
+import (
+	"bytes"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestRenderAutoPiped(t *testing.T) {
+	pr := createMockPR()
+	pr.Body = "A body long enough that it would be wrapped on any narrow terminal window, which piped output never is"
+
+	var auto, text bytes.Buffer
+	if err := prview.Render(&auto, pr, prview.RenderOptions{Format: "auto"}); err != nil {
+		t.Fatalf("Render returned an error: %v", err)
+	}
+	if err := prview.Render(&text, pr, prview.RenderOptions{Format: "text"}); err != nil {
+		t.Fatalf("Render returned an error: %v", err)
+	}
+	if auto.String() != text.String() {
+		t.Errorf("Expected auto to render plain text when piped, got:\n%s\nwant:\n%s", auto.String(), text.String())
+	}
+	if opts := prview.ResolveFormat(&auto, prview.RenderOptions{Format: "auto"}); opts.Format != "text" || opts.MaxWidth != 0 {
+		t.Errorf("Expected auto to resolve to unwrapped text, got format %q and width %d", opts.Format, opts.MaxWidth)
+	}
+}
+
+func TestResolveFormatTerminal(t *testing.T) {
+	prview.SetTerminalWidth(t, 100)
+
+	if opts := prview.ResolveFormat(&bytes.Buffer{}, prview.RenderOptions{Format: "auto"}); opts.Format != "text" || opts.MaxWidth != 100 {
+		t.Errorf("Expected text wrapped to the terminal's 100 columns, got format %q and width %d", opts.Format, opts.MaxWidth)
+	}
+	if opts := prview.ResolveFormat(&bytes.Buffer{}, prview.RenderOptions{Format: "auto", MaxWidth: 60}); opts.MaxWidth != 60 {
+		t.Errorf("Expected --max-width to win over the terminal's width, got %d", opts.MaxWidth)
+	}
+	if opts := prview.ResolveFormat(&bytes.Buffer{}, prview.RenderOptions{Format: "json"}); opts.Format != "json" || opts.MaxWidth != 0 {
+		t.Errorf("Expected other formats to be left alone, got %+v", opts)
+	}
+}
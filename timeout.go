@@ -0,0 +1,77 @@
+package prview
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// timeoutTransport limits how long each request through it may take,
+// including reading the response body, before passing it to base, or to
+// http.DefaultTransport when base is nil. Unlike http.Client.Timeout, the
+// limit is a context derived from the request's own, so a request that runs
+// out of its own time fails with ErrRequestTimeout, while one cut short by
+// the caller's deadline still fails with context.DeadlineExceeded.
+type timeoutTransport struct {
+	timeout time.Duration
+	base    http.RoundTripper
+}
+
+// withRequestTimeout wraps rt in a timeoutTransport when timeout is
+// positive, returning rt as it is otherwise
+func withRequestTimeout(rt http.RoundTripper, timeout time.Duration) http.RoundTripper {
+	if timeout <= 0 {
+		return rt
+	}
+	return &timeoutTransport{timeout: timeout, base: rt}
+}
+
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	parent := req.Context()
+	ctx, cancel := context.WithTimeout(parent, t.timeout)
+	resp, err := base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		err = t.tag(err, ctx, parent)
+		cancel()
+		return nil, err
+	}
+	resp.Body = &timeoutBody{ReadCloser: resp.Body, transport: t, ctx: ctx, parent: parent, cancel: cancel}
+	return resp, nil
+}
+
+// tag reports err as ErrRequestTimeout when it came from the request's own
+// deadline rather than its parent context ending
+func (t *timeoutTransport) tag(err error, ctx, parent context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && parent.Err() == nil {
+		return tagError(ErrRequestTimeout, "%v after %v", ErrRequestTimeout, t.timeout)
+	}
+	return err
+}
+
+// timeoutBody keeps a request's deadline running until its response body
+// is closed
+type timeoutBody struct {
+	io.ReadCloser
+	transport   *timeoutTransport
+	ctx, parent context.Context
+	cancel      context.CancelFunc
+}
+
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = b.transport.tag(err, b.ctx, b.parent)
+	}
+	return n, err
+}
+
+func (b *timeoutBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,50 @@
+package prview_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestLoadPRTimeoutKinds(t *testing.T) {
+	setTestAuth(t)
+	slow := func() *stubTransport {
+		return &stubTransport{
+			responses: map[string]string{"/repos/owner/repo/pulls/7": `{"number": 7}`},
+			delays:    map[string]time.Duration{"/repos/owner/repo/pulls/7": 5 * time.Second},
+		}
+	}
+
+	t.Run("request", func(t *testing.T) {
+		_, err := prview.LoadPR(context.Background(), 7, prview.LoadOptions{
+			Repo:           "owner/repo",
+			Transport:      slow(),
+			RequestTimeout: 50 * time.Millisecond,
+		})
+		if !errors.Is(err, prview.ErrRequestTimeout) {
+			t.Errorf("Expected the request timeout to fire, got %v", err)
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Didn't expect the request timeout to look like the overall one, got %v", err)
+		}
+	})
+
+	t.Run("overall", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := prview.LoadPR(ctx, 7, prview.LoadOptions{
+			Repo:           "owner/repo",
+			Transport:      slow(),
+			RequestTimeout: 5 * time.Second,
+		})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the overall timeout to fire, got %v", err)
+		}
+		if errors.Is(err, prview.ErrRequestTimeout) {
+			t.Errorf("Didn't expect the overall timeout to be blamed on the request, got %v", err)
+		}
+	})
+}
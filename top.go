@@ -0,0 +1,63 @@
+package prview
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// TopComments returns up to n of the PR's issue and review comments with the
+// most reactions, most reacted first. Comments nobody reacted to are left
+// out, and ties keep the order the comments were written in.
+func TopComments(pr PullRequest, n int) []Comment {
+	var reacted []Comment
+	add := func(comments []Comment) {
+		for _, c := range comments {
+			if c.Reactions.TotalCount > 0 {
+				reacted = append(reacted, c)
+			}
+		}
+	}
+	add(pr.Comments)
+	for _, review := range pr.Reviews {
+		for _, thread := range review.Threads {
+			add(thread.Comments)
+		}
+	}
+	sort.SliceStable(reacted, func(i, j int) bool {
+		if reacted[i].Reactions.TotalCount != reacted[j].Reactions.TotalCount {
+			return reacted[i].Reactions.TotalCount > reacted[j].Reactions.TotalCount
+		}
+		return reacted[i].CreatedAt.Before(reacted[j].CreatedAt)
+	})
+
+	if n >= 0 && len(reacted) > n {
+		reacted = reacted[:n]
+	}
+	return reacted
+}
+
+// RenderTopComments writes the n most reacted comments on the PR, review
+// comments along with the diff they refer to
+func RenderTopComments(w io.Writer, pr PullRequest, n int, opts RenderOptions) error {
+	return renderFitted(w, opts, func(w io.Writer) error {
+		renderTopComments(w, pr, n, opts)
+		return nil
+	})
+}
+
+func renderTopComments(w io.Writer, pr PullRequest, n int, opts RenderOptions) {
+	for _, c := range TopComments(pr, n) {
+		noun := "reactions"
+		if c.Reactions.TotalCount == 1 {
+			noun = "reaction"
+		}
+		fmt.Fprintf(w, "%d %s\n\n", c.Reactions.TotalCount, noun)
+		if c.Path != "" {
+			renderThread(w, CommentThread{Comments: []Comment{c}}, opts)
+		} else {
+			renderIssueComment(w, c, opts)
+		}
+		fmt.Fprintln(w, opts.rule("-"))
+	}
+}
@@ -0,0 +1,45 @@
+package prview_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func TestTopComments(t *testing.T) {
+	now := time.Now()
+	pr := prview.PullRequest{
+		Comments: []prview.Comment{
+			{ID: 1, Body: "Some", CreatedAt: now, User: prview.User{Login: "alice"}, Reactions: prview.Reactions{TotalCount: 3}},
+			{ID: 2, Body: "Few", CreatedAt: now.Add(time.Minute), User: prview.User{Login: "bob"}, Reactions: prview.Reactions{TotalCount: 1}},
+		},
+		Reviews: []prview.Review{
+			{ID: 10, Threads: []prview.CommentThread{{Comments: []prview.Comment{
+				{ID: 3, Body: "Most", Path: "main.go", DiffHunk: "@@ -1 +1 @@\n+package main", CreatedAt: now, User: prview.User{Login: "carol"}, Reactions: prview.Reactions{TotalCount: 7}},
+			}}}},
+		},
+	}
+
+	top := prview.TopComments(pr, 2)
+	if len(top) != 2 || top[0].ID != 3 || top[1].ID != 1 {
+		t.Fatalf("Expected comments 3 then 1, got %+v", top)
+	}
+
+	var buf bytes.Buffer
+	if err := prview.RenderTopComments(&buf, pr, 2, prview.RenderOptions{}); err != nil {
+		t.Fatalf("RenderTopComments returned an error: %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "7 reactions") || !strings.Contains(output, "main.go") || !strings.Contains(output, "+package main") {
+		t.Errorf("Expected the review comment with its diff context, got:\n%s", output)
+	}
+	if strings.Index(output, "Most") > strings.Index(output, "Some") {
+		t.Errorf("Expected the most reacted comment first, got:\n%s", output)
+	}
+	if strings.Contains(output, "Few") {
+		t.Errorf("Expected only the top two comments, got:\n%s", output)
+	}
+}
@@ -0,0 +1,47 @@
+package prview
+
+import "strings"
+
+// truncatedBodyHint ends a PR body cut short by truncateBody
+const truncatedBodyHint = "... (show full with --body-lines 0)"
+
+// truncateBody cuts body down to its first n lines, followed by a hint that
+// there is more, or returns it whole when n isn't positive or it already
+// fits. A fenced code block is never split: a cut inside one moves back to
+// before the block, or, when the block starts the body, forward past its end.
+func truncateBody(body string, n int) string {
+	lines := strings.Split(body, "\n")
+	if n <= 0 || len(lines) <= n {
+		return body
+	}
+
+	// fenceStart is the line the code block open at line n began on, or -1
+	fenceStart := -1
+	for i, line := range lines[:n] {
+		if isFence(line) {
+			if fenceStart < 0 {
+				fenceStart = i
+			} else {
+				fenceStart = -1
+			}
+		}
+	}
+
+	cut := n
+	if fenceStart > 0 {
+		cut = fenceStart
+	} else if fenceStart == 0 {
+		cut = len(lines)
+		for i := n; i < len(lines); i++ {
+			if isFence(lines[i]) {
+				cut = i + 1
+				break
+			}
+		}
+	}
+	if cut >= len(lines) {
+		return body
+	}
+
+	return strings.Join(lines[:cut], "\n") + "\n" + truncatedBodyHint
+}
@@ -0,0 +1,56 @@
+package prview_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+func renderBody(t *testing.T, body string, n int) string {
+	t.Helper()
+	pr := createMockPR()
+	pr.Body = body
+	pr.Comments, pr.Reviews = nil, nil
+
+	var buf bytes.Buffer
+	if err := prview.RenderPR(&buf, pr, prview.RenderOptions{BodyLines: n}); err != nil {
+		t.Fatalf("RenderPR returned an error: %v", err)
+	}
+	_, after, _ := strings.Cut(buf.String(), "\n\n")
+	return after
+}
+
+func TestRenderBodyLines(t *testing.T) {
+	output := renderBody(t, "one\ntwo\nthree\nfour\nfive", 3)
+	if !strings.HasPrefix(output, "one\ntwo\nthree\n... (show full with --body-lines 0)\n") {
+		t.Errorf("Expected the body cut to 3 lines and a hint, got:\n%s", output)
+	}
+	if strings.Contains(output, "four") {
+		t.Errorf("Expected the rest of the body to be dropped, got:\n%s", output)
+	}
+}
+
+func TestRenderBodyLinesFits(t *testing.T) {
+	for _, n := range []int{0, 5} {
+		output := renderBody(t, "one\ntwo\nthree\nfour\nfive", n)
+		if !strings.HasPrefix(output, "one\ntwo\nthree\nfour\nfive\n") || strings.Contains(output, "--body-lines") {
+			t.Errorf("Expected the whole body with --body-lines %d, got:\n%s", n, output)
+		}
+	}
+}
+
+func TestRenderBodyLinesCodeBlock(t *testing.T) {
+	body := "Intro\n```go\nfunc a() {}\nfunc b() {}\n```\nOutro\nMore"
+	output := renderBody(t, body, 3)
+	if !strings.HasPrefix(output, "Intro\n... (show full") {
+		t.Errorf("Expected the cut to move before the code block, got:\n%s", output)
+	}
+
+	body = "```go\nfunc a() {}\nfunc b() {}\n```\nOutro\nMore"
+	output = renderBody(t, body, 2)
+	if !strings.HasPrefix(output, "```go\nfunc a() {}\nfunc b() {}\n```\n... (show full") {
+		t.Errorf("Expected a leading code block to be kept whole, got:\n%s", output)
+	}
+}
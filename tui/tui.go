@@ -0,0 +1,222 @@
+// Package tui is an interactive browser for a pull request's timeline: a
+// list of the timeline items on the left and the selected item in full,
+// diffs included, on the right.
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+
+	prview "github.com/bmon/gh-prview"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// defaultWidth and defaultHeight size the view until the terminal
+	// reports its size
+	defaultWidth  = 80
+	defaultHeight = 24
+	// maxListWidth caps the width of the timeline list
+	maxListWidth = 40
+	// separator divides the list from the detail of the selected item
+	separator = " │ "
+	help      = "j/k: next/prev  pgdn/pgup: scroll  g/G: first/last  q: quit"
+)
+
+// Model is the bubbletea model of the browser. Its zero value isn't usable;
+// create one with New.
+type Model struct {
+	items   []prview.TimelineItem
+	details [][]string
+
+	selected int
+	// offset is the first item shown in the list and scroll the first line
+	// of the selected item's detail shown
+	offset int
+	scroll int
+
+	width  int
+	height int
+}
+
+// New returns a model browsing the PR's timeline, with each item rendered
+// as Render would with opts
+func New(pr prview.PullRequest, opts prview.RenderOptions) Model {
+	m := Model{
+		items:  prview.Timeline(pr, opts),
+		width:  defaultWidth,
+		height: defaultHeight,
+	}
+	for _, item := range m.items {
+		var buf bytes.Buffer
+		prview.RenderTimelineItem(&buf, item, opts)
+		m.details = append(m.details, strings.Split(strings.TrimRight(buf.String(), "\n"), "\n"))
+	}
+	return m
+}
+
+// Run browses the PR's timeline on the terminal until the user quits
+func Run(pr prview.PullRequest, opts prview.RenderOptions, options ...tea.ProgramOption) error {
+	options = append([]tea.ProgramOption{tea.WithAltScreen()}, options...)
+	_, err := tea.NewProgram(New(pr, opts), options...).Run()
+	return err
+}
+
+// Selected returns the index of the selected timeline item
+func (m Model) Selected() int {
+	return m.selected
+}
+
+// Scroll returns how many lines of the selected item's detail are scrolled
+// out of view
+func (m Model) Scroll() int {
+	return m.scroll
+}
+
+// Init implements tea.Model
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model, moving the selection and scrolling the detail
+// in response to keys
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.keepSelectedVisible()
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "j", "down":
+			m.selectItem(m.selected + 1)
+		case "k", "up":
+			m.selectItem(m.selected - 1)
+		case "g", "home":
+			m.selectItem(0)
+		case "G", "end":
+			m.selectItem(len(m.items) - 1)
+		case "pgdown", " ", "ctrl+d":
+			m.scrollDetail(m.rows())
+		case "pgup", "b", "ctrl+u":
+			m.scrollDetail(-m.rows())
+		}
+	}
+	return m, nil
+}
+
+// selectItem selects item i, clamped to the timeline, and scrolls its
+// detail back to the top
+func (m *Model) selectItem(i int) {
+	i = max(0, min(i, len(m.items)-1))
+	if i == m.selected {
+		return
+	}
+	m.selected = i
+	m.scroll = 0
+	m.keepSelectedVisible()
+}
+
+// scrollDetail scrolls the selected item's detail by n lines, stopping with
+// its last line at the bottom of the view
+func (m *Model) scrollDetail(n int) {
+	if len(m.details) == 0 {
+		return
+	}
+	last := max(0, len(m.details[m.selected])-m.rows())
+	m.scroll = max(0, min(m.scroll+n, last))
+}
+
+// keepSelectedVisible moves the list so the selected item is in view
+func (m *Model) keepSelectedVisible() {
+	rows := m.rows()
+	if m.selected < m.offset {
+		m.offset = m.selected
+	} else if m.selected >= m.offset+rows {
+		m.offset = m.selected - rows + 1
+	}
+}
+
+// rows is the number of lines available to the list and detail, leaving a
+// line for the key help
+func (m Model) rows() int {
+	return max(1, m.height-1)
+}
+
+// View implements tea.Model
+func (m Model) View() string {
+	if len(m.items) == 0 {
+		return "No comments, reviews or commits\n\nq: quit\n"
+	}
+
+	listWidth := min(maxListWidth, m.width/3)
+	detailWidth := max(0, m.width-listWidth-len([]rune(separator)))
+	detail := m.details[m.selected]
+
+	var b strings.Builder
+	for row := 0; row < m.rows(); row++ {
+		entry := ""
+		if i := m.offset + row; i < len(m.items) {
+			marker := "  "
+			if i == m.selected {
+				marker = "> "
+			}
+			entry = marker + summary(m.items[i])
+		}
+		line := ""
+		if i := m.scroll + row; i < len(detail) {
+			line = detail[i]
+		}
+		b.WriteString(fit(entry, listWidth))
+		b.WriteString(separator)
+		b.WriteString(strings.TrimRight(fit(line, detailWidth), " "))
+		b.WriteString("\n")
+	}
+	b.WriteString(fit(help, m.width))
+	return b.String()
+}
+
+// summary describes a timeline item in a line of the list
+func summary(item prview.TimelineItem) string {
+	when := item.CreatedAt.Format("01-02 15:04")
+	switch item.Type {
+	case "comment":
+		return fmt.Sprintf("%s %s commented", when, item.Comment.User.Login)
+	case "review_comment":
+		return fmt.Sprintf("%s %s on %s", when, item.Comment.User.Login, item.Comment.Path)
+	case "review":
+		return fmt.Sprintf("%s %s %s", when, item.Review.User.Login, strings.ToLower(strings.ReplaceAll(item.Review.State, "_", " ")))
+	case "commit":
+		sha := item.Commit.SHA
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		return fmt.Sprintf("%s %s %s", when, sha, item.Commit.Message)
+	case "reference":
+		return fmt.Sprintf("%s referenced by #%d in %s", when, item.Reference.Number, item.Reference.Repo)
+	}
+	return when
+}
+
+// fit pads or truncates s to exactly width characters. Control characters
+// are dropped, as logins and commit messages in the list aren't otherwise
+// sanitized.
+func fit(s string, width int) string {
+	var runes []rune
+	for _, r := range strings.ReplaceAll(s, "\t", "    ") {
+		if !unicode.IsControl(r) {
+			runes = append(runes, r)
+		}
+	}
+	s = string(runes)
+	if len(runes) > width {
+		if width <= 1 {
+			return string(runes[:width])
+		}
+		return string(runes[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
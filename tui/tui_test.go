@@ -0,0 +1,134 @@
+package tui_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+	"github.com/bmon/gh-prview/tui"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func mockPR() prview.PullRequest {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	var body []string
+	for i := 0; i < 30; i++ {
+		body = append(body, "line")
+	}
+	return prview.PullRequest{
+		Number: 1,
+		Comments: []prview.Comment{
+			{ID: 1, Body: "First", CreatedAt: start, User: prview.User{Login: "alice"}},
+			{ID: 2, Body: strings.Join(body, "\n"), CreatedAt: start.Add(2 * time.Hour), User: prview.User{Login: "carol"}},
+		},
+		Reviews: []prview.Review{
+			{ID: 10, Body: "Looks good", State: "APPROVED", SubmittedAt: start.Add(time.Hour), User: prview.User{Login: "bob"}},
+		},
+	}
+}
+
+func keys(m tea.Model, names ...string) tea.Model {
+	for _, name := range names {
+		var msg tea.KeyMsg
+		switch name {
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "up":
+			msg = tea.KeyMsg{Type: tea.KeyUp}
+		case "pgdown":
+			msg = tea.KeyMsg{Type: tea.KeyPgDown}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)}
+		}
+		m, _ = m.Update(msg)
+	}
+	return m
+}
+
+func TestModelSelection(t *testing.T) {
+	var m tea.Model = tui.New(mockPR(), prview.RenderOptions{})
+
+	tests := []struct {
+		keys []string
+		want int
+	}{
+		{nil, 0},
+		{[]string{"j"}, 1},
+		{[]string{"down"}, 2},
+		{[]string{"j"}, 2},
+		{[]string{"k", "up", "up"}, 0},
+		{[]string{"G"}, 2},
+		{[]string{"g"}, 0},
+	}
+	for _, tt := range tests {
+		m = keys(m, tt.keys...)
+		if got := m.(tui.Model).Selected(); got != tt.want {
+			t.Errorf("After %v expected item %d to be selected, got %d", tt.keys, tt.want, got)
+		}
+	}
+}
+
+func TestModelDetailFollowsSelection(t *testing.T) {
+	var m tea.Model = tui.New(mockPR(), prview.RenderOptions{})
+	m = keys(m, "j")
+
+	view := m.View()
+	if !strings.Contains(view, "> 01-01 11:00 bob approved") || !strings.Contains(view, "Looks good") {
+		t.Errorf("Expected the review selected and shown in detail, got:\n%s", view)
+	}
+	if strings.Contains(view, "First") {
+		t.Errorf("Expected only the selected item's detail, got:\n%s", view)
+	}
+}
+
+func TestModelScroll(t *testing.T) {
+	var m tea.Model = tui.New(mockPR(), prview.RenderOptions{})
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 11})
+
+	m = keys(m, "pgdown")
+	if got := m.(tui.Model).Scroll(); got != 0 {
+		t.Errorf("Expected a short item not to scroll, got %d", got)
+	}
+
+	m = keys(m, "G", "pgdown")
+	if got := m.(tui.Model).Scroll(); got != 10 {
+		t.Errorf("Expected a page of scrolling, got %d", got)
+	}
+	m = keys(m, "pgdown", "pgdown", "pgdown")
+	if got := m.(tui.Model).Scroll(); got != 22 {
+		t.Errorf("Expected scrolling to stop at the last line, got %d", got)
+	}
+
+	m = keys(m, "k")
+	if got := m.(tui.Model).Scroll(); got != 0 {
+		t.Errorf("Expected a new selection to start at the top, got %d", got)
+	}
+}
+
+func TestModelQuit(t *testing.T) {
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("q")},
+		{Type: tea.KeyCtrlC},
+		{Type: tea.KeyEsc},
+	} {
+		_, cmd := tui.New(mockPR(), prview.RenderOptions{}).Update(key)
+		if cmd == nil {
+			t.Fatalf("Expected %q to quit", key)
+		}
+		if _, ok := cmd().(tea.QuitMsg); !ok {
+			t.Errorf("Expected %q to quit, got %T", key, cmd())
+		}
+	}
+}
+
+func TestModelEmptyTimeline(t *testing.T) {
+	var m tea.Model = tui.New(prview.PullRequest{Number: 1}, prview.RenderOptions{})
+	m = keys(m, "j", "G", "pgdown")
+	if got := m.(tui.Model).Selected(); got != 0 {
+		t.Errorf("Expected nothing to select, got %d", got)
+	}
+	if !strings.Contains(m.View(), "No comments") {
+		t.Errorf("Expected an empty timeline message, got:\n%s", m.View())
+	}
+}
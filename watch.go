@@ -0,0 +1,120 @@
+package prview
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Clock abstracts waiting so the watch loop can be driven by tests
+type Clock interface {
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// RealClock is the Clock backed by the system time
+var RealClock Clock = realClock{}
+
+// Watch calls refresh immediately and then once every interval until ctx is
+// done. Cancelling ctx, e.g. on Ctrl-C, is a clean stop and returns nil; an
+// error returned by refresh stops the loop and is returned as is.
+func Watch(ctx context.Context, interval time.Duration, clock Clock, refresh func(context.Context) error) error {
+	for {
+		if err := refresh(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-clock.After(interval):
+		}
+	}
+}
+
+// conditionalTransport remembers the ETag of every successful GET and sends
+// it back as If-None-Match, replaying the stored body when GitHub answers
+// 304 Not Modified. Those responses don't count against the rate limit,
+// which keeps repeated loads in watch mode cheap.
+type conditionalTransport struct {
+	base http.RoundTripper
+
+	mu    sync.Mutex
+	cache map[string]cachedResponse
+}
+
+type cachedResponse struct {
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+// NewConditionalTransport wraps base, or http.DefaultTransport when base is
+// nil, with a transport that revalidates repeated GET requests using ETags
+// instead of downloading them again
+func NewConditionalTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &conditionalTransport{base: base, cache: make(map[string]cachedResponse)}
+}
+
+func (t *conditionalTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.base.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	t.mu.Lock()
+	cached, ok := t.cache[key]
+	t.mu.Unlock()
+	if ok {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		resp.StatusCode = http.StatusOK
+		resp.Status = "200 OK"
+		resp.Header = cached.header.Clone()
+		resp.Body = io.NopCloser(bytes.NewReader(cached.body))
+		resp.ContentLength = int64(len(cached.body))
+		return resp, nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	t.cache[key] = cachedResponse{etag: etag, header: resp.Header.Clone(), body: body}
+	t.mu.Unlock()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
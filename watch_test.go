@@ -0,0 +1,102 @@
+package prview_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	prview "github.com/bmon/gh-prview"
+)
+
+// fakeClock hands out a channel the test fires by hand and records the
+// durations it was asked to wait
+type fakeClock struct {
+	tick  chan time.Time
+	waits []time.Duration
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	return c.tick
+}
+
+func TestWatch(t *testing.T) {
+	clock := &fakeClock{tick: make(chan time.Time, 1)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var refreshes int
+	refresh := func(ctx context.Context) error {
+		refreshes++
+		if refreshes == 1 {
+			// Let the loop complete a single wait before refreshing again
+			clock.tick <- time.Now()
+		} else {
+			cancel()
+		}
+		return nil
+	}
+
+	if err := prview.Watch(ctx, 30*time.Second, clock, refresh); err != nil {
+		t.Fatalf("Expected cancelling to stop the watch cleanly, got %v", err)
+	}
+	if refreshes != 2 {
+		t.Errorf("Expected an initial refresh and one after the tick, got %d", refreshes)
+	}
+	if len(clock.waits) != 1 || clock.waits[0] != 30*time.Second {
+		t.Errorf("Expected a single wait of the interval, got %v", clock.waits)
+	}
+}
+
+func TestWatchRefreshError(t *testing.T) {
+	clock := &fakeClock{tick: make(chan time.Time)}
+	want := errors.New("boom")
+
+	err := prview.Watch(context.Background(), time.Second, clock, func(context.Context) error { return want })
+	if !errors.Is(err, want) {
+		t.Errorf("Expected the refresh error to be returned, got %v", err)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestConditionalTransport(t *testing.T) {
+	var conditional []string
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		conditional = append(conditional, req.Header.Get("If-None-Match"))
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			return &http.Response{StatusCode: http.StatusNotModified, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Etag": []string{`"v1"`}},
+			Body:       io.NopCloser(strings.NewReader(`{"number": 7}`)),
+			Request:    req,
+		}, nil
+	})
+	rt := prview.NewConditionalTransport(base)
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/repos/owner/repo/pulls/7", nil)
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("RoundTrip returned an error: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK || string(body) != `{"number": 7}` {
+			t.Errorf("Request %d: expected the cached body with 200, got %d %q", i, resp.StatusCode, body)
+		}
+	}
+
+	if len(conditional) != 2 || conditional[0] != "" || conditional[1] != `"v1"` {
+		t.Errorf("Expected the second request to revalidate with the ETag, got %q", conditional)
+	}
+}